@@ -0,0 +1,176 @@
+package temingo
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"path"
+	"time"
+)
+
+const activityPubConfigFileName = "activitypub.yaml"
+
+func init() {
+	configFileNames = append(configFileNames, activityPubConfigFileName)
+}
+
+// activityPubConfig is the content of an 'activitypub.yaml' file, which publishes the items of its folder as read-only fediverse actor.
+type activityPubConfig struct {
+	Username  string `yaml:"username"`
+	Name      string `yaml:"name"`
+	Summary   string `yaml:"summary"`
+	Icon      string `yaml:"icon"`
+	Limit     int    `yaml:"limit"`     // maximum number of items in the outbox
+	Webfinger *bool  `yaml:"webfinger"` // whether this actor is announced in '.well-known/webfinger', defaults to true
+}
+
+// exportActivityPub writes the static documents of a read-only ActivityPub actor for each folder containing an 'activitypub.yaml'.
+// Those are the actor itself, its outbox with the most recent items, empty inbox and followers collections and the webfinger document.
+func (engine *Engine) exportActivityPub() error {
+	configPaths, err := engine.getConfigFiles(activityPubConfigFileName)
+	if err != nil {
+		return err
+	}
+	if len(configPaths) == 0 {
+		return nil
+	}
+	if engine.siteBaseURL == "" {
+		return errors.New("The ActivityPub export requires the '--baseURL' flag or the 'baseURL' value to be set.")
+	}
+	site, err := url.Parse(engine.siteBaseURL)
+	if err != nil {
+		return err
+	}
+
+	webfingerSource := ""
+	for _, configPath := range configPaths {
+		sectionPath := path.Dir(configPath)
+
+		config := activityPubConfig{}
+		if err := loadConfigFile(configPath, &config); err != nil {
+			return err
+		}
+		if config.Username == "" {
+			config.Username = path.Base(sectionPath)
+			if sectionPath == "." {
+				config.Username = site.Hostname()
+			}
+		}
+		if config.Name == "" {
+			config.Name = config.Username
+		}
+		if config.Limit == 0 {
+			config.Limit = 20
+		}
+		announced := config.Webfinger == nil || *config.Webfinger
+		if announced {
+			if webfingerSource != "" { // a static webfinger document can only describe one actor
+				return errors.New("Both '" + webfingerSource + "' and '" + configPath + "' want to be announced via webfinger, set 'webfinger: false' in one of them.")
+			}
+			webfingerSource = configPath
+		}
+		if !engine.isChangedCollection(sectionPath) { // checked after the webfinger, as the one of the unchanged actors still counts
+			continue
+		}
+
+		engine.logDebug("*** Exporting '" + sectionPath + "' as ActivityPub actor '" + config.Username + "' ... ***")
+
+		sectionURL := engine.absoluteURL(path.Join("/", sectionPath))
+		actorID := engine.absoluteURL(path.Join("/", sectionPath, "actor.json"))
+		actor := map[string]interface{}{
+			"@context":          []string{"https://www.w3.org/ns/activitystreams"},
+			"id":                actorID,
+			"type":              "Service",
+			"preferredUsername": config.Username,
+			"name":              config.Name,
+			"summary":           config.Summary,
+			"url":               sectionURL,
+			"inbox":             engine.absoluteURL(path.Join("/", sectionPath, "inbox.json")),
+			"outbox":            engine.absoluteURL(path.Join("/", sectionPath, "outbox.json")),
+			"followers":         engine.absoluteURL(path.Join("/", sectionPath, "followers.json")),
+		}
+		if config.Icon != "" {
+			actor["icon"] = map[string]interface{}{"type": "Image", "url": engine.absoluteURL(config.Icon)}
+		}
+		if err := engine.writeJsonFile(path.Join(engine.OutputDir, sectionPath, "actor.json"), actor); err != nil {
+			return err
+		}
+
+		items, err := engine.getSortedListObjects(sectionPath, "date", true)
+		if err != nil {
+			return err
+		}
+		activities := []interface{}{}
+		for _, item := range items {
+			if len(activities) == config.Limit {
+				break
+			}
+			itemURL := engine.absoluteURL(toString(item["Path"]))
+			article := map[string]interface{}{
+				"id":           itemURL,
+				"type":         "Article",
+				"name":         toString(item["title"]),
+				"summary":      toString(item["description"]),
+				"url":          itemURL,
+				"attributedTo": actorID,
+				"to":           []string{"https://www.w3.org/ns/activitystreams#Public"},
+			}
+			activity := map[string]interface{}{
+				"id":     itemURL + "#create",
+				"type":   "Create",
+				"actor":  actorID,
+				"object": article,
+				"to":     []string{"https://www.w3.org/ns/activitystreams#Public"},
+			}
+			if published, ok := toTime(item["date"]); ok {
+				article["published"] = published.UTC().Format(time.RFC3339)
+				activity["published"] = published.UTC().Format(time.RFC3339)
+			}
+			activities = append(activities, activity)
+		}
+		if err := engine.writeJsonFile(path.Join(engine.OutputDir, sectionPath, "outbox.json"), createOrderedCollection(engine.absoluteURL(path.Join("/", sectionPath, "outbox.json")), activities)); err != nil {
+			return err
+		}
+		if err := engine.writeJsonFile(path.Join(engine.OutputDir, sectionPath, "inbox.json"), createOrderedCollection(engine.absoluteURL(path.Join("/", sectionPath, "inbox.json")), []interface{}{})); err != nil {
+			return err
+		}
+		if err := engine.writeJsonFile(path.Join(engine.OutputDir, sectionPath, "followers.json"), createOrderedCollection(engine.absoluteURL(path.Join("/", sectionPath, "followers.json")), []interface{}{})); err != nil {
+			return err
+		}
+
+		if announced {
+			webfinger := map[string]interface{}{
+				"subject": "acct:" + config.Username + "@" + site.Hostname(),
+				"aliases": []string{sectionURL, actorID},
+				"links": []interface{}{
+					map[string]interface{}{"rel": "self", "type": "application/activity+json", "href": actorID},
+					map[string]interface{}{"rel": "http://webfinger.net/rel/profile-page", "type": "text/html", "href": sectionURL},
+				},
+			}
+			if err := engine.writeJsonFile(path.Join(engine.OutputDir, ".well-known", "webfinger"), webfinger); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func createOrderedCollection(id string, items []interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"@context":     "https://www.w3.org/ns/activitystreams",
+		"id":           id,
+		"type":         "OrderedCollection",
+		"totalItems":   len(items),
+		"orderedItems": items,
+	}
+}
+
+func (engine *Engine) writeJsonFile(filePath string, content interface{}) error {
+	engine.logDebug("Writing '" + filePath + "' ...")
+	marshalled, err := json.MarshalIndent(content, "", "  ")
+	if err != nil {
+		return err
+	}
+	return engine.writeTemplateToFile(filePath, append(marshalled, '\n'))
+}
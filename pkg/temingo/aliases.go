@@ -0,0 +1,103 @@
+package temingo
+
+import (
+	"encoding/json"
+	"errors"
+	"html"
+	"path"
+	"strings"
+)
+
+// aliasRedirect is an old path of a page, declared in 'aliases' of its front matter or values, which redirects to the page.
+type aliasRedirect struct {
+	From string // the site-relative alias, f.e. '/old/'
+	To   string // the site-relative path of the page
+}
+
+// collectAliases collects the aliases of all pages of the current language.
+// With the aliasRedirects option 'server', they are added to the redirects of the server configuration as 301, so the templates of server configuration files render them.
+func (engine *Engine) collectAliases() error {
+	engine.aliases = []aliasRedirect{}
+	for _, page := range engine.sitePages {
+		pageValues := page.(map[string]interface{})
+		value, ok := pageValues["aliases"]
+		if !ok {
+			continue
+		}
+		target := engine.getLanguagePath(toString(pageValues["Path"]))
+		aliases, ok := value.([]interface{})
+		if !ok {
+			return errors.New("The 'aliases' of the page '" + target + "' must be a list of paths.")
+		}
+		for _, alias := range aliases {
+			from, ok := alias.(string)
+			if !ok || strings.Trim(from, "/") == "" || strings.Contains(from, "://") {
+				return errors.New("The 'aliases' of the page '" + target + "' must be a list of site-relative paths.")
+			}
+			engine.aliases = append(engine.aliases, aliasRedirect{From: engine.getLanguagePath(from), To: target})
+		}
+	}
+	if engine.AliasRedirects == "server" {
+		for _, alias := range engine.aliases {
+			engine.server.Redirects = append(engine.server.Redirects, serverRedirect{From: alias.From, To: alias.To, Status: 301})
+		}
+	}
+	return nil
+}
+
+// writeAliases writes a file to the path of each alias, after the pages are rendered.
+// With the aliasRedirects option 'page', it's a page redirecting to the target, with a canonical link and structured data pointing at it, so search engines index the target instead.
+// With 'server', it's an empty placeholder, as the server configuration redirects it.
+func (engine *Engine) writeAliases() error {
+	errs := BuildErrors{}
+	for _, alias := range engine.aliases {
+		aliasPath := strings.TrimPrefix(alias.From, "/")
+		if strings.HasSuffix(alias.From, "/") || path.Ext(alias.From) == "" {
+			aliasPath = path.Join(aliasPath, "index.html")
+		}
+		outputFilePath := path.Join(engine.OutputDir, aliasPath)
+		engine.lock.Lock()
+		source, ok := engine.outputSources[outputFilePath]
+		if !ok {
+			engine.outputSources[outputFilePath] = "the alias of '" + alias.To + "'"
+		}
+		engine.lock.Unlock()
+		if ok {
+			errs.add(errors.New("The alias '" + alias.From + "' of '" + alias.To + "' is rendered to '" + outputFilePath + "', which is already rendered from '" + source + "'."))
+			continue
+		}
+		content := []byte{}
+		if engine.AliasRedirects == "page" {
+			content = []byte(engine.getAliasPage(alias.To))
+		}
+		engine.logDebug("Writing the alias '" + alias.From + "' of '" + alias.To + "' to '" + outputFilePath + "' ...")
+		errs.add(engine.writeTemplateToFile(outputFilePath, content))
+	}
+	return errs.err()
+}
+
+// getAliasPage returns the html page redirecting to target.
+func (engine *Engine) getAliasPage(target string) string {
+	targetURL := html.EscapeString(engine.absoluteURL(target))
+	structuredData, _ := json.Marshal(map[string]string{ // can't fail for strings, and escapes '<', so it can't end the script
+		"@context": "https://schema.org",
+		"@type":    "WebPage",
+		"@id":      engine.absoluteURL(target),
+		"url":      engine.absoluteURL(target),
+	})
+	return `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Redirecting to ` + targetURL + `</title>
+<link rel="canonical" href="` + targetURL + `">
+<meta name="robots" content="noindex">
+<meta http-equiv="refresh" content="0; url=` + targetURL + `">
+<script type="application/ld+json">` + string(structuredData) + `</script>
+</head>
+<body>
+<p>This page has moved to <a href="` + targetURL + `">` + targetURL + `</a>.</p>
+</body>
+</html>
+`
+}
@@ -0,0 +1,52 @@
+package temingo
+
+import (
+	"errors"
+	"fmt"
+)
+
+// assertRequired returns value, or an error with message if value is missing or an empty string.
+// Returning an error aborts the template execution, so the message is printed together with the position in the template.
+func assertRequired(message string, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, errors.New(message)
+	}
+	if s, ok := value.(string); ok && s == "" {
+		return nil, errors.New(message)
+	}
+	return value, nil
+}
+
+// assertWarnf returns a function that logs a formatted warning for the template templateName without aborting its execution.
+func (engine *Engine) assertWarnf(templateName string) func(string, ...interface{}) string {
+	return func(format string, args ...interface{}) string {
+		engine.logWarn("In '" + templateName + "': " + fmt.Sprintf(format, args...))
+		return ""
+	}
+}
+
+// getValue returns a function that returns the value at a dotted key path of values, f.e. 'getValue "theme.colors.primary" "black"'.
+// If the value is missing or null, the default is returned instead. Without default, a missing value aborts the template execution, like with 'required'.
+func getValue(values map[string]interface{}) func(string, ...interface{}) (interface{}, error) {
+	return func(keyPath string, defaultValue ...interface{}) (interface{}, error) {
+		if len(defaultValue) > 1 {
+			return nil, errors.New("getValue: expects a key path and at most one default, but got " + fmt.Sprint(len(defaultValue)) + " defaults")
+		}
+		value, ok := lookupValue(values, keyPath)
+		if ok && value != nil {
+			return value, nil
+		}
+		if len(defaultValue) == 1 {
+			return defaultValue[0], nil
+		}
+		return nil, errors.New("getValue: there is no value at '" + keyPath + "' and no default was given")
+	}
+}
+
+// hasValue returns a function that returns whether values contain a non-null value at a dotted key path.
+func hasValue(values map[string]interface{}) func(string) bool {
+	return func(keyPath string) bool {
+		value, ok := lookupValue(values, keyPath)
+		return ok && value != nil
+	}
+}
@@ -0,0 +1,196 @@
+package temingo
+
+import (
+	"errors"
+	"html/template"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// AssetBundle is a css or js file concatenated from static files, see the assetBundles option.
+type AssetBundle struct {
+	Files       []string `yaml:"files"`       // paths or glob patterns of the static files in the order they are concatenated, relative to the staticDir, compiled Sass stylesheets by their css path
+	Minify      bool     `yaml:"minify"`      // whether the bundle is minified
+	Fingerprint bool     `yaml:"fingerprint"` // whether the bundle is additionally written with a hash of its content in the file name
+}
+
+// assetBundleSeparators are written between the concatenated files, so a missing trailing newline or semicolon doesn't merge the last statement of a file with the first one of the next.
+var assetBundleSeparators = map[string]string{
+	".css": "\n",
+	".js":  "\n;\n",
+}
+
+// validateAssetBundles checks the paths and files of the assetBundles option.
+func (engine *Engine) validateAssetBundles() error {
+	bundles := make(map[string]AssetBundle)
+	for bundlePath, bundle := range engine.AssetBundles {
+		cleanPath := path.Clean(strings.TrimPrefix(bundlePath, "/"))
+		if _, ok := assetBundleSeparators[path.Ext(cleanPath)]; !ok {
+			return errors.New("The asset bundle '" + bundlePath + "' must be a css or js file")
+		}
+		if cleanPath == ".." || strings.HasPrefix(cleanPath, "../") {
+			return errors.New("The asset bundle '" + bundlePath + "' must not point outside of the output-directory")
+		}
+		if len(bundle.Files) == 0 {
+			return errors.New("The asset bundle '" + bundlePath + "' must contain at least one file")
+		}
+		for _, file := range bundle.Files {
+			if _, err := filepath.Match(file, ""); err != nil {
+				return errors.New("The file pattern '" + file + "' of the asset bundle '" + bundlePath + "' is invalid: " + err.Error())
+			}
+		}
+		bundles[cleanPath] = bundle
+	}
+	if engine.AssetBundles != nil {
+		engine.AssetBundles = bundles
+	}
+	return nil
+}
+
+// buildAssetBundles concatenates the static files of each asset bundle in the outputDir, where they were copied to and maybe compiled and minified before, and writes the bundle there.
+// Bundles with 'minify' are minified, bundles with 'fingerprint' are additionally written with a hash of their content in the file name and available via the 'asset' template function.
+func (engine *Engine) buildAssetBundles() error {
+	engine.bundledFiles = make(map[string]bool)
+	if len(engine.AssetBundles) == 0 { // if no bundles are configured
+		return nil
+	}
+
+	engine.logDebug("*** Building asset bundles ... ***")
+
+	bundlePaths := []string{}
+	for bundlePath := range engine.AssetBundles {
+		bundlePaths = append(bundlePaths, bundlePath)
+	}
+	sort.Strings(bundlePaths)
+
+	fingerprinted := false
+	for _, bundlePath := range bundlePaths {
+		bundle := engine.AssetBundles[bundlePath]
+		files, err := engine.getAssetBundleFiles(bundlePath, bundle)
+		if err != nil {
+			return err
+		}
+
+		contents := []string{}
+		for _, file := range files {
+			content, err := ioutil.ReadFile(path.Join(engine.OutputDir, file))
+			if err != nil {
+				return err
+			}
+			contents = append(contents, strings.TrimRight(string(content), "\n"))
+			if bundle.Fingerprint {
+				engine.bundledFiles[file] = true
+			}
+		}
+		content := []byte(strings.Join(contents, assetBundleSeparators[path.Ext(bundlePath)]) + "\n")
+
+		outputFilePath, err := engine.getOutputFilePath(bundlePath)
+		if err != nil {
+			return err
+		}
+		if bundle.Minify {
+			content, err = engine.minifyOutput(outputFilePath, content)
+			if err != nil {
+				return err
+			}
+		}
+		engine.logDebug("Writing asset bundle '"+outputFilePath+"' of", len(files), "file(s) ...")
+		err = engine.writeTemplateToFile(outputFilePath, content)
+		if err != nil {
+			return err
+		}
+		if bundle.Fingerprint {
+			err = engine.writeFingerprintedFile(bundlePath, content)
+			if err != nil {
+				return err
+			}
+			fingerprinted = true
+		}
+	}
+
+	if !fingerprinted {
+		return nil
+	}
+	return engine.writeAssetManifest() // again, now with the bundles
+}
+
+// getAssetBundleFiles returns the paths of the files of the bundle in the outputDir, in the order they are concatenated.
+// The matches of a glob pattern are sorted by path. Files which weren't copied or compiled from the staticDir, like fingerprinted copies, and files already contained in the bundle are skipped.
+func (engine *Engine) getAssetBundleFiles(bundlePath string, bundle AssetBundle) ([]string, error) {
+	files := []string{}
+	added := make(map[string]bool)
+	for _, file := range bundle.Files {
+		file = path.Clean(strings.TrimPrefix(file, "/"))
+		matches, err := filepath.Glob(filepath.Join(engine.OutputDir, filepath.FromSlash(file)))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(matches)
+
+		matched := false
+		for _, match := range matches {
+			relativePath, err := filepath.Rel(engine.OutputDir, match)
+			if err != nil {
+				return nil, err
+			}
+			relativePath = filepath.ToSlash(relativePath)
+			if !engine.isStaticOutput(relativePath) {
+				continue
+			}
+			matched = true
+			if !added[relativePath] {
+				added[relativePath] = true
+				files = append(files, relativePath)
+			}
+		}
+		if !matched {
+			return nil, errors.New("The file '" + file + "' of the asset bundle '" + bundlePath + "' does not match any static file in '" + engine.StaticDir + "'")
+		}
+	}
+	return files, nil
+}
+
+// isStaticOutput returns whether the file at the relativePath in the outputDir was copied or compiled from the staticDir.
+func (engine *Engine) isStaticOutput(relativePath string) bool {
+	if _, ok := engine.getSassSource(relativePath); ok {
+		return true
+	}
+	info, err := os.Stat(path.Join(engine.StaticDir, relativePath))
+	return err == nil && !info.IsDir()
+}
+
+// getAssetBundleTag returns the tag which includes the asset bundle at bundlePath, a stylesheet link for css and a script for js bundles, with its fingerprinted path if it's fingerprinted.
+// F.e. 'assetBundle "css/site.css"' results in '<link rel="stylesheet" href="/css/site.3fa9c2d1.css">'.
+func (engine *Engine) getAssetBundleTag(bundlePath string) (template.HTML, error) {
+	bundlePath = path.Clean(strings.TrimPrefix(bundlePath, "/"))
+	if _, ok := engine.AssetBundles[bundlePath]; !ok {
+		return "", errors.New("assetBundle: there is no asset bundle '" + bundlePath + "' in the 'assetBundles' option")
+	}
+	assetPath, err := engine.getAsset(bundlePath)
+	if err != nil {
+		return "", err
+	}
+	if path.Ext(bundlePath) == ".css" {
+		return template.HTML(`<link rel="stylesheet" href="` + template.HTMLEscapeString(assetPath) + `">`), nil
+	}
+	return template.HTML(`<script src="` + template.HTMLEscapeString(assetPath) + `"></script>`), nil
+}
+
+// isInFingerprintedBundle returns whether the file at filePath is a static file contained in a fingerprinted asset bundle.
+func (engine *Engine) isInFingerprintedBundle(filePath string) bool {
+	if len(engine.bundledFiles) == 0 || !engine.isInside(filePath, engine.StaticDir) {
+		return false
+	}
+	if isSassFile(filePath) { // stylesheets can import each other, so any of them can change a bundled css file
+		return true
+	}
+	relativePath, err := filepath.Rel(engine.StaticDir, filePath)
+	if err != nil {
+		return false
+	}
+	return engine.bundledFiles[filepath.ToSlash(relativePath)]
+}
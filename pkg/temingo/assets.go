@@ -0,0 +1,119 @@
+package temingo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	gitignore "github.com/sabhiram/go-gitignore"
+)
+
+const assetManifestFileName = "assets.json"
+
+// fingerprintAssets copies each file in the staticDir which matches one of the fingerprintPatterns to the outputDir, with a hash of its content in the file name, f.e. 'css/app.css' to 'css/app.3fa9c2d1.css'.
+// The original file is kept, so other files can still refer to it. The fingerprinted paths are available via the 'asset' template function and listed in 'assets.json' in the outputDir.
+func (engine *Engine) fingerprintAssets() error {
+	engine.assets = make(map[string]string)
+	if len(engine.FingerprintPatterns) == 0 { // if fingerprinting is not configured
+		return nil
+	}
+
+	engine.logDebug("*** Fingerprinting static files ... ***")
+
+	matcher := gitignore.CompileIgnoreLines(engine.FingerprintPatterns...)
+	err := filepath.Walk(engine.StaticDir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		relativePath, err := filepath.Rel(engine.StaticDir, filePath)
+		if err != nil {
+			return err
+		}
+		relativePath = getStaticOutputPath(filepath.ToSlash(relativePath)) // the compiled css of Sass stylesheets
+		if relativePath == "" || !matcher.MatchesPath("/"+relativePath) {
+			return nil
+		}
+
+		content, err := ioutil.ReadFile(path.Join(engine.OutputDir, relativePath)) // the copy, which might be minified
+		if err != nil {
+			return err
+		}
+		return engine.writeFingerprintedFile(relativePath, content)
+	})
+	if err != nil {
+		return err
+	}
+	return engine.writeAssetManifest()
+}
+
+// writeFingerprintedFile writes the content of the asset at the site-relative assetPath to the outputDir, with a hash of the content in the file name, and remembers the fingerprinted path for the 'asset' function.
+func (engine *Engine) writeFingerprintedFile(assetPath string, content []byte) error {
+	hash := sha256.Sum256(content)
+	extension := path.Ext(assetPath)
+	fingerprintedPath := strings.TrimSuffix(assetPath, extension) + "." + hex.EncodeToString(hash[:])[:8] + extension
+	outputFilePath, err := engine.getOutputFilePath(fingerprintedPath)
+	if err != nil {
+		return err
+	}
+	engine.logDebug("Writing fingerprinted file '" + outputFilePath + "' ...")
+	err = engine.writeTemplateToFile(outputFilePath, content)
+	if err != nil {
+		return err
+	}
+	engine.assets[assetPath] = fingerprintedPath
+	return nil
+}
+
+// writeAssetManifest writes the fingerprinted paths of all assets to 'assets.json' in the outputDir.
+func (engine *Engine) writeAssetManifest() error {
+	manifestPath, err := engine.getOutputFilePath(assetManifestFileName)
+	if err != nil {
+		return err
+	}
+	return engine.writeJsonFile(manifestPath, engine.assets)
+}
+
+// getAsset returns the site-relative path of the static file at assetPath, fingerprinted if it matches one of the fingerprintPatterns.
+// F.e. 'asset "css/app.css"' results in '/css/app.3fa9c2d1.css'.
+func (engine *Engine) getAsset(assetPath string) (string, error) {
+	assetPath = path.Clean(strings.TrimPrefix(assetPath, "/"))
+	if fingerprintedPath, ok := engine.assets[assetPath]; ok {
+		return "/" + fingerprintedPath, nil
+	}
+	if _, ok := engine.getSassSource(assetPath); ok {
+		return "/" + assetPath, nil
+	}
+	if _, ok := engine.AssetBundles[assetPath]; ok {
+		return "/" + assetPath, nil
+	}
+	if info, err := os.Stat(path.Join(engine.StaticDir, assetPath)); err != nil || info.IsDir() {
+		return "", errors.New("asset: the static file '" + assetPath + "' does not exist in '" + engine.StaticDir + "'")
+	}
+	return "/" + assetPath, nil
+}
+
+// isFingerprinted returns whether the file at filePath is a static file matching one of the fingerprintPatterns, or part of a fingerprinted asset bundle.
+func (engine *Engine) isFingerprinted(filePath string) bool {
+	if engine.isInFingerprintedBundle(filePath) {
+		return true
+	}
+	if len(engine.FingerprintPatterns) == 0 || !engine.isInside(filePath, engine.StaticDir) {
+		return false
+	}
+	if isSassFile(filePath) { // stylesheets can import each other, so any of them can change a fingerprinted css file
+		return true
+	}
+	relativePath, err := filepath.Rel(engine.StaticDir, filePath)
+	if err != nil {
+		return false
+	}
+	return gitignore.CompileIgnoreLines(engine.FingerprintPatterns...).MatchesPath("/" + filepath.ToSlash(relativePath))
+}
@@ -0,0 +1,25 @@
+package temingo
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+type Breadcrumb struct {
+	Name, Path interface{}
+}
+
+func (engine *Engine) createBreadcrumbs(path string) []Breadcrumb {
+	engine.logDebug("Creating breadcrumbs for '" + path + "'.")
+	breadcrumbs := []Breadcrumb{}
+	currentPath := ""
+	dirNames := strings.Split(filepath.ToSlash(path), "/")
+	for ok := true; ok; ok = (len(dirNames) > 1) { // last one is not considered, so no self-reference occurs
+		currentPath = currentPath + "/" + dirNames[0]
+		breadcrumb := Breadcrumb{dirNames[0], currentPath}
+		breadcrumbs = append(breadcrumbs, breadcrumb)
+		dirNames = dirNames[1:] // remove first one, as it is now added to 'currentPath'
+	}
+
+	return breadcrumbs
+}
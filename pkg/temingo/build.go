@@ -0,0 +1,37 @@
+package temingo
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// getBuildInfo returns the metadata of the current build, which is available as '.Build' in the templates.
+func (engine *Engine) getBuildInfo() (map[string]interface{}, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"Time":        time.Now(),
+		"Version":     engine.Version,
+		"Commit":      engine.getGitCommit(),
+		"Environment": engine.Environment,
+		"ID":          hex.EncodeToString(id),
+	}, nil
+}
+
+// getGitCommit returns the hash of the checked out git commit of the inputDir, or an empty string if it's not part of a git repository.
+func (engine *Engine) getGitCommit() string {
+	command := exec.Command("git", "rev-parse", "HEAD")
+	command.Dir = engine.InputDir
+	output, err := command.Output()
+	if err != nil {
+		engine.logDebug("Could not determine the git commit: " + err.Error())
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
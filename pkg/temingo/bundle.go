@@ -0,0 +1,142 @@
+package temingo
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/otiai10/copy"
+)
+
+// bundleVendorDir is the folder of a bundle the project folders outside of the working directory are copied to, f.e. partials shared by several projects.
+const bundleVendorDir = "vendor"
+
+// Bundle writes a self-contained copy of the project to targetDir, which must not exist or be empty.
+// It contains the input-dir, partials-dir, static-dir, translations-dir and the ignore file. The ones outside of the working directory (f.e. '../shared/partials') are vendored to 'vendor/<name>'.
+// The values files and the additionally passed values are merged into a single 'values.yaml' (plus one per language, if they differ) and all options are written to 'temingo.yaml',
+// so the bundle renders the same with a plain 'temingo build', independent of the original config file, flags and environment.
+func (engine *Engine) Bundle(targetDir string) error {
+	if err := engine.validate(); err != nil {
+		return err
+	}
+	targetDir = path.Clean(targetDir)
+	if dirContents, err := ioutil.ReadDir(targetDir); err == nil && len(dirContents) > 0 {
+		return errors.New("The bundle directory '" + targetDir + "' must not exist or be empty.")
+	}
+
+	bundled := engine.Options
+	bundled.Values = nil
+	bundled.ValuesFilePaths = []string{"values.yaml"}
+	bundled.Environment = engine.Environment // so the bundle doesn't depend on 'TEMINGO_ENV'
+	bundled.SchemaVersion = ConfigSchemaVersion
+	bundled.Verbose, bundled.Debug = bundled.Verbose || bundled.Debug, false // debug is deprecated
+
+	skipped := map[string]bool{ // relative to the working directory
+		targetDir:    true,
+		".git":       true,
+		cacheDir:     true,
+		lockFileName: true,
+	}
+	skipped[engine.OutputDir] = true
+	for _, fileName := range ConfigFileNames { // replaced by the bundled config
+		skipped[fileName] = true
+	}
+	for _, valuesFilePath := range engine.ValuesFilePaths { // replaced by the bundled values
+		skipped[valuesFilePath] = true
+		skipped[engine.getEnvironmentValuesFilePath(valuesFilePath)] = true // the environment of the bundle is fixed, so its values are bundled as well
+		for _, language := range engine.Languages {
+			engine.language = language
+			skipped[engine.getLanguageValuesFilePath(valuesFilePath)] = true
+		}
+		engine.language = ""
+	}
+
+	for _, source := range []struct {
+		name string
+		path *string
+	}{
+		{"input", &bundled.InputDir},
+		{"partials", &bundled.PartialsDir},
+		{"static", &bundled.StaticDir},
+		{"i18n", &bundled.TranslationsDir},
+		{"data", &bundled.DataDir},
+		{"temingoignore", &bundled.TemingoignoreFilePath},
+	} {
+		sourcePath := cleanPath(*source.path)
+		*source.path = getBundledPath(sourcePath, source.name)
+		if _, err := os.Stat(sourcePath); os.IsNotExist(err) { // f.e. a site without translations
+			continue
+		}
+		engine.logDebug("Bundling '" + sourcePath + "' as '" + *source.path + "' ...")
+		err := copy.Copy(sourcePath, path.Join(targetDir, *source.path), copy.Options{
+			Skip: func(src string) (bool, error) {
+				return skipped[path.Clean(filepath.ToSlash(src))], nil
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	bundled.OutputDir = getBundledPath(engine.OutputDir, "output")
+	if err := os.MkdirAll(path.Join(targetDir, bundled.OutputDir), engine.dirMode); err != nil {
+		return err
+	}
+
+	mappedValues, err := engine.getMappedValues()
+	if err != nil {
+		return err
+	}
+	if err := engine.writeBundledValues(path.Join(targetDir, bundled.TemingoignoreFilePath), path.Join(targetDir, bundled.ValuesFilePaths[0]), mappedValues); err != nil {
+		return err
+	}
+	for _, language := range engine.Languages {
+		engine.language = language
+		languageValues, err := engine.getMappedValues()
+		languageValuesFilePath := engine.getLanguageValuesFilePath(bundled.ValuesFilePaths[0])
+		engine.language = ""
+		if err != nil {
+			return err
+		}
+		if reflect.DeepEqual(languageValues, mappedValues) { // the language has no values of its own
+			continue
+		}
+		if err := engine.writeBundledValues(path.Join(targetDir, bundled.TemingoignoreFilePath), path.Join(targetDir, languageValuesFilePath), languageValues); err != nil {
+			return err
+		}
+	}
+
+	config, err := marshalYaml(bundled)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path.Join(targetDir, ConfigFileNames[0]), config, engine.fileMode); err != nil {
+		return err
+	}
+
+	engine.logInfo("*** Bundled the project to '" + targetDir + "', render it there with 'temingo build'. ***")
+	return nil
+}
+
+// writeBundledValues writes the values to valuesFilePath and keeps the file out of the output-dir via the ignore file of the bundle at temingoignoreFilePath.
+func (engine *Engine) writeBundledValues(temingoignoreFilePath string, valuesFilePath string, values map[string]interface{}) error {
+	valuesYaml, err := marshalYaml(values)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(valuesFilePath, valuesYaml, engine.fileMode); err != nil {
+		return err
+	}
+	return engine.appendTemingoignore(temingoignoreFilePath, "/"+path.Base(valuesFilePath)+"\n")
+}
+
+// getBundledPath returns the path of the project folder or file at sourcePath within the bundle. Paths inside the working directory are kept, the others are vendored to 'vendor/<name>'.
+func getBundledPath(sourcePath string, name string) string {
+	if filepath.IsAbs(sourcePath) || sourcePath == ".." || strings.HasPrefix(sourcePath, "../") {
+		return path.Join(bundleVendorDir, name)
+	}
+	return sourcePath
+}
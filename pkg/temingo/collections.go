@@ -0,0 +1,283 @@
+package temingo
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configFileNames contains the names of the per-collection config files. They are never copied to the outputDir.
+var configFileNames = []string{}
+
+// getConfigFiles returns the paths of all files with the given name inside the inputDir, f.e. all 'blog/epub.yaml'.
+func (engine *Engine) getConfigFiles(fileName string) ([]string, error) {
+	var configFiles []string
+
+	err := filepath.Walk(engine.InputDir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		filePath = filepath.ToSlash(filePath)
+		if filePath != engine.InputDir && strings.HasPrefix(info.Name(), ".") { // ignore hidden files/folders
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filePath != engine.InputDir && (engine.isExcluded(filePath, []string{}) || engine.isExcludedByTemingoignore(filePath, []string{})) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.IsDir() && info.Name() == fileName {
+			configFiles = append(configFiles, filePath)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return configFiles, nil
+}
+
+// isChangedCollection returns whether the exports of the collection at collectionPath, like its feeds, calendars or EPUB, have to be written again.
+// That's always the case for full builds. Incremental rebuilds only export the collections which contain a changed or rerendered file again.
+func (engine *Engine) isChangedCollection(collectionPath string) bool {
+	if engine.changedFiles == nil {
+		return true
+	}
+	for filePath := range engine.changedFiles {
+		if engine.isInside(filePath, collectionPath) {
+			return true
+		}
+	}
+	for filePath := range engine.renderedFiles {
+		if engine.isInside(filePath, path.Join(engine.OutputDir, collectionPath)) {
+			return true
+		}
+	}
+	engine.logDebug("Skipping the exports of '" + collectionPath + "', as none of its files changed.")
+	return false
+}
+
+// isChangedExport returns whether an export which only depends on the files at inputPaths, like an OPML file, has to be written again.
+// That's always the case for full builds. Incremental rebuilds only export it again if one of them changed.
+func (engine *Engine) isChangedExport(inputPaths ...string) bool {
+	if engine.changedFiles == nil {
+		return true
+	}
+	for filePath := range engine.changedFiles {
+		for _, inputPath := range inputPaths {
+			if inputPath != "" && path.Clean(filePath) == path.Clean(inputPath) {
+				return true
+			}
+		}
+	}
+	engine.logDebug("Skipping the export of '" + inputPaths[0] + "', as none of its files changed.")
+	return false
+}
+
+// loadConfigFile reads the yaml file at filePath into the struct config points to.
+func loadConfigFile(filePath string, config interface{}) error {
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	err = yaml.Unmarshal(content, config)
+	if err != nil {
+		return errors.New("Could not parse '" + filePath + "': " + err.Error())
+	}
+	return nil
+}
+
+// getSortedListObjects returns the list objects of listPath as slice, sorted by the keys in sortBy (see parseSortKeys), f.e. 'weight, date desc'.
+// Objects without a value are placed at the end. If sortBy is empty, the objects are sorted by their path.
+func (engine *Engine) getSortedListObjects(listPath string, sortBy string, reverse bool) ([]map[string]interface{}, error) {
+	if sortBy == "" {
+		sortBy = "Path"
+	}
+	sortKeys, err := parseSortKeys(strings.Split(sortBy, ","))
+	if err != nil {
+		return nil, errors.New("Could not sort '" + listPath + "': " + err.Error())
+	}
+
+	listObjects := []map[string]interface{}{}
+	unsorted, err := engine.loadListObjects(listPath, false)
+	if err != nil {
+		return nil, err
+	}
+	collection, err := toCollection(unsorted) // ordered by path, so objects with the same value keep their order between builds
+	if err != nil {
+		return nil, err
+	}
+	for _, listObject := range collection {
+		listObjects = append(listObjects, listObject.(map[string]interface{}))
+	}
+
+	sort.SliceStable(listObjects, func(i, j int) bool {
+		if reverse {
+			return lessByKeys(listObjects[j], listObjects[i], sortKeys)
+		}
+		return lessByKeys(listObjects[i], listObjects[j], sortKeys)
+	})
+
+	return listObjects, nil
+}
+
+// sortKey is one of the keys a collection is sorted by.
+type sortKey struct {
+	key        string
+	descending bool
+}
+
+// parseSortKeys parses the keys a collection is sorted by, in the order of their priority.
+// Each key can be followed by its order, either within the same argument ('date desc') or as the next argument ('date', 'desc').
+func parseSortKeys(args []string) ([]sortKey, error) {
+	sortKeys := []sortKey{}
+	for _, arg := range args {
+		fields := strings.Fields(arg)
+		switch {
+		case len(fields) == 0:
+			continue
+		case len(fields) == 1 && (fields[0] == "asc" || fields[0] == "desc"): // order of the previous key
+			if len(sortKeys) == 0 {
+				return nil, errors.New("the order '" + fields[0] + "' must follow a key")
+			}
+			sortKeys[len(sortKeys)-1].descending = fields[0] == "desc"
+		case len(fields) == 1:
+			sortKeys = append(sortKeys, sortKey{key: fields[0]})
+		case len(fields) == 2 && (fields[1] == "asc" || fields[1] == "desc"):
+			sortKeys = append(sortKeys, sortKey{key: fields[0], descending: fields[1] == "desc"})
+		default:
+			return nil, errors.New("'" + arg + "' is neither a key nor a key with order ('asc' or 'desc')")
+		}
+	}
+	if len(sortKeys) == 0 {
+		return nil, errors.New("at least one key is required")
+	}
+	return sortKeys, nil
+}
+
+// lessByKeys compares two elements by the sortKeys, where later keys are only used if the elements are equal on the previous ones.
+// Elements without the value of a key are placed after the ones with value.
+func lessByKeys(a interface{}, b interface{}, sortKeys []sortKey) bool {
+	for _, sortKey := range sortKeys {
+		aValue, aOk := getField(a, sortKey.key)
+		bValue, bOk := getField(b, sortKey.key)
+		if !aOk || !bOk {
+			if aOk != bOk {
+				return aOk // elements with value before elements without
+			}
+			continue
+		}
+		if sortKey.descending {
+			aValue, bValue = bValue, aValue
+		}
+		if lessValue(aValue, bValue) {
+			return true
+		}
+		if lessValue(bValue, aValue) {
+			return false
+		}
+	}
+	return false
+}
+
+// lessValue compares two values from yaml files. Dates and numbers are compared by their value, everything else by its string representation in natural order.
+func lessValue(a interface{}, b interface{}) bool {
+	if aTime, ok := toTime(a); ok {
+		if bTime, ok := toTime(b); ok {
+			return aTime.Before(bTime)
+		}
+	}
+	if aNumber, ok := toFloat(a); ok {
+		if bNumber, ok := toFloat(b); ok {
+			return aNumber < bNumber
+		}
+	}
+	return naturalLess(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// naturalLess compares strings with numbers in them by the value of the numbers, so 'item2' is placed before 'item10'.
+func naturalLess(a string, b string) bool {
+	for a != "" && b != "" {
+		aIsDigit, bIsDigit := isDigit(a[0]), isDigit(b[0])
+		if aIsDigit && bIsDigit {
+			aNumber, bNumber := a[:digitsLength(a)], b[:digitsLength(b)]
+			a, b = a[len(aNumber):], b[len(bNumber):]
+			aTrimmed, bTrimmed := strings.TrimLeft(aNumber, "0"), strings.TrimLeft(bNumber, "0")
+			if len(aTrimmed) != len(bTrimmed) { // more digits means a bigger number
+				return len(aTrimmed) < len(bTrimmed)
+			}
+			if aTrimmed != bTrimmed {
+				return aTrimmed < bTrimmed
+			}
+			continue
+		}
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) < len(b)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// digitsLength returns the number of digits at the start of s.
+func digitsLength(s string) int {
+	length := 0
+	for length < len(s) && isDigit(s[length]) {
+		length++
+	}
+	return length
+}
+
+// toTime converts dates from yaml files to time.Time. Unquoted dates are already parsed by the yaml library, quoted ones are strings.
+func toTime(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02"} {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// toString returns the string representation of value, or an empty string if there is none.
+func toString(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,60 @@
+package temingo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+)
+
+// commandCacheDir is the folder in the cacheDir the outputs of external commands like the sassCommand are cached in, so later builds - f.e. in CI with a restored cache - don't run them again for unchanged inputs.
+var commandCacheDir = path.Join(cacheDir, "commands")
+
+// getCommandCacheKey returns the hash of everything the output of the command depends on: the version of temingo, the command and the contents of inputPath and of the dependencies, f.e. the stylesheets imported by a Sass stylesheet.
+func (engine *Engine) getCommandCacheKey(command string, inputPath string, dependencies []string) (string, error) {
+	hash := sha256.New()
+	hash.Write([]byte(engine.Version + "\n" + command + "\n"))
+	files := append([]string{inputPath}, dependencies...)
+	sort.Strings(files[1:]) // so the order of the dependencies doesn't matter
+	for _, filePath := range files {
+		content, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return "", err
+		}
+		contentHash := sha256.Sum256(content)
+		hash.Write([]byte(filePath + "\n" + hex.EncodeToString(contentHash[:]) + "\n"))
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// runCachedFileCommand runs the external command like runFileCommand, unless its output for the same inputs is cached in the commandCacheDir. Then the cached output is written to outputPath instead.
+// Failing to cache the output is only logged as warning, as the command is run again next time.
+func (engine *Engine) runCachedFileCommand(command string, purpose string, inputPath string, outputPath string, dependencies []string) error {
+	key, err := engine.getCommandCacheKey(command, inputPath, dependencies)
+	if err != nil {
+		return err
+	}
+	cacheFilePath := path.Join(commandCacheDir, key)
+	if output, err := ioutil.ReadFile(cacheFilePath); err == nil {
+		engine.logDebug("Reusing the cached output of the " + purpose + " of '" + inputPath + "' ...")
+		return ioutil.WriteFile(outputPath, output, engine.fileMode)
+	}
+
+	if err := engine.runFileCommand(command, purpose, inputPath, outputPath); err != nil {
+		return err
+	}
+	output, err := ioutil.ReadFile(outputPath)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(commandCacheDir, engine.dirMode); err != nil {
+		engine.logWarn("Could not cache the output of the " + purpose + " of '" + inputPath + "': " + err.Error())
+		return nil
+	}
+	if err := ioutil.WriteFile(cacheFilePath, output, engine.fileMode); err != nil {
+		engine.logWarn("Could not cache the output of the " + purpose + " of '" + inputPath + "': " + err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package temingo
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileNames are the names of the project config files, which are looked for in the working directory in this order.
+var ConfigFileNames = []string{"temingo.yaml", ".temingo.yml", ".temingo.yaml"}
+
+func init() {
+	configFileNames = append(configFileNames, ConfigFileNames...)
+}
+
+// FindConfigFile returns the path of the first existing project config file in the working directory, or an empty string if there is none.
+func FindConfigFile() string {
+	for _, fileName := range ConfigFileNames {
+		if _, err := os.Stat(fileName); err == nil {
+			return fileName
+		}
+	}
+	return ""
+}
+
+// LoadConfigFile reads the project config file at filePath into the options.
+// Its keys are the names of the cli flags, f.e. 'inputDir' or 'valuesfile'. Only the options contained in the file are changed, unknown keys are rejected.
+// Files written for a newer schemaVersion than ConfigSchemaVersion are rejected before their keys are checked, as they might contain options this version doesn't know yet.
+func LoadConfigFile(filePath string, options *Options) error {
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	versioned := struct {
+		SchemaVersion int `yaml:"schemaVersion"`
+	}{}
+	if err := yaml.Unmarshal(content, &versioned); err != nil {
+		return errors.New("Could not parse '" + filePath + "': " + err.Error())
+	}
+	if versioned.SchemaVersion > ConfigSchemaVersion {
+		return errors.New("The project config file '" + filePath + "' is written for a newer version of temingo, as its schemaVersion " + strconv.Itoa(versioned.SchemaVersion) + " is higher than the supported " + strconv.Itoa(ConfigSchemaVersion) + ".")
+	}
+	if versioned.SchemaVersion < 0 {
+		return errors.New("The schemaVersion of the project config file '" + filePath + "' must be positive, but is " + strconv.Itoa(versioned.SchemaVersion) + ".")
+	}
+	options.configFilePath = filePath
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true) // so typos don't go unnoticed
+	err = decoder.Decode(options)
+	if err != nil && err != io.EOF { // an empty file is a valid config
+		return errors.New("Could not parse '" + filePath + "': " + err.Error())
+	}
+	return nil
+}
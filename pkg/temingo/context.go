@@ -0,0 +1,62 @@
+package temingo
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// createContext returns the data passed to the template templateName, which is rendered to outputFilePath.
+// By default, the data is namespaced into '.Values' (the merged values files), '.Data' (the files of the dataDir), '.Site' (global data), '.Page' (page metadata), '.Build' (build metadata), '.Env' (the environment) and '.Item'/'.ItemPath' (only for single-views), so none of them can collide with the others.
+// With flatContext, the old layout is used instead, where the values are placed at the top-level together with 'breadcrumbs', 'Item' and 'ItemPath'.
+func (engine *Engine) createContext(mappedValues map[string]interface{}, templateName string, outputFilePath string, item interface{}, itemPath string) map[string]interface{} {
+	breadcrumbs := engine.createBreadcrumbs(path.Dir(templateName))
+
+	if engine.FlatContext {
+		context := make(map[string]interface{}) // a copy, so injected keys don't leak into the renders of other templates
+		for key, value := range copyValues(mappedValues) {
+			context[key] = value
+		}
+		injected := map[string]interface{}{"breadcrumbs": breadcrumbs}
+		if item != nil {
+			injected["Item"] = copyValue(item)
+			injected["ItemPath"] = itemPath
+		}
+		for key, value := range injected {
+			if _, ok := context[key]; ok {
+				engine.logWarn("The value '" + key + "' is overwritten for '" + templateName + "', as it is reserved in the flat context.")
+			}
+			context[key] = value
+		}
+		return context
+	}
+
+	pagePath := "/" + strings.TrimPrefix(filepath.ToSlash(strings.TrimPrefix(outputFilePath, engine.OutputDir)), "/")
+	menus, _ := getMenus(mappedValues[menusValuesKey], pagePath) // validated while reading the values
+	context := map[string]interface{}{
+		"Values": copyValues(mappedValues), // each output gets its own copy, as templates can modify them (f.e. via 'set') while others are rendered concurrently
+		"Data":   copyValues(engine.data),
+		"Site": map[string]interface{}{
+			"BaseURL":    engine.siteBaseURL,
+			"Pages":      engine.sitePages,
+			"Redirects":  engine.server.Redirects,
+			"Headers":    engine.server.Headers,
+			"Language":   engine.language,
+			"Languages":  engine.Languages,
+			"Taxonomies": engine.taxonomies,
+		},
+		"Page": map[string]interface{}{
+			"Path":        pagePath,
+			"Template":    templateName,
+			"Breadcrumbs": breadcrumbs,
+		},
+		"Build": copyValues(engine.buildInfo),
+		"Env":   engine.Environment,
+		"Menus": menus,
+	}
+	if item != nil {
+		context["Item"] = copyValue(item)
+		context["ItemPath"] = itemPath
+	}
+	return context
+}
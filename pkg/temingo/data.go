@@ -0,0 +1,104 @@
+package temingo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// dataExtensions are the extensions of the files in the dataDir which are loaded as data.
+var dataExtensions = map[string]bool{".yaml": true, ".yml": true, ".json": true, ".toml": true}
+
+// loadData reads all yaml, json and toml files in the dataDir, which are available to templates as '.Data'.
+// Each file is available by its path relative to the dataDir without extension, split at folders, f.e. 'data/team.yaml' as '.Data.team' and 'data/products/specs.json' as '.Data.products.specs'.
+func (engine *Engine) loadData() error {
+	engine.data = make(map[string]interface{})
+	if info, err := os.Stat(engine.DataDir); os.IsNotExist(err) || (err == nil && !info.IsDir()) { // a site without data
+		return nil
+	}
+
+	engine.logDebug("*** Reading data files ... ***")
+
+	sources := make(map[string]string) // the file each key was loaded from, to report collisions
+	return filepath.Walk(engine.DataDir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if filePath != engine.DataDir && strings.HasPrefix(info.Name(), ".") { // ignore hidden files/folders
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() || !dataExtensions[path.Ext(info.Name())] {
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(engine.DataDir, filePath)
+		if err != nil {
+			return err
+		}
+		relativePath = filepath.ToSlash(relativePath)
+		key := strings.TrimSuffix(relativePath, path.Ext(relativePath))
+		if source, ok := sources[key]; ok {
+			return errors.New("Both '" + source + "' and '" + filePath + "' are available as data '" + key + "', rename one of them.")
+		}
+		sources[key] = filePath
+
+		value, err := loadDataValue(filePath)
+		if err != nil {
+			return err
+		}
+		engine.logDebug("Loading data '" + key + "' from '" + filePath + "'.")
+
+		data := engine.data
+		parts := strings.Split(key, "/")
+		for _, part := range parts[:len(parts)-1] {
+			folder, ok := data[part].(map[string]interface{})
+			if _, exists := data[part]; exists && !ok {
+				return errors.New("The data folder '" + path.Join(engine.DataDir, part) + "' collides with the data file of the same name, rename one of them.")
+			}
+			if !ok {
+				folder = make(map[string]interface{})
+				data[part] = folder
+			}
+			data = folder
+		}
+		if _, exists := data[parts[len(parts)-1]]; exists {
+			return errors.New("The data file '" + filePath + "' collides with the data folder of the same name, rename one of them.")
+		}
+		data[parts[len(parts)-1]] = value
+		return nil
+	})
+}
+
+// loadDataValue returns the content of a yaml, json or toml file. Unlike values files, yaml and json files can contain lists as well.
+func loadDataValue(filePath string) (interface{}, error) {
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	switch path.Ext(filePath) {
+	case ".toml":
+		values := make(map[string]interface{})
+		_, err = toml.Decode(string(content), &values)
+		value = normalizeTomlValue(values)
+	case ".json":
+		err = json.Unmarshal(content, &value)
+	default:
+		err = yaml.Unmarshal(bytes.TrimSpace(content), &value)
+	}
+	if err != nil {
+		return nil, errors.New("Could not parse '" + filePath + "': " + err.Error())
+	}
+	return value, nil
+}
@@ -0,0 +1,150 @@
+package temingo
+
+import (
+	"errors"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// dataPage is one page generated from an element of a values collection.
+type dataPage struct {
+	ItemPath string      // site-relative folder of the page, f.e. 'team/alice'
+	Item     interface{} // the element of the collection
+}
+
+// getDataPages returns the pages for a template that declares 'generate' in its front matter, and false if it doesn't.
+// 'generate.from' is the dotted path of a list or map in the values, 'generate.slug' the (optional) key of each element its folder is named after.
+// Without slug, list elements are numbered and map elements named by their keys.
+// F.e. 'team/index.html.template' with 'generate: {from: team, slug: name}' results in 'team/<name>/index.html' for each team member.
+// Elements resulting in the same folder are handled according to the slugCollisions option, see resolveSlugCollisions.
+func (engine *Engine) getDataPages(templateName string, frontMatter map[string]interface{}, values map[string]interface{}) ([]dataPage, bool, error) {
+	generate, ok := frontMatter["generate"]
+	if !ok {
+		return nil, false, nil
+	}
+	config, ok := generate.(map[string]interface{})
+	if !ok || toString(config["from"]) == "" {
+		return nil, true, errors.New("The front matter 'generate' of '" + templateName + "' must contain a 'from' value.")
+	}
+	from := toString(config["from"])
+	slugKey := toString(config["slug"])
+
+	collection, ok := lookupValue(values, from)
+	if !ok {
+		return nil, true, errors.New("The collection '" + from + "' that '" + templateName + "' should be generated from does not exist in the values.")
+	}
+
+	type element struct {
+		name   string
+		source string // f.e. 'team[2]' or 'team.alice', to tell which elements collide
+		value  interface{}
+	}
+	elements := []element{}
+	switch c := collection.(type) {
+	case []interface{}:
+		for i, value := range c {
+			elements = append(elements, element{strconv.Itoa(i + 1), from + "[" + strconv.Itoa(i) + "]", value})
+		}
+	case map[string]interface{}:
+		for key, value := range c {
+			elements = append(elements, element{key, from + "." + key, value})
+		}
+		sort.Slice(elements, func(i, j int) bool { return elements[i].name < elements[j].name })
+	default:
+		return nil, true, errors.New("The value '" + from + "' that '" + templateName + "' should be generated from is neither a list nor a map.")
+	}
+
+	slugs := []string{}
+	sources := []string{}
+	for _, element := range elements {
+		name := element.name
+		if slugKey != "" {
+			slug, ok := getField(element.value, slugKey)
+			if !ok || toString(slug) == "" {
+				return nil, true, errors.New("An element of '" + from + "' has no '" + slugKey + "' value to generate the page of '" + templateName + "' for.")
+			}
+			name = toString(slug)
+		}
+		slug, err := engine.urlize(name)
+		if err != nil {
+			return nil, true, err
+		}
+		if slug == "" || slug == "." || slug == ".." || strings.ContainsAny(slug, "/\\") { // would escape the folder of the pages, f.e. overwriting the root 'index.html'
+			return nil, true, errors.New("The slug '" + name + "' of '" + element.source + "' for the pages of '" + templateName + "' must be the name of a folder, not '.', '..' or a path.")
+		}
+		slugs = append(slugs, slug)
+		sources = append(sources, element.source)
+	}
+
+	slugs, err := engine.resolveSlugCollisions(templateName, slugs, sources)
+	if err != nil {
+		return nil, true, err
+	}
+	pages := []dataPage{}
+	for i, element := range elements {
+		itemValues, _ := element.value.(map[string]interface{})
+		permalink := toString(itemValues["permalink"]) // the permalink of the element overrides the one of the template
+		if permalink == "" {
+			permalink = toString(frontMatter["permalink"])
+		}
+		itemPath, err := engine.getItemOutputPath(templateName, permalink, itemValues, path.Join(path.Dir(templateName), slugs[i]))
+		if err != nil {
+			return nil, true, err
+		}
+		pages = append(pages, dataPage{
+			ItemPath: itemPath,
+			Item:     element.value,
+		})
+	}
+	return pages, true, nil
+}
+
+// resolveSlugCollisions handles elements whose slugs are the same, so no generated page silently overwrites another one.
+// With the slugCollisions option 'fail', the colliding sources are returned as error. With 'suffix', the first element keeps its slug and the following ones get the next free '-2', '-3', ... suffix.
+func (engine *Engine) resolveSlugCollisions(templateName string, slugs []string, sources []string) ([]string, error) {
+	taken := make(map[string]int) // index of the element each slug was taken by
+	for i, slug := range slugs {
+		if _, ok := taken[slug]; !ok {
+			taken[slug] = i
+		}
+	}
+
+	resolved := append([]string{}, slugs...)
+	for i, slug := range slugs {
+		first := taken[slug]
+		if first == i {
+			continue
+		}
+		if engine.SlugCollisions != "suffix" {
+			return nil, errors.New("Both '" + sources[first] + "' and '" + sources[i] + "' result in the slug '" + slug + "' for the pages of '" + templateName + "'. Make their slugs unique or set '--slugCollisions suffix'.")
+		}
+		for n := 2; ; n++ {
+			candidate := slug + "-" + strconv.Itoa(n)
+			if _, ok := taken[candidate]; !ok {
+				resolved[i] = candidate
+				taken[candidate] = i
+				break
+			}
+		}
+		engine.logDebug("The slug '" + slug + "' of '" + sources[i] + "' is already taken by '" + sources[first] + "', using '" + resolved[i] + "' instead.")
+	}
+	return resolved, nil
+}
+
+// lookupValue returns the value at the dotted keyPath, f.e. 'company.team'.
+func lookupValue(values map[string]interface{}, keyPath string) (interface{}, bool) {
+	var current interface{} = values
+	for _, key := range strings.Split(keyPath, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = object[key]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
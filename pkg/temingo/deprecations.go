@@ -0,0 +1,76 @@
+package temingo
+
+import (
+	"errors"
+)
+
+// ConfigSchemaVersion is the version of the options schema, which project config files declare via 'schemaVersion'.
+// It's increased whenever options change incompatibly, so older temingo versions reject configs they would misunderstand.
+const ConfigSchemaVersion = 1
+
+// deprecation is a flag, option, template function or convention which will be removed with the next major version.
+type deprecation struct {
+	name string // what is deprecated, f.e. "The option 'debug' ('--debug')"
+	hint string // how to migrate
+}
+
+// deprecations are all deprecations by their id. Each is logged once per engine, and is an error with the future option.
+var deprecations = map[string]deprecation{
+	"debug": {
+		"The option 'debug' ('--debug', '-d')",
+		"use 'verbose' ('--verbose') instead, which logs the same",
+	},
+	"flatContext": {
+		"The flat template context ('flatContext', '--flatContext')",
+		"use the namespaced context instead, f.e. '.Values.title' instead of '.title' and '.Page.Breadcrumbs' instead of '.breadcrumbs'",
+	},
+	"capitalize": {
+		"The template function 'capitalize'",
+		"use 'title' instead, which does the same",
+	},
+	"unversionedConfig": {
+		"A project config file without 'schemaVersion'",
+		"add 'schemaVersion: 1' to it",
+	},
+}
+
+// deprecatedFunctions are the ids of the deprecations of template functions, by function name.
+var deprecatedFunctions = map[string]string{
+	"capitalize": "capitalize",
+}
+
+// useDeprecated logs the deprecation with id, the first time it's used. With the future option, deprecations are handled as if they were already removed, so an error is returned instead.
+func (engine *Engine) useDeprecated(id string) error {
+	deprecation := deprecations[id]
+	if engine.Future {
+		return errors.New(deprecation.name + " is deprecated and not available with '--future', " + deprecation.hint + ".")
+	}
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+	if engine.warnedDeprecations[id] {
+		return nil
+	}
+	engine.warnedDeprecations[id] = true
+	engine.logWarn(deprecation.name + " is deprecated and will be removed with the next major version, " + deprecation.hint + ".")
+	return nil
+}
+
+// validateDeprecations checks the options and the project config file for deprecated ones.
+func (engine *Engine) validateDeprecations() error {
+	if engine.configFilePath != "" && engine.SchemaVersion == 0 {
+		if err := engine.useDeprecated("unversionedConfig"); err != nil {
+			return err
+		}
+	}
+	if engine.Debug {
+		if err := engine.useDeprecated("debug"); err != nil {
+			return err
+		}
+	}
+	if engine.FlatContext {
+		if err := engine.useDeprecated("flatContext"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
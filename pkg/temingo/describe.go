@@ -0,0 +1,136 @@
+package temingo
+
+import (
+	"sort"
+)
+
+// ProjectModel is the resolved structure of a project, see Describe. It's meant for tools like editor extensions, f.e. to complete the names of partials and values keys.
+type ProjectModel struct {
+	Templates       []string        `json:"templates"`       // paths of the templates
+	SingleTemplates []string        `json:"singleTemplates"` // paths of the single-view templates
+	MarkdownFiles   []string        `json:"markdownFiles"`   // paths of the markdown content files
+	Partials        []string        `json:"partials"`        // names of the partials and the templates they define, as used by 'include' and 'template'
+	ValuesKeys      []string        `json:"valuesKeys"`      // dotted key paths of the merged values, as used by 'getValue' and in '.Values'
+	DataKeys        []string        `json:"dataKeys"`        // dotted key paths of the data files, as used in '.Data'
+	Pages           []ProjectPage   `json:"pages"`           // the pages and items of the site, as returned by 'pages'
+	Outputs         []ProjectOutput `json:"outputs"`         // the files rendered into the outputDir, of all languages
+	Languages       []string        `json:"languages"`       // the languages the site is rendered in, empty without languages
+	Functions       []string        `json:"functions"`       // names of the functions available in templates
+}
+
+// ProjectPage is a page or item of the site in the ProjectModel.
+type ProjectPage struct {
+	Path     string `json:"path"`               // site-relative path of the page
+	Kind     string `json:"kind"`               // 'page', 'item' or 'term'
+	Section  string `json:"section"`            // top-level folder of the page
+	Template string `json:"template,omitempty"` // the template or markdown file the page is rendered from, if known
+}
+
+// ProjectOutput is a file rendered into the outputDir in the ProjectModel.
+type ProjectOutput struct {
+	Path   string `json:"path"`   // path of the file in the outputDir
+	Source string `json:"source"` // the template or markdown file it's rendered from
+}
+
+// Describe resolves the structure of the project without rendering it: its templates, partials, values keys, pages and which outputs are rendered from which template.
+// Pages are the ones of the default language, outputs the ones of all languages.
+func (engine *Engine) Describe() (ProjectModel, error) {
+	if err := engine.validate(); err != nil {
+		return ProjectModel{}, err
+	}
+	if err := engine.loadTemingoignore(); err != nil {
+		return ProjectModel{}, err
+	}
+	model := ProjectModel{Languages: append([]string{}, engine.Languages...), Outputs: []ProjectOutput{}}
+	defer func() { engine.language = "" }()
+	languages := engine.getLanguages()
+	for i := len(languages) - 1; i >= 0; i-- { // the default language last, so its sources and pages are described
+		engine.language = languages[i]
+		sources, err := engine.readSources()
+		if err != nil {
+			return ProjectModel{}, err
+		}
+		if err := engine.collectPages(sources); err != nil {
+			return ProjectModel{}, err
+		}
+		jobs, err := engine.getJobs(sources, func(string) bool { return true })
+		if err != nil {
+			return ProjectModel{}, err
+		}
+		for _, job := range jobs {
+			model.Outputs = append(model.Outputs, ProjectOutput{Path: job.outputFilePath, Source: job.templateName})
+		}
+		if i > 0 {
+			continue
+		}
+
+		model.Templates = getFileNames(sources.templates)
+		model.SingleTemplates = getFileNames(sources.singleTemplates)
+		model.MarkdownFiles = getFileNames(sources.markdownFiles)
+		partials := make(map[string]bool)
+		for _, partial := range sources.partials {
+			partials[partial[0]] = true
+			for _, match := range templateDefinitionRegexp.FindAllStringSubmatch(partial[1], -1) {
+				partials[match[1]] = true
+			}
+		}
+		model.Partials = getSortedKeys(partials)
+		model.ValuesKeys = getKeyPaths(sources.values)
+		model.DataKeys = getKeyPaths(engine.data)
+		model.Pages = []ProjectPage{}
+		for _, page := range engine.sitePages {
+			values := page.(map[string]interface{})
+			model.Pages = append(model.Pages, ProjectPage{
+				Path:     toString(values["Path"]),
+				Kind:     toString(values["Kind"]),
+				Section:  toString(values["Section"]),
+				Template: toString(values["Template"]),
+			})
+		}
+	}
+	sort.Slice(model.Outputs, func(i, j int) bool { return model.Outputs[i].Path < model.Outputs[j].Path })
+
+	functions, err := engine.Funcs()
+	if err != nil {
+		return ProjectModel{}, err
+	}
+	for _, function := range functions {
+		model.Functions = append(model.Functions, function.Name)
+	}
+	return model, nil
+}
+
+// getFileNames returns the paths of files read as pairs of path and content.
+func getFileNames(files [][]string) []string {
+	names := []string{}
+	for _, file := range files {
+		names = append(names, file[0])
+	}
+	return names
+}
+
+// getSortedKeys returns the keys of set, sorted.
+func getSortedKeys(set map[string]bool) []string {
+	keys := []string{}
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// getKeyPaths returns the dotted key paths of all keys of values at any depth, sorted, f.e. 'theme' and 'theme.colors' for nested maps. Lists aren't descended into.
+func getKeyPaths(values map[string]interface{}) []string {
+	keyPaths := make(map[string]bool)
+	var walk func(prefix string, values map[string]interface{})
+	walk = func(prefix string, values map[string]interface{}) {
+		for key, value := range values {
+			keyPaths[prefix+key] = true
+			if nested, ok := value.(map[string]interface{}); ok {
+				walk(prefix+key+".", nested)
+			}
+		}
+	}
+	walk("", values)
+	return getSortedKeys(keyPaths)
+}
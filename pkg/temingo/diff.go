@@ -0,0 +1,170 @@
+package temingo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// BuildManifest describes the files of a build, so later builds can be compared against it, see Diff.
+type BuildManifest struct {
+	Version string                  `json:"version"` // of temingo
+	Time    string                  `json:"time"`    // when the build finished
+	Files   map[string]ManifestFile `json:"files"`   // the files of the outputDir by their site-relative path, f.e. '/blog/index.html'
+}
+
+// ManifestFile is a file of the outputDir in the BuildManifest.
+type ManifestFile struct {
+	Hash  string   `json:"hash"`            // sha256 of the content
+	Title string   `json:"title,omitempty"` // the '<title>' of html pages
+	Links []string `json:"links,omitempty"` // the site-relative targets of the internal links of html pages, sorted
+}
+
+// OutputDiff is the change report of a build compared to a previous one, see Diff.
+type OutputDiff struct {
+	Added        []DiffFile    `json:"added"`        // files which are new
+	Removed      []DiffFile    `json:"removed"`      // files which don't exist anymore
+	Changed      []DiffFile    `json:"changed"`      // files whose content changed
+	RemovedLinks []RemovedLink `json:"removedLinks"` // internal links which disappeared from pages existing in both builds
+}
+
+// DiffFile is an added, removed or changed file in the OutputDiff.
+type DiffFile struct {
+	Path  string `json:"path"`            // site-relative path of the file
+	Title string `json:"title,omitempty"` // the '<title>' of html pages, the previous one for removed pages
+}
+
+// RemovedLink is an internal link which disappeared from a page in the OutputDiff.
+type RemovedLink struct {
+	Page   string `json:"page"`   // site-relative path of the page
+	Target string `json:"target"` // site-relative path the page linked to
+}
+
+// getManifest returns the BuildManifest of the files currently in the outputDir. skippedPath is left out, as it's the manifest itself if it's written into the outputDir.
+func (engine *Engine) getManifest(skippedPath string) (BuildManifest, error) {
+	manifest := BuildManifest{Version: engine.Version, Time: time.Now().Format(time.RFC3339), Files: make(map[string]ManifestFile)}
+	err := filepath.Walk(engine.OutputDir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || (skippedPath != "" && cleanPath(filePath) == cleanPath(skippedPath)) {
+			return nil
+		}
+		content, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		relativePath, err := filepath.Rel(engine.OutputDir, filePath)
+		if err != nil {
+			return err
+		}
+		pagePath := "/" + filepath.ToSlash(relativePath)
+		hash := sha256.Sum256(content)
+		file := ManifestFile{Hash: hex.EncodeToString(hash[:])}
+		if engine.isHtmlOutput(filePath) {
+			file.Title = getSearchIndexEntry(pagePath, content, nil).Title
+			targets := make(map[string]bool)
+			for _, link := range getLinks(content) {
+				if target, ok := engine.getInternalLinkTarget(pagePath, link); ok {
+					targets[target] = true
+				}
+			}
+			if len(targets) > 0 {
+				file.Links = getSortedKeys(targets)
+			}
+		}
+		manifest.Files[pagePath] = file
+		return nil
+	})
+	return manifest, err
+}
+
+// writeManifest writes the BuildManifest of the outputDir to the manifest file, if it's set.
+func (engine *Engine) writeManifest() error {
+	if engine.Manifest == "" {
+		return nil
+	}
+	manifest, err := engine.getManifest(engine.Manifest)
+	if err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	engine.logDebug("Writing the build manifest '" + engine.Manifest + "' ...")
+	return ioutil.WriteFile(engine.Manifest, append(content, '\n'), engine.fileMode)
+}
+
+// loadManifest reads the BuildManifest at filePath.
+func loadManifest(filePath string) (BuildManifest, error) {
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return BuildManifest{}, errors.New("Could not read the build manifest '" + filePath + "': " + err.Error())
+	}
+	var manifest BuildManifest
+	if err := json.Unmarshal(content, &manifest); err != nil || manifest.Files == nil {
+		return BuildManifest{}, errors.New("The file '" + filePath + "' is no build manifest, as written by '--manifest'.")
+	}
+	return manifest, nil
+}
+
+// Diff compares the current contents of the outputDir against the build manifest at oldManifestPath, as written by a previous build with the manifest option.
+// It reports the added, removed and changed files, and the internal links which disappeared from the pages existing in both builds, f.e. for the pull requests of content repositories.
+func (engine *Engine) Diff(oldManifestPath string) (OutputDiff, error) {
+	old, err := loadManifest(oldManifestPath)
+	if err != nil {
+		return OutputDiff{}, err
+	}
+	if err := engine.validate(); err != nil {
+		return OutputDiff{}, err
+	}
+	current, err := engine.getManifest(engine.Manifest)
+	if err != nil {
+		return OutputDiff{}, err
+	}
+
+	diff := OutputDiff{Added: []DiffFile{}, Removed: []DiffFile{}, Changed: []DiffFile{}, RemovedLinks: []RemovedLink{}}
+	for filePath, file := range current.Files {
+		oldFile, ok := old.Files[filePath]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, DiffFile{Path: filePath, Title: file.Title})
+		case oldFile.Hash != file.Hash:
+			diff.Changed = append(diff.Changed, DiffFile{Path: filePath, Title: file.Title})
+		}
+		if !ok {
+			continue
+		}
+		links := make(map[string]bool)
+		for _, link := range file.Links {
+			links[link] = true
+		}
+		for _, link := range oldFile.Links {
+			if !links[link] {
+				diff.RemovedLinks = append(diff.RemovedLinks, RemovedLink{Page: filePath, Target: link})
+			}
+		}
+	}
+	for filePath, oldFile := range old.Files {
+		if _, ok := current.Files[filePath]; !ok {
+			diff.Removed = append(diff.Removed, DiffFile{Path: filePath, Title: oldFile.Title})
+		}
+	}
+	for _, files := range [][]DiffFile{diff.Added, diff.Removed, diff.Changed} {
+		sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	}
+	sort.Slice(diff.RemovedLinks, func(i, j int) bool {
+		if diff.RemovedLinks[i].Page != diff.RemovedLinks[j].Page {
+			return diff.RemovedLinks[i].Page < diff.RemovedLinks[j].Page
+		}
+		return diff.RemovedLinks[i].Target < diff.RemovedLinks[j].Target
+	})
+	return diff, nil
+}
@@ -0,0 +1,142 @@
+package temingo
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// previewDir is the folder in the cacheDir the site is rendered to by Preview, instead of the outputDir.
+var previewDir = path.Join(cacheDir, "dry-run")
+
+// OutputChange is a file of the outputDir that a build would create, change or delete, see Preview.
+type OutputChange struct {
+	Path   string `json:"path"`           // path of the file in the outputDir
+	Change string `json:"change"`         // 'created', 'changed' or 'deleted'
+	Diff   string `json:"diff,omitempty"` // unified diff of changed text files, if requested
+}
+
+// Preview renders the whole site without touching the outputDir and returns which of its files would be created, changed or deleted, sorted by path.
+// The site is rendered to a folder in the cacheDir, as commands like the sassCommand need actual files, which is deleted again afterwards.
+// With withDiff, each change of a text file contains the unified diff between the current and the new content.
+func (engine *Engine) Preview(withDiff bool) ([]OutputChange, error) {
+	if err := engine.validate(); err != nil {
+		return nil, err
+	}
+	release, err := engine.acquireLock()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	outputDir := engine.OutputDir
+	if err := os.RemoveAll(previewDir); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(previewDir, engine.dirMode); err != nil {
+		return nil, err
+	}
+	engine.OutputDir, engine.actualOutputDir = previewDir, outputDir
+	defer func() {
+		engine.OutputDir, engine.actualOutputDir = outputDir, ""
+		os.RemoveAll(previewDir)
+	}()
+
+	if err := engine.renderOutput(); err != nil {
+		return nil, err
+	}
+
+	previewed, err := listOutputFiles(previewDir)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := listOutputFiles(outputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []OutputChange{}
+	for relativePath := range previewed {
+		filePath := path.Join(outputDir, relativePath)
+		if !existing[relativePath] {
+			changes = append(changes, OutputChange{Path: filePath, Change: "created"})
+			continue
+		}
+		oldContent, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+		newContent, err := ioutil.ReadFile(path.Join(previewDir, relativePath))
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(oldContent, newContent) {
+			continue
+		}
+		change := OutputChange{Path: filePath, Change: "changed"}
+		if withDiff {
+			change.Diff, err = diffOutputFile(filePath, oldContent, newContent)
+			if err != nil {
+				return nil, err
+			}
+		}
+		changes = append(changes, change)
+	}
+	for relativePath := range existing {
+		if !previewed[relativePath] {
+			changes = append(changes, OutputChange{Path: path.Join(outputDir, relativePath), Change: "deleted"})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes, nil
+}
+
+// listOutputFiles returns the paths of all files in dir, relative to it. A missing dir has no files.
+func listOutputFiles(dir string) (map[string]bool, error) {
+	files := make(map[string]bool)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return files, nil
+	}
+	err := filepath.Walk(dir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		relativePath, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(relativePath)] = true
+		return nil
+	})
+	return files, err
+}
+
+// diffOutputFile returns the unified diff between the old and the new content of the output file at filePath. Binary files only state that they differ.
+func diffOutputFile(filePath string, oldContent []byte, newContent []byte) (string, error) {
+	if !isText(oldContent) || !isText(newContent) {
+		return "Binary file " + filePath + " differs\n", nil
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(oldContent)),
+		B:        difflib.SplitLines(string(newContent)),
+		FromFile: filePath,
+		ToFile:   filePath + " (dry run)",
+		Context:  3,
+	})
+}
+
+func isText(content []byte) bool {
+	return utf8.Valid(content) && !bytes.ContainsRune(content, 0)
+}
@@ -0,0 +1,544 @@
+package temingo
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cacheDir is the folder the work of previous builds is kept in, f.e. the fetched webmentions, compiled stylesheets and processed images. See CleanCache.
+const cacheDir = ".temingo-cache"
+
+var (
+	pathValidator = "^[a-z0-9-_./]+$"
+	rexp          = regexp.MustCompile(pathValidator)
+)
+
+// Options configures an Engine. Use DefaultOptions to get the defaults of the cli.
+// The yaml keys are the names of the corresponding cli flags, see LoadConfigFile.
+type Options struct {
+	ValuesFilePaths         []string               `yaml:"valuesfile"`              // paths of the values files, merged in the given order
+	Values                  map[string]interface{} `yaml:"values"`                  // additional values, which override the ones of the values files
+	ValuesMerge             map[string]string      `yaml:"valuesMerge"`             // strategies the values at dotted key paths are merged with across the values files, see ValuesMergeStrategies
+	InputDir                string                 `yaml:"inputDir"`                // folder containing the templates
+	PartialsDir             string                 `yaml:"partialsDir"`             // folder containing the partials
+	OutputDir               string                 `yaml:"outputDir"`               // destination of the rendered templates
+	StaticDir               string                 `yaml:"staticDir"`               // folder containing files that are copied as they are
+	TemplateExtension       string                 `yaml:"templateExtension"`       // extension of the template files
+	SingleTemplateExtension string                 `yaml:"singleTemplateExtension"` // extension of the single-view template files
+	PartialExtension        string                 `yaml:"partialExtension"`        // extension of the partial files
+	MarkdownExtension       string                 `yaml:"markdownExtension"`       // extension of the markdown content files, f.e. '.md', markdown content is disabled if empty
+	ItemIndexFiles          []string               `yaml:"itemIndexFiles"`          // file names which make a folder an item, the first existing one contains its values
+	ItemFiles               []string               `yaml:"itemFiles"`               // gitignore patterns of yaml, json, toml or markdown files which are items on their own, without a folder
+	MarkdownLayout          string                 `yaml:"markdownLayout"`          // name of the partial markdown content files are rendered with, if they don't specify a layout
+	SummaryWords            int                    `yaml:"summaryWords"`            // number of words of the summaries of content without '<!--more-->'
+	ReadingSpeed            int                    `yaml:"readingSpeed"`            // words per minute the reading time of content is calculated with
+	HtmlExtensions          []string               `yaml:"htmlExtensions"`          // output extensions that are rendered with contextual html escaping
+	TemingoignoreFilePath   string                 `yaml:"temingoignore"`           // path of the ignore file
+	CopyExclusions          []string               `yaml:"copyExclusions"`          // gitignore patterns of files in the inputDir which aren't copied to the outputDir, but still available for templating
+	TemplateExclusions      []string               `yaml:"templateExclusions"`      // gitignore patterns of templates and markdown files which aren't rendered
+	WatchExclusions         []string               `yaml:"watchExclusions"`         // gitignore patterns of files whose changes don't trigger rebuilds while watching
+	BaseURL                 string                 `yaml:"baseURL"`                 // absolute url of the site, used wherever absolute urls are required
+	WebmentionEndpoint      string                 `yaml:"webmentionEndpoint"`      // announced webmention endpoint
+	PingbackEndpoint        string                 `yaml:"pingbackEndpoint"`        // announced pingback endpoint
+	WebmentionsAPI          string                 `yaml:"webmentionsAPI"`          // url received webmentions are fetched from, '{target}' is replaced with the page url
+	Offline                 bool                   `yaml:"offline"`                 // whether remote data and webmentions are only read from the cacheDir instead of fetched
+	PdfPatterns             []string               `yaml:"pdf"`                     // patterns of rendered files that are additionally exported to PDF
+	PdfCommand              string                 `yaml:"pdfCommand"`              // command used for the PDF export, '{input}' and '{output}' are replaced with the file paths
+	SassCommand             string                 `yaml:"sassCommand"`             // command used to compile Sass stylesheets of the staticDir to css, '{input}' and '{output}' are replaced with the file paths
+	FingerprintPatterns     []string               `yaml:"fingerprint"`             // patterns of static files that are additionally written with a hash of their content in the file name
+	AssetBundles            map[string]AssetBundle `yaml:"assetBundles"`            // css and js files concatenated from static files, by their path in the outputDir
+	ImageQuality            int                    `yaml:"imageQuality"`            // quality of jpeg images written by the image template functions, from 1 to 100
+	BuildDrafts             bool                   `yaml:"buildDrafts"`             // whether items, pages and templates with 'draft: true' are built
+	BuildFuture             bool                   `yaml:"buildFuture"`             // whether items, pages and templates with a 'date' in the future are built
+	FileMode                string                 `yaml:"fileMode"`                // permissions of written files in octal notation, reduced by the umask
+	DirMode                 string                 `yaml:"dirMode"`                 // permissions of created folders in octal notation, reduced by the umask
+	ProfileTemplates        bool                   `yaml:"profileTemplates"`        // whether the time spent per template, included partial and list is logged after rendering
+	Profile                 bool                   `yaml:"profile"`                 // whether the time spent per phase of a build is logged after it, together with the template profile
+	ProfileDir              string                 `yaml:"profileDir"`              // folder the pprof cpu and heap profiles of a build are written to, if set
+	Minify                  bool                   `yaml:"minify"`                  // whether rendered html, css and js outputs are minified
+	MinifyStatic            bool                   `yaml:"minifyStatic"`            // whether css and js files copied from the staticDir are minified
+	FlatContext             bool                   `yaml:"flatContext"`             // whether templates get the values at the top-level instead of namespaced
+	SlugCollisions          string                 `yaml:"slugCollisions"`          // how generated pages with the same slug are handled, either 'fail' or 'suffix'
+	AliasRedirects          string                 `yaml:"aliasRedirects"`          // how the 'aliases' of pages redirect to them, either 'page' for redirect pages or 'server' for redirects of the server configuration
+	Poll                    bool                   `yaml:"poll"`                    // whether watched files are checked for changes every watchInterval, instead of being notified about them by the operating system
+	WatchInterval           time.Duration          `yaml:"watchInterval"`           // interval in which watched files are checked for changes when polling
+	WatchDebounce           time.Duration          `yaml:"watchDebounce"`           // time without further changes after which changed files are rebuilt while watching
+	Notify                  bool                   `yaml:"notify"`                  // whether a desktop notification is shown after each build while watching
+	NotifyWebhook           string                 `yaml:"notifyWebhook"`           // url a json summary is posted to after each build while watching
+	WatchLog                string                 `yaml:"watchLog"`                // path of a file json lines about each change and build are appended to while watching
+	Concurrency             int                    `yaml:"concurrency"`             // number of outputs rendered at the same time, 0 for GOMAXPROCS
+	Sitemap                 bool                   `yaml:"sitemap"`                 // whether a 'sitemap.xml' of the rendered html pages is generated
+	SearchIndex             bool                   `yaml:"searchIndex"`             // whether a 'search-index.json' of the rendered html pages is generated for client-side search
+	ProtectPassword         string                 `yaml:"protectPassword"`         // password the html outputs of pages with 'protected: true' are encrypted with
+	ExecFunctions           map[string]string      `yaml:"execFunctions"`           // additional template functions by name, implemented by commands which get the arguments as json on stdin and print the result
+	PreBuild                []string               `yaml:"preBuild"`                // shell commands run before each build, a failing one aborts the build
+	PostBuild               []string               `yaml:"postBuild"`               // shell commands run after each successful build, a failing one fails the build
+	ReviewEvery             string                 `yaml:"reviewEvery"`             // interval after which content with 'lastReviewed' is due for review, unless it has its own 'reviewEvery'
+	CheckLinks              bool                   `yaml:"checkLinks"`              // whether links of the rendered html pages to files which don't exist in the outputDir are reported as warnings
+	FailOnBrokenLinks       bool                   `yaml:"failOnBrokenLinks"`       // whether links of the rendered html pages to files which don't exist in the outputDir fail the build
+	Manifest                string                 `yaml:"manifest"`                // path of a file the build manifest is written to after each build, which later builds can be compared against with Diff
+	OutputNames             []string               `yaml:"outputNames"`             // rules naming the outputs of pages without permalink, each a gitignore pattern of the sources and a permalink separated by '=', the first matching one applies
+	MaxOutputSize           string                 `yaml:"maxOutputSize"`           // maximum size of the output of a template or a single include, like '100MB', '0' disables it
+	MaxRenderTime           time.Duration          `yaml:"maxRenderTime"`           // maximum time rendering a single template may take, 0 disables it
+	MaxIncludeDepth         int                    `yaml:"maxIncludeDepth"`         // maximum number of nested 'include' calls
+	EnvAllowlist            []string               `yaml:"envAllowlist"`            // patterns of the environment variables available via 'env' and expanded as '${NAME}' in values files, f.e. 'API_*'
+	ValuesSchema            string                 `yaml:"valuesSchema"`            // path of a schema file the merged values are validated against before rendering
+	Taxonomies              []string               `yaml:"taxonomies"`              // values of pages and items whose terms get listing pages via a template with 'taxonomy' in its front matter
+	Languages               []string               `yaml:"languages"`               // languages the site is rendered in, the first one is the default and rendered to the outputDir itself
+	TranslationsDir         string                 `yaml:"translationsDir"`         // folder containing the translations of the 'T' function, one '<language>.yaml' per language
+	DataDir                 string                 `yaml:"dataDir"`                 // folder containing yaml, json and toml files which are available as '.Data'
+	LintRules               map[string]string      `yaml:"lintRules"`               // severities of the lint rules which differ from their default, either 'error', 'warning' or 'off', see LintRules
+	SchemaVersion           int                    `yaml:"schemaVersion"`           // version of the options schema the project config file is written for, see ConfigSchemaVersion
+	Future                  bool                   `yaml:"future"`                  // whether deprecated flags, options and functions are handled as if they were already removed
+	Version                 string                 `yaml:"-"`                       // version of temingo, available as '.Build.Version'
+	Environment             string                 `yaml:"environment"`             // environment the site is built for, f.e. 'production', available as '.Env' and '.Build.Environment'. Selects the overlay values files like 'values.production.yaml'
+	NoLock                  bool                   `yaml:"noLock"`                  // whether the lock file, which prevents concurrent builds of the project, is skipped
+	Quiet                   bool                   `yaml:"quiet"`                   // whether only warnings and errors are logged
+	Verbose                 bool                   `yaml:"verbose"`                 // whether debug information is logged, like with debug
+	LogFormat               string                 `yaml:"logFormat"`               // format of the log messages, either 'text' or 'json'
+	LogTimestamps           bool                   `yaml:"logTimestamps"`           // whether the log messages contain the time they were logged at
+	RedactedKeys            []string               `yaml:"redactedKeys"`            // patterns of keys whose values are redacted when values are logged, f.e. '*token*'
+	Debug                   bool                   `yaml:"debug"`                   // whether debug information is logged, deprecated in favor of verbose
+
+	configFilePath string // the project config file the options were loaded from, set by LoadConfigFile
+}
+
+// DefaultOptions returns the Options with the same defaults as the cli.
+func DefaultOptions() Options {
+	return Options{
+		ValuesFilePaths:         []string{"values.yaml"},
+		InputDir:                ".",
+		PartialsDir:             "partials",
+		OutputDir:               "output",
+		StaticDir:               "static",
+		TemplateExtension:       ".template",
+		SingleTemplateExtension: ".single.template",
+		PartialExtension:        ".partial",
+		MarkdownLayout:          "layouts/default",
+		ItemIndexFiles:          []string{"index.yaml"},
+		HtmlExtensions:          []string{".html", ".htm", ".xhtml"},
+		TemingoignoreFilePath:   ".temingoignore",
+		PdfCommand:              "wkhtmltopdf --quiet {input} {output}",
+		SassCommand:             "sass --no-source-map {input} {output}",
+		ImageQuality:            85,
+		MaxOutputSize:           "100MB",
+		MaxIncludeDepth:         100,
+		SummaryWords:            70,
+		ReadingSpeed:            200,
+		SlugCollisions:          "fail",
+		AliasRedirects:          "page",
+		Sitemap:                 true,
+		Taxonomies:              []string{"tags", "categories"},
+		TranslationsDir:         "i18n",
+		DataDir:                 "data",
+		FileMode:                "0644",
+		DirMode:                 "0755",
+		WatchInterval:           time.Millisecond * 100,
+		WatchDebounce:           time.Millisecond * 200,
+		Environment:             "development",
+		LogFormat:               "text",
+		LogTimestamps:           true,
+		RedactedKeys:            []string{"*password*", "*token*", "*secret*"},
+	}
+}
+
+// Engine renders a temingo project. Create it with New.
+type Engine struct {
+	Options
+
+	listListObjects     map[string]map[string]interface{}
+	partials            [][]string                        // the partials of the current build, which shortcodes are rendered with
+	sitePages           []interface{}                     // all pages and items of the site, collected before templating starts
+	sectionValuesCache  map[string]map[string]interface{} // cascaded section values per folder, reset for every build
+	fetchedWebmentions  map[string][]interface{}          // webmentions fetched during this run, per target url
+	fetchedData         map[string][]byte                 // responses of 'getJSON' and 'getYAML' fetched or read from the cache during this run, per url
+	execFunctionResults map[string][]byte                 // outputs of the execFunctions during the current build, per name and arguments
+	server              serverConfig                      // redirects and headers for server configuration files, read for every build
+	renderedFiles       map[string]bool                   // files rendered by an incremental rebuild, nil for full builds
+	changedFiles        map[string]bool                   // the changed files an incremental rebuild was triggered by, nil for full builds
+	outputSources       map[string]string                 // the template each output file was rendered from during the current build
+	aliases             []aliasRedirect                   // the aliases of the pages of the current language, collected together with the pages
+	templateCaches      map[string]*templateCache         // the templates whose outputs are cached, by name, registered for every build
+	templateEngines     map[string]bool                   // whether the templates declaring an 'engine' are rendered by html/template, by name, registered for every build
+	buildInfo           map[string]interface{}            // metadata of the current build
+	temingoignoreLines  []string                          // the lines of the ignore file, read for every build
+	buildFailed         bool                              // whether the last build while watching failed, so the next one is a full rebuild
+	buildCanceled       int32                             // set atomically once the current build is canceled while watching, as files changed in the meantime
+	buildTrigger        []string                          // the changed paths the current build while watching was triggered by
+	previousValues      map[string]interface{}            // the merged values of the previous build, to show how they changed while watching
+	siteBaseURL         string                            // the baseURL option, or the 'baseURL' of the values if it isn't set
+	sitemapURLs         map[string]sitemapURL             // the entries of the last written sitemap per output file, so incremental rebuilds only update the rerendered ones
+	searchIndexEntries  map[string]searchIndexEntry       // the entries of the last written search index per output file, so incremental rebuilds only update the rerendered ones
+	profile             map[string]*profileEntry          // calls and time spent per template, included partial and list while profiling, reset for every build
+	phases              []*phaseTiming                    // time spent per phase of the build while profiling, in the order they ran, reset for every build
+	assets              map[string]string                 // the fingerprinted path of each static file matching the fingerprintPatterns and each fingerprinted asset bundle, written for every build
+	bundledFiles        map[string]bool                   // the static files contained in fingerprinted asset bundles, by their path in the outputDir
+	processedImages     map[string]bool                   // the site-relative paths of the images written by the image template functions during the current build
+	imageSources        map[string]bool                   // the originals of the processed images, so changing them rerenders everything while watching
+	renderedSources     map[string][]string               // the source files each output file was rendered from, kept across incremental rebuilds for the sitemap
+	fileMode            os.FileMode                       // the parsed fileMode option
+	dirMode             os.FileMode                       // the parsed dirMode option
+	language            string                            // the language currently rendered, empty without languages
+	translations        []map[string]interface{}          // the translations of the current language, followed by the ones of the default language
+	data                map[string]interface{}            // the contents of the files in the dataDir, read for every build
+	taxonomies          map[string][]interface{}          // the terms of each taxonomy, collected together with the site pages
+	summary             *buildSummary                     // what the current build did, reset for every build
+	warnedDeprecations  map[string]bool                   // the ids of the deprecations already logged, so each is only logged once
+	actualOutputDir     string                            // the outputDir option while the site is rendered to the stagingDir or previewDir instead
+	pageIndexing        map[string]pageIndexing           // whether each output file rendered from a page is indexed and listed in the sitemap, kept across incremental rebuilds for the sitemap
+	lock                sync.Mutex                        // guards the state above while templates are rendered concurrently
+}
+
+// New creates an Engine for the given options.
+func New(options Options) *Engine {
+	return &Engine{
+		Options:            options,
+		listListObjects:    make(map[string]map[string]interface{}),
+		sitePages:          []interface{}{},
+		sectionValuesCache: make(map[string]map[string]interface{}),
+		fetchedWebmentions: make(map[string][]interface{}),
+		fetchedData:        make(map[string][]byte),
+		processedImages:    make(map[string]bool),
+		imageSources:       make(map[string]bool),
+		outputSources:      make(map[string]string),
+		renderedSources:    make(map[string][]string),
+		pageIndexing:       make(map[string]pageIndexing),
+		warnedDeprecations: make(map[string]bool),
+	}
+}
+
+// Render copies the static contents and renders all templates once. Only new and changed files are written to the outputDir, files that aren't part of the build anymore are deleted.
+func (engine *Engine) Render() error {
+	if err := engine.validate(); err != nil {
+		return err
+	}
+	release, err := engine.acquireLock()
+	if err != nil {
+		return err
+	}
+	defer release()
+	stopProfile, err := engine.startCPUProfile()
+	if err != nil {
+		return err
+	}
+	errs := BuildErrors{}
+	errs.add(engine.buildWithHooks(engine.rebuildOutput))
+	errs.add(stopProfile())
+	return errs.err()
+}
+
+// Watch renders once and then rerenders whenever a file in the inputDir, the partialsDir or a values file changes. It blocks until the watcher is closed.
+// Build errors are logged instead of returned, so they can be fixed while watching.
+func (engine *Engine) Watch() error {
+	if err := engine.validate(); err != nil {
+		return err
+	}
+	release, err := engine.acquireLock()
+	if err != nil {
+		return err
+	}
+	defer release()
+	engine.logBuildErrors(engine.buildWithHooks(engine.rebuildOutput))
+	return engine.watchAll()
+}
+
+// Clean deletes the contents of the outputDir.
+func (engine *Engine) Clean() error {
+	engine.OutputDir = cleanPath(engine.OutputDir)
+	if _, err := os.Stat(engine.OutputDir); os.IsNotExist(err) { // nothing to clean
+		return nil
+	}
+	if err := engine.parseModes(); err != nil {
+		return err
+	}
+	release, err := engine.acquireLock()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return engine.deleteOutput()
+}
+
+// CleanCache deletes the cacheDir, which contains f.e. the fetched webmentions.
+func (engine *Engine) CleanCache() error {
+	return os.RemoveAll(cacheDir)
+}
+
+// Init creates the folders and files of an example project, where they don't exist yet.
+// That's an 'index.html' template using a 'header' and a 'footer' partial, the values file(s) and the ignore file.
+func (engine *Engine) Init() error {
+	if err := engine.parseModes(); err != nil {
+		return err
+	}
+	for _, dir := range []string{engine.InputDir, engine.PartialsDir, engine.OutputDir, engine.StaticDir} {
+		engine.logDebug("Creating directory '" + dir + "' ...")
+		if err := os.MkdirAll(dir, engine.dirMode); err != nil {
+			return err
+		}
+	}
+	temingoignore := scaffoldTemingoignore
+	for _, valuesFilePath := range engine.ValuesFilePaths {
+		temingoignore += "/" + path.Clean(valuesFilePath) + "\n"
+	}
+	files := [][]string{
+		{path.Join(engine.InputDir, "index.html"+engine.TemplateExtension), scaffoldTemplate},
+		{path.Join(engine.PartialsDir, "header"+engine.PartialExtension), scaffoldHeaderPartial},
+		{path.Join(engine.PartialsDir, "footer"+engine.PartialExtension), scaffoldFooterPartial},
+		{engine.TemingoignoreFilePath, temingoignore},
+	}
+	for i, valuesFilePath := range engine.ValuesFilePaths {
+		content := ""
+		if i == 0 { // the example values are only needed once
+			content = scaffoldValues
+		}
+		files = append(files, []string{valuesFilePath, content})
+	}
+	for _, file := range files {
+		if _, err := os.Stat(file[0]); err == nil { // never overwrite existing files
+			continue
+		}
+		engine.logDebug("Creating file '" + file[0] + "' ...")
+		if err := ioutil.WriteFile(file[0], []byte(file[1]), engine.fileMode); err != nil {
+			return err
+		}
+	}
+	engine.logInfo("*** Created the project, render it with 'temingo build' or 'temingo serve'. ***")
+	return nil
+}
+
+// cleanPath returns the configured path with forward slashes and without redundant elements, as all paths are handled internally, f.e. 'src\\blog\\' on windows becomes 'src/blog'.
+// The os functions accept forward slashes on all platforms.
+func cleanPath(configuredPath string) string {
+	return path.Clean(filepath.ToSlash(configuredPath))
+}
+
+// validate cleans the configured paths and checks they exist.
+func (engine *Engine) validate() error {
+	for i, valuesfilePath := range engine.ValuesFilePaths { // for each path stated
+		engine.ValuesFilePaths[i] = cleanPath(valuesfilePath) // clean path
+		info, err := os.Stat(engine.ValuesFilePaths[i])
+		if os.IsNotExist(err) { // if path doesn't exist
+			return errors.New("Values file does not exist: " + engine.ValuesFilePaths[i])
+		} else if info.IsDir() { // if is not a directoy
+			return errors.New("Values file is not a file (but a directory): " + engine.ValuesFilePaths[i])
+		}
+	}
+
+	for _, dir := range []struct {
+		name string
+		path *string
+	}{
+		{"input-directory", &engine.InputDir},
+		{"partial-files-directory", &engine.PartialsDir},
+		{"output-directory", &engine.OutputDir},
+		{"static-files-directory", &engine.StaticDir},
+	} {
+		*dir.path = cleanPath(*dir.path)
+		info, err := os.Stat(*dir.path)
+		if os.IsNotExist(err) { // if path doesn't exist
+			return errors.New("Given " + dir.name + " does not exist: " + *dir.path)
+		} else if !info.IsDir() { // if is not a directory
+			return errors.New("Given " + dir.name + " is not a directory: " + *dir.path)
+		}
+	}
+
+	if engine.WatchInterval <= 0 {
+		return errors.New("The watch interval must be positive, but is " + engine.WatchInterval.String())
+	}
+	if engine.WatchDebounce < 0 {
+		return errors.New("The watch debounce must not be negative, but is " + engine.WatchDebounce.String())
+	}
+
+	if engine.Concurrency < 0 {
+		return errors.New("The concurrency must not be negative, but is " + strconv.Itoa(engine.Concurrency))
+	}
+
+	for _, fileName := range engine.ItemIndexFiles {
+		if strings.ContainsAny(fileName, "/\\") {
+			return errors.New("The item index file '" + fileName + "' must be a file name, not a path")
+		}
+		if extension := path.Ext(fileName); extension != ".yaml" && extension != ".yml" && extension != ".json" && extension != ".toml" && !engine.isMarkdownFile(fileName) {
+			return errors.New("The item index file '" + fileName + "' must be a yaml, json, toml or markdown file, markdown files require the markdownExtension")
+		}
+	}
+
+	if engine.SlugCollisions != "fail" && engine.SlugCollisions != "suffix" {
+		return errors.New("The slug collision strategy must be either 'fail' or 'suffix', but is '" + engine.SlugCollisions + "'")
+	}
+	if engine.AliasRedirects != "page" && engine.AliasRedirects != "server" {
+		return errors.New("The alias redirects must be either 'page' or 'server', but are '" + engine.AliasRedirects + "'")
+	}
+
+	if err := engine.parseModes(); err != nil {
+		return err
+	}
+
+	if engine.LogFormat != "text" && engine.LogFormat != "json" {
+		return errors.New("The log format must be either '" + strings.Join(LogFormats, "' or '") + "', but is '" + engine.LogFormat + "'")
+	}
+
+	if err := engine.validateLanguages(); err != nil {
+		return err
+	}
+
+	if err := engine.validateDeprecations(); err != nil {
+		return err
+	}
+
+	if err := engine.validateAssetBundles(); err != nil {
+		return err
+	}
+
+	if err := engine.validateValuesMerge(); err != nil {
+		return err
+	}
+
+	if err := engine.validateRedactedKeys(); err != nil {
+		return err
+	}
+
+	if err := engine.validateExecFunctions(); err != nil {
+		return err
+	}
+
+	if err := engine.validateReviewEvery(); err != nil {
+		return err
+	}
+
+	if err := engine.validateLimits(); err != nil {
+		return err
+	}
+
+	if err := engine.validateSummaries(); err != nil {
+		return err
+	}
+
+	if err := engine.validateEnvAllowlist(); err != nil {
+		return err
+	}
+
+	if _, err := engine.getOutputNameRules(); err != nil {
+		return err
+	}
+
+	if engine.ValuesSchema != "" {
+		engine.ValuesSchema = cleanPath(engine.ValuesSchema)
+		if info, err := os.Stat(engine.ValuesSchema); err != nil || info.IsDir() {
+			return errors.New("The values schema '" + engine.ValuesSchema + "' does not exist or is not a file.")
+		}
+	}
+
+	if engine.ImageQuality < 1 || engine.ImageQuality > 100 {
+		return errors.New("The image quality must be between 1 and 100, but is " + strconv.Itoa(engine.ImageQuality))
+	}
+	engine.TranslationsDir = cleanPath(engine.TranslationsDir)
+	engine.DataDir = cleanPath(engine.DataDir)
+	engine.TemingoignoreFilePath = cleanPath(engine.TemingoignoreFilePath)
+
+	engine.logDebug("valuesFilePaths:", engine.ValuesFilePaths)
+	engine.logDebug("valuesMerge:", engine.ValuesMerge)
+	engine.logDebug("inputDir:", engine.InputDir)
+	engine.logDebug("partialsDir:", engine.PartialsDir)
+	engine.logDebug("outputDir:", engine.OutputDir)
+	engine.logDebug("templateExtension:", engine.TemplateExtension)
+	engine.logDebug("singleTemplateExtension:", engine.SingleTemplateExtension)
+	engine.logDebug("partialExtension:", engine.PartialExtension)
+	engine.logDebug("markdownExtension:", engine.MarkdownExtension)
+	engine.logDebug("markdownLayout:", engine.MarkdownLayout)
+	engine.logDebug("summaryWords:", engine.SummaryWords)
+	engine.logDebug("readingSpeed:", engine.ReadingSpeed)
+	engine.logDebug("itemIndexFiles:", engine.ItemIndexFiles)
+	engine.logDebug("itemFiles:", engine.ItemFiles)
+	engine.logDebug("htmlExtensions:", engine.HtmlExtensions)
+	engine.logDebug("temingoignoreFilePath:", engine.TemingoignoreFilePath)
+	engine.logDebug("copyExclusions:", engine.CopyExclusions)
+	engine.logDebug("templateExclusions:", engine.TemplateExclusions)
+	engine.logDebug("watchExclusions:", engine.WatchExclusions)
+	engine.logDebug("staticDir:", engine.StaticDir)
+	engine.logDebug("baseURL:", engine.BaseURL)
+	engine.logDebug("webmentionEndpoint:", engine.WebmentionEndpoint)
+	engine.logDebug("pingbackEndpoint:", engine.PingbackEndpoint)
+	engine.logDebug("webmentionsAPI set:", engine.WebmentionsAPI != "") // the api url contains the token of the account
+	engine.logDebug("offline:", engine.Offline)
+	engine.logDebug("pdfPatterns:", engine.PdfPatterns)
+	engine.logDebug("pdfCommand:", engine.PdfCommand)
+	engine.logDebug("sassCommand:", engine.SassCommand)
+	engine.logDebug("fingerprintPatterns:", engine.FingerprintPatterns)
+	engine.logDebug("assetBundles:", engine.AssetBundles)
+	engine.logDebug("imageQuality:", engine.ImageQuality)
+	engine.logDebug("buildDrafts:", engine.BuildDrafts)
+	engine.logDebug("buildFuture:", engine.BuildFuture)
+	engine.logDebug("fileMode:", engine.fileMode)
+	engine.logDebug("dirMode:", engine.dirMode)
+	engine.logDebug("profileTemplates:", engine.ProfileTemplates)
+	engine.logDebug("profile:", engine.Profile)
+	engine.logDebug("profileDir:", engine.ProfileDir)
+	engine.logDebug("minify:", engine.Minify)
+	engine.logDebug("minifyStatic:", engine.MinifyStatic)
+	engine.logDebug("flatContext:", engine.FlatContext)
+	engine.logDebug("sitemap:", engine.Sitemap)
+	engine.logDebug("searchIndex:", engine.SearchIndex)
+	engine.logDebug("protectPassword set:", engine.ProtectPassword != "")
+	engine.logDebug("execFunctions:", engine.ExecFunctions)
+	engine.logDebug("preBuild:", engine.PreBuild)
+	engine.logDebug("postBuild:", engine.PostBuild)
+	engine.logDebug("reviewEvery:", engine.ReviewEvery)
+	engine.logDebug("checkLinks:", engine.CheckLinks)
+	engine.logDebug("failOnBrokenLinks:", engine.FailOnBrokenLinks)
+	engine.logDebug("manifest:", engine.Manifest)
+	engine.logDebug("outputNames:", engine.OutputNames)
+	engine.logDebug("maxOutputSize:", engine.MaxOutputSize)
+	engine.logDebug("maxRenderTime:", engine.MaxRenderTime)
+	engine.logDebug("maxIncludeDepth:", engine.MaxIncludeDepth)
+	engine.logDebug("envAllowlist:", engine.EnvAllowlist)
+	engine.logDebug("valuesSchema:", engine.ValuesSchema)
+	engine.logDebug("taxonomies:", engine.Taxonomies)
+	engine.logDebug("languages:", engine.Languages)
+	engine.logDebug("translationsDir:", engine.TranslationsDir)
+	engine.logDebug("dataDir:", engine.DataDir)
+	engine.logDebug("lintRules:", engine.LintRules)
+	engine.logDebug("schemaVersion:", engine.SchemaVersion)
+	engine.logDebug("future:", engine.Future)
+	engine.logDebug("poll:", engine.Poll)
+	engine.logDebug("aliasRedirects:", engine.AliasRedirects)
+	engine.logDebug("watchInterval:", engine.WatchInterval)
+	engine.logDebug("watchDebounce:", engine.WatchDebounce)
+	engine.logDebug("notify:", engine.Notify)
+	engine.logDebug("notifyWebhook set:", engine.NotifyWebhook != "") // webhook urls often contain credentials
+	engine.logDebug("watchLog:", engine.WatchLog)
+	engine.logDebug("version:", engine.Version)
+	engine.logDebug("environment:", engine.Environment)
+	engine.logDebug("noLock:", engine.NoLock)
+	engine.logDebug("logFormat:", engine.LogFormat)
+	engine.logDebug("logTimestamps:", engine.LogTimestamps)
+	engine.logDebug("redactedKeys:", engine.RedactedKeys)
+
+	return nil
+}
+
+// parseModes parses the fileMode and dirMode options, which are permissions in octal notation like '0644'.
+func (engine *Engine) parseModes() error {
+	for _, mode := range []struct {
+		name   string
+		value  string
+		parsed *os.FileMode
+	}{
+		{"file mode", engine.FileMode, &engine.fileMode},
+		{"directory mode", engine.DirMode, &engine.dirMode},
+	} {
+		parsed, err := strconv.ParseUint(mode.value, 8, 32)
+		if err != nil || parsed > 0777 {
+			return errors.New("The " + mode.name + " must be permissions in octal notation like '0644', but is '" + mode.value + "'")
+		}
+		*mode.parsed = os.FileMode(parsed)
+	}
+	return nil
+}
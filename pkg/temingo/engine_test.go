@@ -0,0 +1,137 @@
+package temingo
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// createTestProject writes the files of a project into a temporary folder, together with its output-dir and static-dir, and changes into it until the test finished.
+func createTestProject(t *testing.T, files map[string]string) {
+	dir := t.TempDir()
+	workingDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(workingDir) })
+
+	for filePath, content := range files {
+		if err := os.MkdirAll(path.Dir(filePath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, dirPath := range []string{"output", "static"} {
+		if err := os.MkdirAll(dirPath, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestRenderWithBaseURL builds a site with a baseURL, which writes the sitemap and the search index while the engine is locked.
+func TestRenderWithBaseURL(t *testing.T) {
+	createTestProject(t, map[string]string{
+		"values.yaml":            "title: test\n",
+		".temingoignore":         "output\n",
+		"index.html.template":    "<html><head><title>home</title></head><body><a href=\"/about.html\">about</a></body></html>\n",
+		"about.html.template":    "<html><head><title>about</title></head><body>about</body></html>\n",
+		"feed.xml.template":      "<feed></feed>\n",
+		"partials/empty.partial": "",
+	})
+
+	options := DefaultOptions()
+	options.BaseURL = "https://example.com"
+	options.SearchIndex = true
+	engine := New(options)
+
+	done := make(chan error, 1)
+	go func() { done <- engine.Render() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(30 * time.Second):
+		t.Fatal("rendering a site with a baseURL didn't finish")
+	}
+
+	sitemap, err := ioutil.ReadFile(path.Join("output", sitemapFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, url := range []string{"https://example.com/", "https://example.com/about.html"} {
+		if !strings.Contains(string(sitemap), "<loc>"+url+"</loc>") {
+			t.Errorf("the sitemap doesn't contain '%s':\n%s", url, sitemap)
+		}
+	}
+	if strings.Contains(string(sitemap), "feed.xml") {
+		t.Errorf("the sitemap contains the non-html output 'feed.xml':\n%s", sitemap)
+	}
+	if _, err := os.Stat(path.Join("output", searchIndexFileName)); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestRenderKeepsOutputOnFailure breaks a template of a built site, so the next build fails. The output-dir has to stay as it was, as builds are rendered to the stagingDir first.
+func TestRenderKeepsOutputOnFailure(t *testing.T) {
+	createTestProject(t, map[string]string{
+		"values.yaml":            "title: test\n",
+		".temingoignore":         "output\n",
+		"index.html.template":    "<html><body>{{ .Values.title }}</body></html>\n",
+		"about.html.template":    "<html><body>about</body></html>\n",
+		"partials/empty.partial": "",
+	})
+	if err := New(DefaultOptions()).Render(); err != nil {
+		t.Fatal(err)
+	}
+	built := readOutputFiles(t)
+
+	if err := ioutil.WriteFile("about.html.template", []byte("<html><body>{{ .Values.title </body></html>\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile("index.html.template", []byte("<html><body>changed</body></html>\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := New(DefaultOptions()).Render(); err == nil {
+		t.Fatal("rendering a broken template didn't fail")
+	}
+
+	current := readOutputFiles(t)
+	if len(current) != len(built) {
+		t.Errorf("the output-dir contains %d files after the failed build instead of %d", len(current), len(built))
+	}
+	for filePath, content := range built {
+		if current[filePath] != content {
+			t.Errorf("'%s' changed from %q to %q by the failed build", filePath, content, current[filePath])
+		}
+	}
+}
+
+// readOutputFiles returns the contents of the files in the output-dir by their path.
+func readOutputFiles(t *testing.T) map[string]string {
+	files := make(map[string]string)
+	err := filepath.Walk("output", func(filePath string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		content, err := ioutil.ReadFile(filePath)
+		files[filePath] = string(content)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("the output-dir is empty")
+	}
+	return files
+}
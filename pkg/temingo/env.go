@@ -0,0 +1,101 @@
+package temingo
+
+import (
+	"errors"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var envReferenceRegexp = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`) // '${NAME}' or '${NAME:-default}'
+
+// isEnvAllowed returns whether the environment variable name matches one of the envAllowlist patterns, f.e. 'API_*'.
+func (engine *Engine) isEnvAllowed(name string) bool {
+	for _, pattern := range engine.EnvAllowlist {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// validateEnvAllowlist checks the patterns of the envAllowlist are valid.
+func (engine *Engine) validateEnvAllowlist() error {
+	for _, pattern := range engine.EnvAllowlist {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return errors.New("The environment variable pattern '" + pattern + "' is invalid: " + err.Error())
+		}
+	}
+	return nil
+}
+
+// getEnv is the 'env' template function, which returns the value of an environment variable in the envAllowlist, or an empty string if it isn't set.
+// It replaces the one of sprig, which gives templates access to all environment variables.
+func (engine *Engine) getEnv(name string) (string, error) {
+	if !engine.isEnvAllowed(name) {
+		return "", errors.New("The environment variable '" + name + "' is not in the envAllowlist.")
+	}
+	return os.Getenv(name), nil
+}
+
+// expandEnv is the 'expandenv' template function, which replaces '$NAME' and '${NAME}' in s with the environment variables in the envAllowlist.
+func (engine *Engine) expandEnv(s string) (string, error) {
+	var expandErr error
+	expanded := os.Expand(s, func(name string) string {
+		value, err := engine.getEnv(name)
+		if expandErr == nil {
+			expandErr = err
+		}
+		return value
+	})
+	return expanded, expandErr
+}
+
+// expandValuesEnv replaces '${NAME}' in the strings of values with the environment variable NAME, if it's in the envAllowlist. '${NAME:-default}' is replaced with default if NAME isn't set.
+// References to variables which aren't allowed are kept as they are and logged as warning, so strings like scripts can still contain them. filePath is the values file, used in messages.
+// Without envAllowlist, values are never expanded.
+func (engine *Engine) expandValuesEnv(filePath string, values interface{}) (interface{}, error) {
+	if len(engine.EnvAllowlist) == 0 {
+		return values, nil
+	}
+	switch typed := values.(type) {
+	case map[string]interface{}:
+		for key, value := range typed {
+			expanded, err := engine.expandValuesEnv(filePath, value)
+			if err != nil {
+				return nil, err
+			}
+			typed[key] = expanded
+		}
+	case []interface{}:
+		for i, value := range typed {
+			expanded, err := engine.expandValuesEnv(filePath, value)
+			if err != nil {
+				return nil, err
+			}
+			typed[i] = expanded
+		}
+	case string:
+		var expandErr error
+		expanded := envReferenceRegexp.ReplaceAllStringFunc(typed, func(reference string) string {
+			match := envReferenceRegexp.FindStringSubmatch(reference)
+			if !engine.isEnvAllowed(match[1]) {
+				engine.logWarn("'" + filePath + "' references the environment variable '" + match[1] + "', which is not in the envAllowlist, so it's kept as it is.")
+				return reference
+			}
+			if value, ok := os.LookupEnv(match[1]); ok {
+				return value
+			}
+			if strings.Contains(reference, ":-") {
+				return match[2]
+			}
+			if expandErr == nil {
+				expandErr = errors.New("'" + filePath + "' references the environment variable '" + match[1] + "', which is not set. Set it or add a default like '${" + match[1] + ":-default}'.")
+			}
+			return reference
+		})
+		return expanded, expandErr
+	}
+	return values, nil
+}
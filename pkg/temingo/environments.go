@@ -0,0 +1,27 @@
+package temingo
+
+import (
+	"path"
+	"strings"
+)
+
+// getEnvironmentValuesFilePath returns the path of the values of the environment which override the ones of valuesFilePath, f.e. 'values.production.yaml' for 'values.yaml'.
+func (engine *Engine) getEnvironmentValuesFilePath(valuesFilePath string) string {
+	extension := path.Ext(valuesFilePath)
+	return strings.TrimSuffix(valuesFilePath, extension) + "." + engine.Environment + extension
+}
+
+// getValuesOverlayPattern returns the pattern matching the files overriding the values of valuesFilePath, the ones of the environments and the languages, f.e. 'values.*.yaml' for 'values.yaml'.
+func getValuesOverlayPattern(valuesFilePath string) string {
+	extension := path.Ext(valuesFilePath)
+	return strings.TrimSuffix(path.Clean(valuesFilePath), extension) + ".*" + extension
+}
+
+// getEnvironmentExclusions returns the values files of all environments, which are no content. As the names of the other environments aren't known, these are all overlays of the values files.
+func (engine *Engine) getEnvironmentExclusions() []string {
+	exclusions := []string{}
+	for _, valuesFilePath := range engine.ValuesFilePaths {
+		exclusions = append(exclusions, "/"+getValuesOverlayPattern(valuesFilePath))
+	}
+	return exclusions
+}
@@ -0,0 +1,334 @@
+package temingo
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"html"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	htmlparser "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const epubConfigFileName = "epub.yaml"
+
+var (
+	bodyRexp = regexp.MustCompile(`(?is)<body[^>]*>(.*)</body>`)
+
+	epubImageTypes = map[string]string{
+		".gif":  "image/gif",
+		".jpeg": "image/jpeg",
+		".jpg":  "image/jpeg",
+		".png":  "image/png",
+		".svg":  "image/svg+xml",
+		".webp": "image/webp",
+	}
+)
+
+func init() {
+	configFileNames = append(configFileNames, epubConfigFileName)
+}
+
+// epubConfig is the content of an 'epub.yaml' file, which marks its folder as collection that should be exported as EPUB.
+type epubConfig struct {
+	Title      string `yaml:"title"`
+	Author     string `yaml:"author"`
+	Language   string `yaml:"language"`
+	Identifier string `yaml:"identifier"`
+	Output     string `yaml:"output"`  // file name of the EPUB, relative to the collection in the outputDir
+	SortBy     string `yaml:"sortBy"`  // value of the items the chapters are ordered by
+	Reverse    bool   `yaml:"reverse"` // whether the order is descending
+	Chapter    string `yaml:"chapter"` // file name of the rendered single-view of each item, which becomes the chapter content
+}
+
+type epubChapter struct {
+	Id, Title, Href string
+}
+
+type epubFile struct {
+	Id, Href, MediaType string
+}
+
+var epubContainerTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+var epubPackageTemplate = template.Must(template.New("content.opf").Funcs(template.FuncMap{"escape": html.EscapeString}).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="book-id">{{ escape .Config.Identifier }}</dc:identifier>
+    <dc:title>{{ escape .Config.Title }}</dc:title>
+    <dc:language>{{ escape .Config.Language }}</dc:language>
+    {{- if .Config.Author }}
+    <dc:creator>{{ escape .Config.Author }}</dc:creator>
+    {{- end }}
+    <meta property="dcterms:modified">{{ .Modified }}</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    {{- range .Chapters }}
+    <item id="{{ .Id }}" href="{{ escape .Href }}" media-type="application/xhtml+xml"/>
+    {{- end }}
+    {{- range .Files }}
+    <item id="{{ .Id }}" href="{{ escape .Href }}" media-type="{{ .MediaType }}"/>
+    {{- end }}
+  </manifest>
+  <spine>
+    {{- range .Chapters }}
+    <itemref idref="{{ .Id }}"/>
+    {{- end }}
+  </spine>
+</package>
+`))
+
+var epubNavTemplate = template.Must(template.New("nav.xhtml").Funcs(template.FuncMap{"escape": html.EscapeString}).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops">
+<head><title>{{ escape .Config.Title }}</title></head>
+<body>
+  <nav epub:type="toc">
+    <h1>{{ escape .Config.Title }}</h1>
+    <ol>
+      {{- range .Chapters }}
+      <li><a href="{{ escape .Href }}">{{ escape .Title }}</a></li>
+      {{- end }}
+    </ol>
+  </nav>
+</body>
+</html>
+`))
+
+var epubChapterTemplate = template.Must(template.New("chapter.xhtml").Funcs(template.FuncMap{"escape": html.EscapeString}).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<html xmlns="http://www.w3.org/1999/xhtml">
+<head><title>{{ escape .Title }}</title></head>
+<body>
+{{ .Body }}
+</body>
+</html>
+`))
+
+// exportEpubs creates an EPUB for each collection in the inputDir that contains an 'epub.yaml'.
+// Each item of the collection becomes a chapter, its content is taken from the already rendered single-view of the item.
+func (engine *Engine) exportEpubs() error {
+	configPaths, err := engine.getConfigFiles(epubConfigFileName)
+	if err != nil {
+		return err
+	}
+	for _, configPath := range configPaths {
+		collectionPath := path.Dir(configPath)
+		if !engine.isChangedCollection(collectionPath) {
+			continue
+		}
+
+		config := epubConfig{}
+		if err := loadConfigFile(configPath, &config); err != nil {
+			return err
+		}
+		if config.Title == "" {
+			config.Title = path.Base(collectionPath)
+		}
+		if config.Language == "" {
+			config.Language = "en"
+		}
+		if config.Identifier == "" {
+			config.Identifier = "urn:temingo:" + collectionPath
+		}
+		if config.Output == "" {
+			config.Output = path.Base(collectionPath) + ".epub"
+		}
+		if config.Chapter == "" {
+			config.Chapter = "index.html"
+		}
+
+		outputFilePath, err := engine.getOutputFilePath(collectionPath, config.Output)
+		if err != nil {
+			return err
+		}
+		engine.logDebug("*** Exporting '" + collectionPath + "' to EPUB at '" + outputFilePath + "' ... ***")
+
+		epub, err := engine.createEpub(collectionPath, config)
+		if err != nil {
+			return errors.New("Could not export '" + collectionPath + "' to EPUB: " + err.Error())
+		}
+		err = engine.writeTemplateToFile(outputFilePath, epub)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (engine *Engine) createEpub(collectionPath string, config epubConfig) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	archive := zip.NewWriter(buffer)
+
+	// the mimetype has to be the first file of the archive and must not be compressed
+	writer, err := archive.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return nil, err
+	}
+	writer.Write([]byte("application/epub+zip"))
+
+	err = writeEpubFile(archive, "META-INF/container.xml", []byte(epubContainerTemplate))
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := engine.getSortedListObjects(collectionPath, config.SortBy, config.Reverse)
+	if err != nil {
+		return nil, err
+	}
+	chapters := []epubChapter{}
+	files := []epubFile{}
+	for i, item := range items {
+		itemPath := strings.TrimPrefix(toString(item["Path"]), "/")
+		chapterDir := "chapters/" + strconv.Itoa(i+1) // each chapter gets its own folder, so relative image references stay valid
+		chapter := epubChapter{
+			Id:    "chapter-" + strconv.Itoa(i+1),
+			Title: toString(item["title"]),
+			Href:  chapterDir + "/index.xhtml",
+		}
+		if chapter.Title == "" {
+			chapter.Title = path.Base(itemPath)
+		}
+
+		body := toString(item["content"])
+		renderedPath, err := engine.getOutputFilePath(itemPath, config.Chapter)
+		if err != nil {
+			return nil, err
+		}
+		if content, err := ioutil.ReadFile(renderedPath); err == nil {
+			body = string(content)
+			if match := bodyRexp.FindStringSubmatch(body); match != nil {
+				body = match[1]
+			}
+		} else {
+			engine.logDebug("No rendered chapter found at '" + renderedPath + "', using the 'content' value instead.")
+		}
+
+		body, err = convertToXhtml(body)
+		if err != nil {
+			return nil, errors.New("Could not convert the chapter '" + itemPath + "' to xhtml: " + err.Error())
+		}
+
+		chapterBuffer := new(bytes.Buffer)
+		err = epubChapterTemplate.Execute(chapterBuffer, map[string]interface{}{"Title": chapter.Title, "Body": body})
+		if err != nil {
+			return nil, err
+		}
+		err = writeEpubFile(archive, "OEBPS/"+chapter.Href, chapterBuffer.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		chapters = append(chapters, chapter)
+
+		// add the images of the item
+		dirContents, err := ioutil.ReadDir(path.Join(engine.OutputDir, itemPath))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		for _, entry := range dirContents {
+			mediaType, ok := epubImageTypes[strings.ToLower(filepath.Ext(entry.Name()))]
+			if entry.IsDir() || !ok {
+				continue
+			}
+			content, err := ioutil.ReadFile(path.Join(engine.OutputDir, itemPath, entry.Name()))
+			if err != nil {
+				return nil, err
+			}
+			file := epubFile{
+				Id:        "file-" + strconv.Itoa(len(files)+1),
+				Href:      chapterDir + "/" + entry.Name(),
+				MediaType: mediaType,
+			}
+			err = writeEpubFile(archive, "OEBPS/"+file.Href, content)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, file)
+		}
+	}
+
+	modified, ok := getNewestItemDate(items)
+	if !ok { // the last modification of the config instead, so the book stays the same as long as its inputs do
+		modified, _ = getLastModification([]string{path.Join(collectionPath, epubConfigFileName)})
+	}
+	data := map[string]interface{}{
+		"Config":   config,
+		"Chapters": chapters,
+		"Files":    files,
+		"Modified": modified.UTC().Format("2006-01-02T15:04:05Z"),
+	}
+	err = writeEpubTemplate(archive, "OEBPS/content.opf", epubPackageTemplate, data)
+	if err != nil {
+		return nil, err
+	}
+	err = writeEpubTemplate(archive, "OEBPS/nav.xhtml", epubNavTemplate, data)
+	if err != nil {
+		return nil, err
+	}
+
+	err = archive.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// convertToXhtml serializes the html of a chapter body again as xhtml, as EPUB readers require chapters to be valid xml.
+// Void elements like '<br>' are closed and entities like '&nbsp;' are replaced by their characters.
+func convertToXhtml(body string) (string, error) {
+	nodes, err := htmlparser.ParseFragment(strings.NewReader(body), &htmlparser.Node{Type: htmlparser.ElementNode, Data: "body", DataAtom: atom.Body})
+	if err != nil {
+		return "", err
+	}
+	buffer := new(bytes.Buffer)
+	for _, node := range nodes {
+		if err := htmlparser.Render(buffer, node); err != nil {
+			return "", err
+		}
+	}
+	return buffer.String(), nil
+}
+
+// getNewestItemDate returns the newest 'date' of the items, and false if none of them has one.
+func getNewestItemDate(items []map[string]interface{}) (time.Time, bool) {
+	newest, ok := time.Time{}, false
+	for _, item := range items {
+		if date, isDate := toTime(item["date"]); isDate && date.After(newest) {
+			newest, ok = date, true
+		}
+	}
+	return newest, ok
+}
+
+func writeEpubTemplate(archive *zip.Writer, name string, tpl *template.Template, data interface{}) error {
+	buffer := new(bytes.Buffer)
+	err := tpl.Execute(buffer, data)
+	if err != nil {
+		return err
+	}
+	return writeEpubFile(archive, name, buffer.Bytes())
+}
+
+func writeEpubFile(archive *zip.Writer, name string, content []byte) error {
+	writer, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(content)
+	return err
+}
@@ -0,0 +1,96 @@
+package temingo
+
+import (
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var templateErrorRegexp = regexp.MustCompile(`template: ([^:\s]+):(\d+):`) // like 'template: blog/index.html.template:3:12: executing ...'
+
+// BuildErrors contains all errors of a build, so they are reported at once instead of aborting at the first one.
+type BuildErrors []error
+
+func (errs BuildErrors) Error() string {
+	messages := []string{}
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+// add appends err, unless it's nil or already contained (f.e. a broken partial is reported once instead of for each template it's parsed into).
+// Nested BuildErrors are flattened.
+func (errs *BuildErrors) add(err error) {
+	if err == nil {
+		return
+	}
+	if nested, ok := err.(BuildErrors); ok {
+		for _, nestedErr := range nested {
+			errs.add(nestedErr)
+		}
+		return
+	}
+	for _, existing := range *errs {
+		if existing.Error() == err.Error() {
+			return
+		}
+	}
+	*errs = append(*errs, err)
+}
+
+// err returns the BuildErrors as error, or nil if there are none.
+func (errs BuildErrors) err() error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// templateError adds the file and the line a template error refers to, so it can be found without searching for the name of the template.
+type templateError struct {
+	err      error
+	filePath string
+	line     int
+	source   string
+}
+
+func (err templateError) Error() string {
+	message := err.filePath + ":" + strconv.Itoa(err.line) + ": " + err.err.Error()
+	if err.source != "" {
+		message += "\n    " + strconv.Itoa(err.line) + " | " + strings.TrimSpace(err.source)
+	}
+	return message
+}
+
+// describeTemplateError returns err with the file and the source line it refers to, if it names a template or partial of sources.
+func (engine *Engine) describeTemplateError(err error, sources renderSources) error {
+	match := templateErrorRegexp.FindStringSubmatch(err.Error())
+	if match == nil {
+		return err
+	}
+	name := match[1]
+	line, _ := strconv.Atoi(match[2])
+
+	filePath, content := "", ""
+	for _, template := range append(append(append([][]string{}, sources.templates...), sources.singleTemplates...), sources.markdownFiles...) {
+		if template[0] == name {
+			filePath, content = template[0], template[1]
+		}
+	}
+	for _, partial := range sources.partials {
+		if filePath == "" && (partial[0] == name || templateDefinesName(partial[1], name)) {
+			filePath, content = path.Join(engine.PartialsDir, partial[0]+engine.PartialExtension), partial[1]
+		}
+	}
+	if filePath == "" {
+		return err
+	}
+
+	source := ""
+	if lines := strings.Split(content, "\n"); line >= 1 && line <= len(lines) {
+		source = lines[line-1]
+	}
+	return templateError{err: err, filePath: filePath, line: line, source: source}
+}
@@ -0,0 +1,118 @@
+package temingo
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	gitignore "github.com/sabhiram/go-gitignore"
+)
+
+// loadTemingoignore reads the ignore file. It's read once per build, so a broken ignore file is reported once.
+func (engine *Engine) loadTemingoignore() error {
+	content, err := ioutil.ReadFile(engine.TemingoignoreFilePath)
+	if err != nil {
+		return errors.New("Could not read the ignore file '" + engine.TemingoignoreFilePath + "': " + err.Error())
+	}
+	engine.temingoignoreLines = strings.Split(string(content), "\n")
+	return nil
+}
+
+// matchesTemingoignore returns whether srcPath is matched by the ignore file or one of the additionalExclusions.
+// The additionalExclusions come after the lines of the ignore file, so their negations ('!') include files the ignore file excludes.
+func (engine *Engine) matchesTemingoignore(srcPath string, additionalExclusions []string) bool {
+	lines := append(append([]string{}, engine.temingoignoreLines...), additionalExclusions...)
+	return gitignore.CompileIgnoreLines(lines...).MatchesPath(srcPath)
+}
+
+func (engine *Engine) isExcludedByTemingoignore(srcPath string, additionalExclusions []string) bool {
+	srcPath = "/" + filepath.ToSlash(srcPath)
+
+	if engine.matchesTemingoignore(srcPath, additionalExclusions) {
+		engine.logDebug("Exclusion triggered at '" + srcPath + "', specified in '" + engine.TemingoignoreFilePath + "'.")
+		return true
+	}
+
+	return false
+}
+
+func (engine *Engine) isExcluded(srcPath string, additionalExclusions []string) bool {
+	srcPath = "/" + filepath.ToSlash(srcPath)
+
+	additionalExclusions = append(additionalExclusions, engine.getInternalExclusions()...)
+
+	if engine.matchesTemingoignore(srcPath, additionalExclusions) {
+		engine.logDebug("Exclusion triggered at '" + srcPath + "', specified internally.")
+		return true
+	}
+
+	return false
+}
+
+// getInternalExclusions returns the files which are always excluded, as they are no content of the inputDir.
+func (engine *Engine) getInternalExclusions() []string {
+	exclusions := []string{
+		"/" + engine.TemingoignoreFilePath,      // always ignore the ignore file itself
+		"/" + path.Join(engine.OutputDir, "**"), // always ignore the outputDir
+		"/" + path.Join(engine.StaticDir, "**"), // always ignore the staticDir
+		"/" + path.Join(engine.DataDir, "**"),   // always ignore the dataDir
+		"/" + path.Join(cacheDir, "**"),         // always ignore the cacheDir
+		"/" + lockFileName,                      // always ignore the lock file
+	}
+	if engine.actualOutputDir != "" {
+		exclusions = append(exclusions, "/"+path.Join(engine.actualOutputDir, "**")) // ignore the actual outputDir while rendering to another folder as well
+	}
+	if engine.WatchLog != "" {
+		exclusions = append(exclusions, "/"+cleanPath(engine.WatchLog)) // ignore the watch log, which is written while watching
+	}
+	if engine.Manifest != "" {
+		exclusions = append(exclusions, "/"+cleanPath(engine.Manifest)) // ignore the build manifest, which is written after each build
+	}
+	if engine.ProfileDir != "" {
+		exclusions = append(exclusions, "/"+path.Join(cleanPath(engine.ProfileDir), "**")) // ignore the profiles, which are written after a build
+	}
+	return exclusions
+}
+
+// matchesExclusions returns whether srcPath is matched by one of the exclusions or the internal ones, regardless of the ignore file.
+func (engine *Engine) matchesExclusions(srcPath string, exclusions []string) bool {
+	return gitignore.CompileIgnoreLines(append(append([]string{}, exclusions...), engine.getInternalExclusions()...)...).MatchesPath("/" + srcPath)
+}
+
+// isExcludedFromWatching returns whether changes of the watched file at absolutePath are ignored, as it matches one of the watchExclusions.
+func (engine *Engine) isExcludedFromWatching(absolutePath string) bool {
+	if len(engine.WatchExclusions) == 0 {
+		return false
+	}
+	filePath, ok := getRelativePath(absolutePath)
+	if !ok {
+		return false
+	}
+	return gitignore.CompileIgnoreLines(engine.WatchExclusions...).MatchesPath("/" + filePath)
+}
+
+// appendTemingoignore appends ignoreLine to the ignore file at filePath, unless it already contains it. A missing ignore file is created.
+func (engine *Engine) appendTemingoignore(filePath string, ignoreLine string) error {
+	existing, err := ioutil.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if strings.Contains(string(existing), ignoreLine) {
+		return nil
+	}
+	if len(existing) > 0 && !strings.HasSuffix(string(existing), "\n") {
+		ignoreLine = "\n" + ignoreLine
+	}
+	file, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, engine.fileMode)
+	if err != nil {
+		return err
+	}
+	_, err = file.WriteString(ignoreLine)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,88 @@
+package temingo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var funcNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// validateExecFunctions checks the names of the execFunctions are valid and don't replace a function of sprig or temingo, and their commands aren't empty.
+func (engine *Engine) validateExecFunctions() error {
+	builtins := engine.getBuiltinFuncMap("", nil, nil)
+	for name, command := range engine.ExecFunctions {
+		if !funcNameRegexp.MatchString(name) {
+			return errors.New("The template function name '" + name + "' is invalid, it must only contain letters, digits and underscores and not start with a digit")
+		}
+		if _, ok := builtins[name]; ok {
+			return errors.New("The template function '" + name + "' already exists and can't be replaced")
+		}
+		if len(strings.Fields(command)) == 0 {
+			return errors.New("The command of the template function '" + name + "' must not be empty")
+		}
+	}
+	return nil
+}
+
+// getExecFunctions returns the execFunctions as template functions. Each runs its command with the arguments as json array on stdin.
+// The output is decoded as json if possible, so commands can return numbers, lists and maps, otherwise it's returned as string without the trailing newline.
+// Calls with the same arguments are only run once per build.
+func (engine *Engine) getExecFunctions() map[string]interface{} {
+	functions := make(map[string]interface{}, len(engine.ExecFunctions))
+	for name, command := range engine.ExecFunctions {
+		name, command := name, command
+		functions[name] = func(args ...interface{}) (interface{}, error) {
+			return engine.runExecFunction(name, command, args)
+		}
+	}
+	return functions
+}
+
+// runExecFunction runs the command of the template function name with args.
+func (engine *Engine) runExecFunction(name string, command string, args []interface{}) (interface{}, error) {
+	if args == nil {
+		args = []interface{}{}
+	}
+	input, err := json.Marshal(args)
+	if err != nil {
+		return nil, errors.New(name + ": the arguments can't be passed as json: " + err.Error())
+	}
+	cacheKey := name + "\x00" + string(input)
+	engine.lock.Lock()
+	output, ok := engine.execFunctionResults[cacheKey]
+	engine.lock.Unlock()
+
+	if !ok {
+		if engine.isProfilingTemplates() {
+			defer engine.recordProfile("function", name, time.Now())
+		}
+		fields := strings.Fields(command)
+		engine.logDebug("Running the template function '" + name + "' via '" + command + "' ...")
+		cmd := exec.Command(fields[0], fields[1:]...)
+		cmd.Stdin = bytes.NewReader(input)
+		stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+		cmd.Stdout, cmd.Stderr = stdout, stderr
+		if err := cmd.Run(); err != nil {
+			message := strings.TrimSpace(stderr.String())
+			if message == "" {
+				message = err.Error()
+			}
+			return nil, errors.New(name + ": '" + command + "' failed: " + message)
+		}
+		output = stdout.Bytes()
+		engine.lock.Lock()
+		engine.execFunctionResults[cacheKey] = output
+		engine.lock.Unlock()
+	}
+
+	var result interface{}
+	if err := json.Unmarshal(output, &result); err == nil { // decoded for each call, so templates can't modify the results of others
+		return result, nil
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(string(output), "\n"), "\r"), nil
+}
@@ -0,0 +1,226 @@
+package temingo
+
+import (
+	"encoding/xml"
+	"errors"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const feedConfigFileName = "feed.yaml"
+
+func init() {
+	configFileNames = append(configFileNames, feedConfigFileName)
+}
+
+// feedConfig is the content of a 'feed.yaml' file or an element of the 'feeds' value, which results in RSS and Atom feeds of the items of the collection.
+type feedConfig struct {
+	Path        string   `yaml:"path"` // folder of the collection, only used for the 'feeds' value
+	Title       string   `yaml:"title"`
+	Description string   `yaml:"description"`
+	Author      string   `yaml:"author"`
+	Formats     []string `yaml:"formats"`    // 'rss' and/or 'atom', defaults to both
+	RssOutput   string   `yaml:"rssOutput"`  // file name of the RSS feed, relative to the collection in the outputDir
+	AtomOutput  string   `yaml:"atomOutput"` // file name of the Atom feed, relative to the collection in the outputDir
+	Limit       int      `yaml:"limit"`      // maximum number of items in the feeds
+}
+
+type rssDocument struct {
+	XMLName xml.Name `xml:"rss"`
+	Version string   `xml:"version,attr"`
+	Channel struct {
+		Title         string    `xml:"title"`
+		Link          string    `xml:"link"`
+		Description   string    `xml:"description"`
+		LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+		Items         []rssItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Guid        string `xml:"guid"`
+	Description string `xml:"description,omitempty"`
+	PubDate     string `xml:"pubDate"`
+}
+
+type atomDocument struct {
+	XMLName  xml.Name    `xml:"feed"`
+	Xmlns    string      `xml:"xmlns,attr"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	ID       string      `xml:"id"`
+	Link     atomLink    `xml:"link"`
+	Updated  string      `xml:"updated"`
+	Author   atomAuthor  `xml:"author"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	Title   string   `xml:"title"`
+	ID      string   `xml:"id"`
+	Link    atomLink `xml:"link"`
+	Updated string   `xml:"updated"`
+	Summary string   `xml:"summary,omitempty"`
+}
+
+// exportFeeds creates RSS and Atom feeds for each collection in the inputDir that contains a 'feed.yaml' or is listed in the 'feeds' value.
+// Each item with a 'date' value becomes an entry, the most recent first. Optional item values are 'title' and 'description'.
+func (engine *Engine) exportFeeds() error {
+	configs, err := engine.getFeedConfigs()
+	if err != nil {
+		return err
+	}
+	if len(configs) == 0 {
+		return nil
+	}
+	if engine.siteBaseURL == "" {
+		return errors.New("The feed export requires the '--baseURL' flag or the 'baseURL' value to be set, as feeds contain absolute URLs.")
+	}
+
+	for _, config := range configs {
+		collectionPath := config.Path
+		if !engine.isChangedCollection(collectionPath) {
+			continue
+		}
+		if config.Title == "" {
+			config.Title = path.Base(collectionPath)
+		}
+		if config.Author == "" {
+			config.Author = config.Title
+		}
+		if len(config.Formats) == 0 {
+			config.Formats = []string{"rss", "atom"}
+		}
+		if config.RssOutput == "" {
+			config.RssOutput = "rss.xml"
+		}
+		if config.AtomOutput == "" {
+			config.AtomOutput = "atom.xml"
+		}
+		if config.Limit == 0 {
+			config.Limit = 20
+		}
+
+		engine.logDebug("*** Exporting the feeds of '" + collectionPath + "' ... ***")
+
+		items, err := engine.getSortedListObjects(collectionPath, "date", true)
+		if err != nil {
+			return err
+		}
+		collectionURL := engine.absoluteURL(path.Join("/", collectionPath) + "/")
+		rss := rssDocument{Version: "2.0"}
+		rss.Channel.Title = config.Title
+		rss.Channel.Link = collectionURL
+		rss.Channel.Description = config.Description
+		atom := atomDocument{Xmlns: "http://www.w3.org/2005/Atom", Title: config.Title, Subtitle: config.Description, ID: collectionURL, Link: atomLink{collectionURL}, Author: atomAuthor{config.Author}}
+		for _, item := range items {
+			if len(rss.Channel.Items) == config.Limit {
+				break
+			}
+			date, ok := toTime(item["date"])
+			if !ok {
+				engine.logDebug("Skipping '" + toString(item["Path"]) + "' for the feeds, as it has no valid 'date' value.")
+				continue
+			}
+			if atom.Updated == "" { // the items are sorted by date, so the first one is the latest
+				rss.Channel.LastBuildDate = date.UTC().Format(time.RFC1123Z)
+				atom.Updated = date.UTC().Format(time.RFC3339)
+			}
+			itemURL := engine.absoluteURL(toString(item["Path"]) + "/")
+			rss.Channel.Items = append(rss.Channel.Items, rssItem{toString(item["title"]), itemURL, itemURL, toString(item["description"]), date.UTC().Format(time.RFC1123Z)})
+			atom.Entries = append(atom.Entries, atomEntry{toString(item["title"]), itemURL, atomLink{itemURL}, date.UTC().Format(time.RFC3339), toString(item["description"])})
+		}
+		if atom.Updated == "" { // a feed without entries
+			atom.Updated = time.Now().UTC().Format(time.RFC3339)
+		}
+
+		for _, format := range config.Formats {
+			var document interface{}
+			var fileName string
+			switch format {
+			case "rss":
+				document, fileName = rss, config.RssOutput
+			case "atom":
+				document, fileName = atom, config.AtomOutput
+			default:
+				return errors.New("The feed format '" + format + "' of '" + collectionPath + "' is not supported, it must be 'rss' or 'atom'.")
+			}
+			content, err := xml.MarshalIndent(document, "", "  ")
+			if err != nil {
+				return err
+			}
+			outputFilePath, err := engine.getOutputFilePath(collectionPath, fileName)
+			if err != nil {
+				return err
+			}
+			engine.logDebug("Writing " + format + " feed '" + outputFilePath + "' ...")
+			err = engine.writeTemplateToFile(outputFilePath, append([]byte(xml.Header), append(content, '\n')...))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// getFeedConfigs returns the configs of all feeds, ordered by their collection. A 'feed.yaml' takes precedence over an element of the 'feeds' value for the same collection.
+func (engine *Engine) getFeedConfigs() ([]feedConfig, error) {
+	configs := make(map[string]feedConfig)
+
+	if feeds, ok := engine.previousValues["feeds"]; ok { // the values of the current build
+		content, err := yaml.Marshal(feeds)
+		if err != nil {
+			return nil, err
+		}
+		valueConfigs := []feedConfig{}
+		if err := yaml.Unmarshal(content, &valueConfigs); err != nil {
+			return nil, errors.New("The 'feeds' value must be a list of feeds: " + err.Error())
+		}
+		for _, config := range valueConfigs {
+			if config.Path == "" {
+				return nil, errors.New("Each element of the 'feeds' value must contain the 'path' of its collection.")
+			}
+			config.Path = path.Join(engine.InputDir, strings.TrimPrefix(config.Path, "/"))
+			configs[config.Path] = config
+		}
+	}
+
+	configPaths, err := engine.getConfigFiles(feedConfigFileName)
+	if err != nil {
+		return nil, err
+	}
+	for _, configPath := range configPaths {
+		config := feedConfig{}
+		if err := loadConfigFile(configPath, &config); err != nil {
+			return nil, err
+		}
+		config.Path = path.Dir(configPath)
+		configs[config.Path] = config
+	}
+
+	collectionPaths := []string{}
+	for collectionPath := range configs {
+		collectionPaths = append(collectionPaths, collectionPath)
+	}
+	sort.Strings(collectionPaths)
+	sorted := []feedConfig{}
+	for _, collectionPath := range collectionPaths {
+		sorted = append(sorted, configs[collectionPath])
+	}
+	return sorted, nil
+}
@@ -0,0 +1,292 @@
+package temingo
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/radovskyb/watcher"
+)
+
+// fileWatcher reports changes of the watched files and folders, either via notifications of the operating system or via polling, see newFileWatcher.
+// Both report their changes as watcher.Event with absolute paths.
+type fileWatcher interface {
+	addRecursive(dirPath string) error  // watches the folder and everything inside it, including folders created later on
+	add(filePath string) error          // watches a single file
+	isWatched(absolutePath string) bool // whether the file or folder is watched
+	watchedPaths() []string             // the absolute paths of all watched files and folders, sorted
+	events() <-chan watcher.Event       // the changes
+	errors() <-chan error               // errors while watching, which don't end it
+	closed() <-chan struct{}            // closed once watching ended
+	start(interval time.Duration) error // watches until closed, the interval is only used for polling
+	close()                             // ends watching
+}
+
+// newFileWatcher returns the fsnotify based fileWatcher, or the polling one with the poll option or if the operating system doesn't provide notifications.
+// The outputDir, the cacheDir, the lock file, the watchLog and the '.git' folder are never watched.
+func (engine *Engine) newFileWatcher() fileWatcher {
+	ignored := []string{engine.OutputDir, ".git", cacheDir, lockFileName}
+	if engine.WatchLog != "" {
+		ignored = append(ignored, engine.WatchLog)
+	}
+	if !engine.Poll {
+		w, err := newNotifyWatcher(ignored)
+		if err == nil {
+			return w
+		}
+		engine.logWarn("Could not watch via notifications of the operating system, polling instead: " + err.Error())
+	}
+	return newPollingWatcher(ignored)
+}
+
+// pollingWatcher checks the watched files for changes every interval.
+type pollingWatcher struct {
+	*watcher.Watcher
+}
+
+func newPollingWatcher(ignored []string) *pollingWatcher {
+	w := watcher.New()
+	w.Ignore(ignored...) // ignoring before adding, so the ignored paths won't be added
+	return &pollingWatcher{w}
+}
+
+func (w *pollingWatcher) addRecursive(dirPath string) error {
+	return w.AddRecursive(dirPath)
+}
+
+func (w *pollingWatcher) add(filePath string) error {
+	return w.Add(filePath)
+}
+
+func (w *pollingWatcher) isWatched(absolutePath string) bool {
+	_, ok := w.WatchedFiles()[absolutePath]
+	return ok
+}
+
+func (w *pollingWatcher) watchedPaths() []string {
+	paths := []string{}
+	for watchedPath := range w.WatchedFiles() {
+		paths = append(paths, watchedPath)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func (w *pollingWatcher) events() <-chan watcher.Event {
+	return w.Event
+}
+
+func (w *pollingWatcher) errors() <-chan error {
+	return w.Error
+}
+
+func (w *pollingWatcher) closed() <-chan struct{} {
+	return w.Closed
+}
+
+func (w *pollingWatcher) start(interval time.Duration) error {
+	return w.Start(interval)
+}
+
+func (w *pollingWatcher) close() {
+	w.Close()
+}
+
+// notifyWatcher receives the changes of the watched folders from the operating system, via fsnotify.
+// Single files are watched via their parent folder, so they are still watched after editors replace them with a new file.
+type notifyWatcher struct {
+	watcher    *fsnotify.Watcher
+	ignored    []string        // absolute paths of the files and folders which are never watched
+	folders    map[string]bool // absolute paths of the folders watched with everything inside them
+	files      map[string]bool // absolute paths of the single watched files
+	eventChan  chan watcher.Event
+	errorChan  chan error
+	closedChan chan struct{}
+	lock       sync.Mutex // guards the folders and files
+}
+
+func newNotifyWatcher(ignored []string) (*notifyWatcher, error) {
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	w := &notifyWatcher{
+		watcher:    fsWatcher,
+		folders:    make(map[string]bool),
+		files:      make(map[string]bool),
+		eventChan:  make(chan watcher.Event),
+		errorChan:  make(chan error),
+		closedChan: make(chan struct{}),
+	}
+	for _, ignoredPath := range ignored {
+		absolutePath, err := filepath.Abs(ignoredPath)
+		if err != nil {
+			return nil, err
+		}
+		w.ignored = append(w.ignored, absolutePath)
+	}
+	return w, nil
+}
+
+// isIgnored returns whether absolutePath is one of the ignored files or folders, or inside one of them.
+func (w *notifyWatcher) isIgnored(absolutePath string) bool {
+	for _, ignoredPath := range w.ignored {
+		if absolutePath == ignoredPath || strings.HasPrefix(absolutePath, ignoredPath+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *notifyWatcher) addRecursive(dirPath string) error {
+	absolutePath, err := filepath.Abs(dirPath)
+	if err != nil {
+		return err
+	}
+	return filepath.Walk(absolutePath, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if w.isIgnored(filePath) {
+			return filepath.SkipDir
+		}
+		if err := w.watcher.Add(filePath); err != nil {
+			return err
+		}
+		w.lock.Lock()
+		w.folders[filePath] = true
+		w.lock.Unlock()
+		return nil
+	})
+}
+
+func (w *notifyWatcher) add(filePath string) error {
+	absolutePath, err := filepath.Abs(filePath)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(absolutePath); err != nil {
+		return err
+	}
+	if err := w.watcher.Add(filepath.Dir(absolutePath)); err != nil {
+		return err
+	}
+	w.lock.Lock()
+	w.files[absolutePath] = true
+	w.lock.Unlock()
+	return nil
+}
+
+func (w *notifyWatcher) isWatched(absolutePath string) bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.folders[absolutePath] || w.files[absolutePath] || w.folders[filepath.Dir(absolutePath)]
+}
+
+func (w *notifyWatcher) watchedPaths() []string {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	paths := []string{}
+	for watchedPath := range w.folders {
+		paths = append(paths, watchedPath)
+	}
+	for watchedPath := range w.files {
+		paths = append(paths, watchedPath)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func (w *notifyWatcher) events() <-chan watcher.Event {
+	return w.eventChan
+}
+
+func (w *notifyWatcher) errors() <-chan error {
+	return w.errorChan
+}
+
+func (w *notifyWatcher) closed() <-chan struct{} {
+	return w.closedChan
+}
+
+// start forwards the notifications of the watched files and folders as watcher.Event until the watcher is closed.
+// Folders created inside watched folders are watched as well.
+func (w *notifyWatcher) start(interval time.Duration) error {
+	defer close(w.closedChan)
+	for {
+		select {
+		case notification, ok := <-w.watcher.Events:
+			if !ok {
+				return nil
+			}
+			event, ok := w.toEvent(notification)
+			if !ok {
+				continue
+			}
+			if event.Op == watcher.Create && event.IsDir() {
+				if err := w.addRecursive(event.Path); err != nil {
+					w.errorChan <- err
+				}
+			}
+			if event.Op == watcher.Remove || event.Op == watcher.Rename {
+				w.lock.Lock()
+				delete(w.folders, event.Path) // the operating system removes the watch itself
+				w.lock.Unlock()
+			}
+			w.eventChan <- event
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return nil
+			}
+			w.errorChan <- err
+		}
+	}
+}
+
+// toEvent converts the notification to the watcher.Event of the polling watcher. Returns false if it's not about a watched file or folder.
+func (w *notifyWatcher) toEvent(notification fsnotify.Event) (watcher.Event, bool) {
+	absolutePath, err := filepath.Abs(notification.Name)
+	if err != nil || w.isIgnored(absolutePath) || !w.isWatched(absolutePath) {
+		return watcher.Event{}, false
+	}
+	event := watcher.Event{Path: absolutePath}
+	switch {
+	case notification.Op&fsnotify.Remove != 0:
+		event.Op = watcher.Remove
+	case notification.Op&fsnotify.Rename != 0:
+		event.Op = watcher.Rename
+	case notification.Op&fsnotify.Create != 0:
+		event.Op = watcher.Create
+	case notification.Op&fsnotify.Write != 0:
+		event.Op = watcher.Write
+	default:
+		event.Op = watcher.Chmod
+	}
+	info, err := os.Lstat(absolutePath)
+	if err != nil { // already deleted again
+		event.Op, info = watcher.Remove, removedFileInfo(filepath.Base(absolutePath))
+	}
+	event.FileInfo = info
+	return event, true
+}
+
+func (w *notifyWatcher) close() {
+	w.watcher.Close()
+}
+
+// removedFileInfo is the os.FileInfo of events about files which don't exist anymore.
+type removedFileInfo string
+
+func (name removedFileInfo) Name() string       { return string(name) }
+func (name removedFileInfo) Size() int64        { return 0 }
+func (name removedFileInfo) Mode() os.FileMode  { return 0 }
+func (name removedFileInfo) ModTime() time.Time { return time.Time{} }
+func (name removedFileInfo) IsDir() bool        { return false }
+func (name removedFileInfo) Sys() interface{}   { return nil }
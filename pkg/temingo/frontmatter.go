@@ -0,0 +1,69 @@
+package temingo
+
+import (
+	"errors"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const frontMatterDelimiter = "---"
+
+// splitFrontMatter separates the optional yaml front matter at the top of a template from its body.
+// The front matter is delimited by '---' lines. It's replaced by a template comment spanning the same lines, so line numbers in template errors stay correct and no empty lines end up in the output.
+// A 'layout' in the front matter is the same as '{{ extends "<layout>" }}' at the top of the body, see the layout inheritance.
+func splitFrontMatter(templateName string, content string) (map[string]interface{}, string, error) {
+	frontMatter, body, end, err := parseFrontMatter(templateName, content)
+	if err != nil || end == -1 {
+		return frontMatter, content, err
+	}
+	extends := ""
+	if value, ok := frontMatter["layout"]; ok {
+		layout, ok := value.(string)
+		if !ok || layout == "" {
+			return nil, content, errors.New("The front matter 'layout' of '" + templateName + "' must be the name of a partial, f.e. 'layouts/base'.")
+		}
+		extends = `{{ extends "` + layout + `" }}`
+	}
+	return frontMatter, "{{/*" + strings.Repeat("\n", end) + "*/ -}}" + extends + "\n" + body, nil
+}
+
+// setPageParams makes the front matter of the rendered template or markdown file available as '.Page.Params', f.e. its 'title' and 'description'.
+func (engine *Engine) setPageParams(context map[string]interface{}, frontMatter map[string]interface{}) {
+	if engine.FlatContext {
+		context["Params"] = copyValues(frontMatter)
+		return
+	}
+	context["Page"].(map[string]interface{})["Params"] = copyValues(frontMatter) // each output gets its own copy, like the values
+}
+
+// parseFrontMatter returns the optional yaml front matter at the top of content, the content after it and the line of its closing delimiter.
+// If there is no front matter, the line is -1 and the content is returned as it is.
+func parseFrontMatter(fileName string, content string) (map[string]interface{}, string, int, error) {
+	frontMatter := make(map[string]interface{})
+
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	if lines[0] != frontMatterDelimiter {
+		return frontMatter, content, -1, nil
+	}
+	end := -1
+	for i := 1; i < len(lines); i++ {
+		if lines[i] == frontMatterDelimiter {
+			end = i
+			break
+		}
+	}
+	if end == -1 { // not terminated, so it's not front matter
+		return frontMatter, content, -1, nil
+	}
+
+	err := yaml.Unmarshal([]byte(strings.Join(lines[1:end], "\n")), &frontMatter)
+	if err != nil {
+		return nil, content, -1, errors.New("Could not parse the front matter of '" + fileName + "': " + err.Error())
+	}
+	if frontMatter == nil { // empty front matter
+		frontMatter = make(map[string]interface{})
+	}
+
+	return frontMatter, strings.Join(lines[end+1:], "\n"), end, nil
+}
@@ -0,0 +1,131 @@
+package temingo
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/sprig"
+)
+
+// TemplateFunction is a function available in templates, see Funcs.
+type TemplateFunction struct {
+	Name        string `json:"name"`
+	Signature   string `json:"signature"`            // the types of the arguments and results, f.e. 'getValue(string, ...interface{}) (interface{}, error)', empty for the functions of text/template
+	Source      string `json:"source"`               // where the function comes from, either 'text/template', 'sprig', 'temingo' or 'exec'
+	Description string `json:"description"`          // what the function does, in a single sentence
+	Deprecated  string `json:"deprecated,omitempty"` // how to migrate, if the function is deprecated
+}
+
+// builtinFunctionDescriptions describe the functions of text/template, see builtinFunctions.
+var builtinFunctionDescriptions = map[string]string{
+	"and":      "Returns the first empty argument or the last argument.",
+	"call":     "Calls the function given as first argument with the remaining arguments.",
+	"eq":       "Returns whether the first argument equals any of the others.",
+	"ge":       "Returns whether the first argument is greater than or equal to the second.",
+	"gt":       "Returns whether the first argument is greater than the second.",
+	"html":     "Returns the html escaped text of its arguments.",
+	"index":    "Returns the element of a map, slice or array at the given keys or indexes.",
+	"js":       "Returns the javascript escaped text of its arguments.",
+	"le":       "Returns whether the first argument is less than or equal to the second.",
+	"len":      "Returns the length of a string, map, slice, array or channel.",
+	"lt":       "Returns whether the first argument is less than the second.",
+	"ne":       "Returns whether the arguments are not equal.",
+	"not":      "Returns the boolean negation of its argument.",
+	"or":       "Returns the first non-empty argument or the last argument.",
+	"print":    "Formats its arguments like fmt.Sprint.",
+	"printf":   "Formats its arguments like fmt.Sprintf.",
+	"println":  "Formats its arguments like fmt.Sprintln.",
+	"slice":    "Returns the string, slice or array sliced by the given indexes.",
+	"urlquery": "Returns the text of its arguments escaped for url queries.",
+}
+
+// temingoFunctionDescriptions describe the functions of temingo, by their name in getTemingoFuncMap.
+var temingoFunctionDescriptions = map[string]string{
+	"addPercentage":   "Adds two percentages like '20%' and returns the sum as percentage.",
+	"extends":         "Renders the template into the blocks of the given layout, only allowed at the top level of a template.",
+	"include":         "Renders the partial or defined template with the given data and returns the result, so it can be piped.",
+	"safeHTML":        "Marks the string as safe html, so it isn't escaped.",
+	"safeCSS":         "Marks the string as safe css, so it isn't escaped.",
+	"xmlEscape":       "Escapes the string for xml and html.",
+	"list":            "Returns the items of the given folders, by default the one of the template.",
+	"listTree":        "Returns the items of the given folder and its subfolders as tree, optionally sorted by the given keys.",
+	"asset":           "Returns the fingerprinted path of a static file or asset bundle.",
+	"assetBundle":     "Returns the html tag including the given asset bundle.",
+	"imageResize":     "Writes a resized copy of an image and returns its path.",
+	"imageCrop":       "Writes a cropped copy of an image and returns its path.",
+	"imageConvert":    "Writes a copy of an image in another format and returns its path.",
+	"urlize":          "Converts the string into a normalized, lowercase url path.",
+	"required":        "Returns the value, or fails the build with the message if it's missing or empty.",
+	"getValue":        "Returns the value of the page at the dotted key path, or the default if it's missing.",
+	"hasValue":        "Returns whether the page has a non-null value at the dotted key path.",
+	"warnf":           "Logs a formatted warning including the name of the template, without failing the build.",
+	"pages":           "Returns all pages and items of the site.",
+	"related":         "Returns the other items of the given folder sharing tags, categories or the given weighted keys with the item, most related first.",
+	"isActive":        "Returns whether the url points to the page with the given path.",
+	"hasActiveChild":  "Returns whether the page with the given path is below the folder the url points to.",
+	"where":           "Returns the pages or items whose key matches the value, optionally compared with an operator.",
+	"sortBy":          "Returns the pages or items sorted by the given keys, each optionally followed by 'desc'.",
+	"first":           "Returns the first n pages or items, or the first element of a list.",
+	"limit":           "Returns the first n pages or items.",
+	"reverse":         "Returns the pages or items in reverse order.",
+	"slice":           "Returns a list of its arguments, or slices the list given as first argument.",
+	"count":           "Returns the number of pages or items.",
+	"sumBy":           "Returns the sum of the key of the pages or items.",
+	"minBy":           "Returns the page or item with the smallest value of the key.",
+	"maxBy":           "Returns the page or item with the largest value of the key.",
+	"uniqBy":          "Returns the pages or items with distinct values of the key, the first one of each value.",
+	"sortedKeys":      "Returns the keys of a map in natural order.",
+	"sortedPairs":     "Returns the keys and values of a map as pairs in natural order of the keys.",
+	"webmentionLinks": "Returns the html links announcing the webmention and pingback endpoints.",
+	"webmentions":     "Returns the webmentions received by the given page.",
+	"getJSON":         "Fetches and decodes json from a url, cached across builds.",
+	"getYAML":         "Fetches and decodes yaml from a url, cached across builds.",
+	"T":               "Returns the translation of the key in the current language, formatted with the further arguments.",
+	"langPath":        "Returns the path prefixed with the folder of the current language.",
+	"params":          "Declares the parameters of a partial with types and defaults, and returns its arguments completed with the defaults.",
+	"env":             "Returns the value of an environment variable in the envAllowlist.",
+	"expandenv":       "Replaces '$NAME' and '${NAME}' in the string with the environment variables in the envAllowlist.",
+	"capitalize":      "Capitalizes the first letter of each word.",
+}
+
+// Funcs returns all functions available in templates, sorted by name: the ones of text/template, sprig, temingo and the execFunctions.
+// Functions of temingo replacing the ones of sprig are only listed once, as the ones of temingo.
+func (engine *Engine) Funcs() ([]TemplateFunction, error) {
+	if err := engine.validateExecFunctions(); err != nil {
+		return nil, err
+	}
+	sprigFuncMap := sprig.GenericFuncMap()
+	temingoFuncMap := engine.getTemingoFuncMap("", nil, nil, sprigFuncMap)
+	functions := []TemplateFunction{}
+	for _, name := range builtinFunctions {
+		if _, ok := sprigFuncMap[name]; ok { // replaced by sprig or temingo
+			continue
+		}
+		functions = append(functions, TemplateFunction{Name: name, Source: "text/template", Description: builtinFunctionDescriptions[name]})
+	}
+	for name, function := range sprigFuncMap {
+		if _, ok := temingoFuncMap[name]; ok {
+			continue
+		}
+		functions = append(functions, TemplateFunction{Name: name, Signature: getSignature(name, function), Source: "sprig", Description: "See https://masterminds.github.io/sprig/."})
+	}
+	for name, function := range temingoFuncMap {
+		templateFunction := TemplateFunction{Name: name, Signature: getSignature(name, function), Source: "temingo", Description: temingoFunctionDescriptions[name]}
+		if id, ok := deprecatedFunctions[name]; ok {
+			templateFunction.Deprecated = deprecations[id].hint
+		}
+		functions = append(functions, templateFunction)
+	}
+	for name, command := range engine.ExecFunctions {
+		functions = append(functions, TemplateFunction{Name: name, Signature: name + "(...interface{}) (interface{}, error)", Source: "exec", Description: "Runs '" + command + "' with the arguments as json on stdin and returns its output."})
+	}
+	sort.Slice(functions, func(i, j int) bool { return functions[i].Name < functions[j].Name })
+	return functions, nil
+}
+
+// getSignature returns the types of the arguments and results of function, like 'name(string, int) (string, error)'.
+func getSignature(name string, function interface{}) string {
+	signature := strings.TrimPrefix(reflect.TypeOf(function).String(), "func")
+	return name + strings.ReplaceAll(signature, "interface {}", "interface{}")
+}
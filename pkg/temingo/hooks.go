@@ -0,0 +1,70 @@
+package temingo
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// buildWithHooks runs the preBuild commands, the build and - if it succeeded - the postBuild commands, in that order.
+// A failing preBuild command aborts the build, a failing postBuild command fails it.
+func (engine *Engine) buildWithHooks(build func() error) error {
+	if err := engine.runHooks("preBuild", engine.PreBuild); err != nil {
+		return err
+	}
+	if err := build(); err != nil {
+		return err
+	}
+	return engine.runHooks("postBuild", engine.PostBuild)
+}
+
+// runHooks runs the commands one after the other via the shell of the platform. Their output is logged line by line, as it's written.
+func (engine *Engine) runHooks(kind string, commands []string) error {
+	for _, command := range commands {
+		engine.logInfo("*** Running the " + kind + " command '" + command + "' ... ***")
+		var cmd *exec.Cmd
+		if runtime.GOOS == "windows" {
+			cmd = exec.Command("cmd", "/C", command)
+		} else {
+			cmd = exec.Command("sh", "-c", command)
+		}
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return err
+		}
+		stderr, err := cmd.StderrPipe()
+		if err != nil {
+			return err
+		}
+		if err := cmd.Start(); err != nil {
+			return errors.New("The " + kind + " command '" + command + "' could not be started: " + err.Error())
+		}
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go engine.logHookOutput(kind, stdout, &wg)
+		go engine.logHookOutput(kind, stderr, &wg)
+		wg.Wait() // the pipes have to be read completely before waiting for the command
+		if err := cmd.Wait(); err != nil {
+			if exitError, ok := err.(*exec.ExitError); ok {
+				return errors.New("The " + kind + " command '" + command + "' failed with exit code " + strconv.Itoa(exitError.ExitCode()) + ".")
+			}
+			return errors.New("The " + kind + " command '" + command + "' failed: " + err.Error())
+		}
+	}
+	return nil
+}
+
+// logHookOutput logs each line of the output of a hook command, with the kind of the hook as field in json logs.
+func (engine *Engine) logHookOutput(kind string, output io.Reader, wg *sync.WaitGroup) {
+	defer wg.Done()
+	scanner := bufio.NewScanner(output)
+	for scanner.Scan() {
+		engine.logMessage(levelInfo, map[string]interface{}{"hook": kind}, "["+kind+"] "+scanner.Text())
+	}
+	io.Copy(ioutil.Discard, output) // f.e. after a line too long to be logged, so the command isn't blocked
+}
@@ -0,0 +1,169 @@
+package temingo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var languageRegexp = regexp.MustCompile(`^[a-zA-Z]{2,3}([-_][a-zA-Z0-9]+)?$`) // f.e. 'de', 'en-US' or 'zh_Hant'
+
+// getLanguages returns the languages the site is rendered in. Without configured languages, that's a single unnamed one.
+func (engine *Engine) getLanguages() []string {
+	if len(engine.Languages) == 0 {
+		return []string{""}
+	}
+	return engine.Languages
+}
+
+// getLanguagePrefix returns the folder the outputs of the language currently rendered are written to, relative to the outputDir.
+// The first language is the default one, which is written to the outputDir itself.
+func (engine *Engine) getLanguagePrefix() string {
+	if engine.language == "" || engine.language == engine.Languages[0] {
+		return ""
+	}
+	return engine.language
+}
+
+// getFileLanguage returns the language of a template or markdown file, which is the last part of its name before the extension, f.e. 'de' for 'about.html.de.template' and 'post.de.md'.
+// Returns an empty string for files without language, which are rendered for all languages.
+func (engine *Engine) getFileLanguage(name string, extension string) string {
+	outputName := strings.TrimSuffix(name, extension)
+	for _, language := range engine.Languages {
+		if strings.HasSuffix(outputName, "."+language) {
+			return language
+		}
+	}
+	return ""
+}
+
+// trimLanguage removes the language from the name of a template or markdown file without extension, f.e. 'about.html.de' to 'about.html', so all languages share the same output paths.
+func (engine *Engine) trimLanguage(outputName string) string {
+	for _, language := range engine.Languages {
+		if strings.HasSuffix(outputName, "."+language) {
+			return strings.TrimSuffix(outputName, "."+language)
+		}
+	}
+	return outputName
+}
+
+// filterLanguageFiles returns the files which are rendered for the current language: the ones of the language and the ones without language, unless there is a file of the language replacing it.
+func (engine *Engine) filterLanguageFiles(files [][]string, extension string) [][]string {
+	if len(engine.Languages) == 0 {
+		return files
+	}
+	replaced := make(map[string]bool)
+	for _, file := range files {
+		if engine.getFileLanguage(file[0], extension) == engine.language {
+			replaced[engine.trimLanguage(strings.TrimSuffix(file[0], extension))] = true
+		}
+	}
+	filtered := [][]string{}
+	for _, file := range files {
+		language := engine.getFileLanguage(file[0], extension)
+		if language == engine.language || (language == "" && !replaced[strings.TrimSuffix(file[0], extension)]) {
+			filtered = append(filtered, file)
+		}
+	}
+	return filtered
+}
+
+// getLanguageValuesFilePath returns the path of the values of the current language which override the ones of valuesFilePath, f.e. 'values.de.yaml' for 'values.yaml'.
+func (engine *Engine) getLanguageValuesFilePath(valuesFilePath string) string {
+	extension := path.Ext(valuesFilePath)
+	return strings.TrimSuffix(valuesFilePath, extension) + "." + engine.language + extension
+}
+
+// getLanguageExclusions returns the files of all languages which are no content, namely the translations and the values files of the languages.
+func (engine *Engine) getLanguageExclusions() []string {
+	if len(engine.Languages) == 0 {
+		return []string{}
+	}
+	exclusions := []string{"/" + path.Join(engine.TranslationsDir, "**")}
+	for _, language := range engine.Languages {
+		for _, valuesFilePath := range engine.ValuesFilePaths {
+			extension := path.Ext(valuesFilePath)
+			exclusions = append(exclusions, "/"+strings.TrimSuffix(path.Clean(valuesFilePath), extension)+"."+language+extension)
+		}
+	}
+	return exclusions
+}
+
+// loadTranslations reads the translations of the current language and the ones of the default language, which are used for missing keys.
+// They are the yaml files named after the language in the translationsDir, f.e. 'i18n/de.yaml'.
+func (engine *Engine) loadTranslations() error {
+	engine.translations = []map[string]interface{}{}
+	if engine.language == "" {
+		return nil
+	}
+	for _, language := range []string{engine.language, engine.Languages[0]} {
+		translationsFilePath := path.Join(engine.TranslationsDir, language+".yaml")
+		if _, err := os.Stat(translationsFilePath); os.IsNotExist(err) {
+			continue
+		}
+		translations, err := loadYaml(translationsFilePath)
+		if err != nil {
+			return err
+		}
+		engine.translations = append(engine.translations, translations)
+	}
+	return nil
+}
+
+// translate returns the translation of key in the current language, f.e. 'T "nav.home"' for the 'home' value of 'nav'. If there are args, the translation is used as format for them, f.e. 'T "greeting" .Values.name'.
+// Missing translations fall back to the default language, and to the key itself with a warning.
+func (engine *Engine) translate(key string, args ...interface{}) (string, error) {
+	if engine.language == "" {
+		return "", errors.New("T: the site has no languages, set them via '--languages'")
+	}
+	for _, translations := range engine.translations {
+		var value interface{} = translations
+		for _, part := range strings.Split(key, ".") {
+			values, ok := value.(map[string]interface{})
+			if !ok {
+				value = nil
+				break
+			}
+			value = values[part]
+		}
+		if value == nil {
+			continue
+		}
+		if _, ok := value.(map[string]interface{}); ok {
+			return "", errors.New("T: '" + key + "' contains several translations instead of a single one")
+		}
+		if len(args) > 0 {
+			return fmt.Sprintf(toString(value), args...), nil
+		}
+		return toString(value), nil
+	}
+	engine.logWarn("There is no translation of '" + key + "' for the language '" + engine.language + "'.")
+	return key, nil
+}
+
+// getLanguagePath returns the site-relative pagePath in the current language, f.e. '/de/blog/' for '/blog/' while rendering 'de'.
+func (engine *Engine) getLanguagePath(pagePath string) string {
+	languagePath := path.Join("/", engine.getLanguagePrefix(), pagePath)
+	if strings.HasSuffix(pagePath, "/") && languagePath != "/" { // path.Join removes the trailing slash of folders
+		languagePath += "/"
+	}
+	return languagePath
+}
+
+// validateLanguages checks the languages are valid language codes and unique.
+func (engine *Engine) validateLanguages() error {
+	seen := make(map[string]bool)
+	for _, language := range engine.Languages {
+		if !languageRegexp.MatchString(language) {
+			return errors.New("The language '" + language + "' is no valid language code like 'en' or 'en-US'")
+		}
+		if seen[language] {
+			return errors.New("The language '" + language + "' is configured more than once")
+		}
+		seen[language] = true
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package temingo
+
+import (
+	"path"
+	"strings"
+	"time"
+)
+
+const calendarConfigFileName = "calendar.yaml"
+
+func init() {
+	configFileNames = append(configFileNames, calendarConfigFileName)
+}
+
+// calendarConfig is the content of a 'calendar.yaml' file, which marks its folder as collection of events.
+type calendarConfig struct {
+	Title      string `yaml:"title"`
+	Output     string `yaml:"output"`     // file name of the aggregate calendar, relative to the collection in the outputDir
+	ItemOutput string `yaml:"itemOutput"` // file name of the per-item calendar, relative to the item in the outputDir
+}
+
+// exportCalendars creates iCalendar files for each collection in the inputDir that contains a 'calendar.yaml'.
+// Each item with a 'date' value becomes an event, which is written to its own file and to the aggregate calendar of the collection.
+// Optional item values are 'end', 'title', 'description' and 'location'.
+func (engine *Engine) exportCalendars() error {
+	configPaths, err := engine.getConfigFiles(calendarConfigFileName)
+	if err != nil {
+		return err
+	}
+	for _, configPath := range configPaths {
+		collectionPath := path.Dir(configPath)
+		if !engine.isChangedCollection(collectionPath) {
+			continue
+		}
+
+		config := calendarConfig{}
+		if err := loadConfigFile(configPath, &config); err != nil {
+			return err
+		}
+		if config.Title == "" {
+			config.Title = path.Base(collectionPath)
+		}
+		if config.Output == "" {
+			config.Output = "calendar.ics"
+		}
+		if config.ItemOutput == "" {
+			config.ItemOutput = "event.ics"
+		}
+
+		engine.logDebug("*** Exporting events of '" + collectionPath + "' to iCalendar ... ***")
+
+		items, err := engine.getSortedListObjects(collectionPath, "date", false)
+		if err != nil {
+			return err
+		}
+		events := []string{}
+		for _, item := range items {
+			itemPath := strings.TrimPrefix(toString(item["Path"]), "/")
+			event, ok := createCalendarEvent(item)
+			if !ok {
+				engine.logDebug("Skipping '" + itemPath + "' for the calendar, as it has no valid 'date' value.")
+				continue
+			}
+			events = append(events, event)
+
+			itemOutputFilePath, err := engine.getOutputFilePath(itemPath, config.ItemOutput)
+			if err != nil {
+				return err
+			}
+			err = engine.writeTemplateToFile(itemOutputFilePath, []byte(createCalendar(toString(item["title"]), []string{event})))
+			if err != nil {
+				return err
+			}
+		}
+
+		outputFilePath, err := engine.getOutputFilePath(collectionPath, config.Output)
+		if err != nil {
+			return err
+		}
+		err = engine.writeTemplateToFile(outputFilePath, []byte(createCalendar(config.Title, events)))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func createCalendar(name string, events []string) string {
+	calendar := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"PRODID:-//temingo//temingo//EN\r\n" +
+		"CALSCALE:GREGORIAN\r\n"
+	if name != "" {
+		calendar += foldCalendarLine("X-WR-CALNAME:" + escapeCalendarText(name))
+	}
+	for _, event := range events {
+		calendar += event
+	}
+	return calendar + "END:VCALENDAR\r\n"
+}
+
+// createCalendarEvent returns the VEVENT for the item, and false if the item has no valid date. Its DTSTAMP is the start of the event, so the output is reproducible.
+func createCalendarEvent(item map[string]interface{}) (string, bool) {
+	start, ok := toTime(item["date"])
+	if !ok {
+		return "", false
+	}
+	end, hasEnd := toTime(item["end"])
+	allDay := isDate(start) && (!hasEnd || isDate(end))
+
+	event := "BEGIN:VEVENT\r\n" +
+		foldCalendarLine("UID:"+escapeCalendarText(strings.TrimPrefix(toString(item["Path"]), "/")+"@temingo")) +
+		"DTSTAMP:" + start.UTC().Format("20060102T150405Z") + "\r\n"
+	if allDay {
+		if !hasEnd {
+			end = start
+		}
+		end = end.AddDate(0, 0, 1) // the 'end' value is the last day of the event, while DTEND is exclusive
+		event += "DTSTART;VALUE=DATE:" + start.Format("20060102") + "\r\n" +
+			"DTEND;VALUE=DATE:" + end.Format("20060102") + "\r\n"
+	} else {
+		event += "DTSTART:" + start.UTC().Format("20060102T150405Z") + "\r\n"
+		if hasEnd {
+			event += "DTEND:" + end.UTC().Format("20060102T150405Z") + "\r\n"
+		}
+	}
+	for _, property := range [][]string{{"SUMMARY", "title"}, {"DESCRIPTION", "description"}, {"LOCATION", "location"}} {
+		if value := toString(item[property[1]]); value != "" {
+			event += foldCalendarLine(property[0] + ":" + escapeCalendarText(value))
+		}
+	}
+	return event + "END:VEVENT\r\n", true
+}
+
+func isDate(t time.Time) bool {
+	return t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0
+}
+
+func escapeCalendarText(text string) string {
+	return strings.NewReplacer("\\", "\\\\", ";", "\\;", ",", "\\,", "\r\n", "\\n", "\n", "\\n").Replace(text)
+}
+
+// foldCalendarLine terminates the line and splits it into lines of at most 75 bytes, as required by RFC 5545.
+// The space continuation lines start with counts towards their length, so their chunks are at most 74 bytes.
+func foldCalendarLine(line string) string {
+	folded := ""
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 { // don't split utf-8 characters
+			cut--
+		}
+		folded += line[:cut] + "\r\n "
+		line = line[cut:]
+		limit = 74
+	}
+	return folded + line + "\r\n"
+}
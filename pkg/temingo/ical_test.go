@@ -0,0 +1,31 @@
+package temingo
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestCreateCalendarEventAllDay checks the exclusive DTEND of all-day events, for which the 'end' value is the last day of the event.
+func TestCreateCalendarEventAllDay(t *testing.T) {
+	tests := []struct {
+		name  string
+		item  map[string]interface{}
+		start string
+		end   string
+	}{
+		{"single day", map[string]interface{}{"Path": "/events/a", "date": "2026-05-01"}, "20260501", "20260502"},
+		{"several days", map[string]interface{}{"Path": "/events/b", "date": "2026-05-01", "end": "2026-05-03"}, "20260501", "20260504"},
+	}
+	for _, test := range tests {
+		event, ok := createCalendarEvent(test.item)
+		if !ok {
+			t.Fatalf("%s: no event was created for %v", test.name, test.item)
+		}
+		if !strings.Contains(event, "DTSTART;VALUE=DATE:"+test.start+"\r\n") {
+			t.Errorf("%s: the event doesn't start on %s:\n%s", test.name, test.start, event)
+		}
+		if !strings.Contains(event, "DTEND;VALUE=DATE:"+test.end+"\r\n") {
+			t.Errorf("%s: the event doesn't end before %s:\n%s", test.name, test.end, event)
+		}
+	}
+}
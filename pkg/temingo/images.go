@@ -0,0 +1,180 @@
+package temingo
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // only decoding, so webp images can be converted
+)
+
+// imageCacheDir is the folder in the cacheDir processed images are cached in, so unchanged images aren't processed again by later builds.
+var imageCacheDir = path.Join(cacheDir, "images")
+
+// imageFormats are the formats processed images can be written in, by the extension of their file name.
+var imageFormats = map[string]string{".jpg": "jpeg", ".jpeg": "jpeg", ".png": "png", ".gif": "gif"}
+
+// imageProcessing is what is done to an image by one of the image template functions.
+type imageProcessing struct {
+	width  int    // the width of the processed image, 0 to keep the aspect ratio
+	height int    // the height of the processed image, 0 to keep the aspect ratio
+	crop   bool   // whether the image is scaled to cover width and height, and cut off at the sides which don't fit
+	format string // the extension of the processed image, f.e. '.png'
+}
+
+// imageResize is the 'imageResize' template function, which scales the image to width and height and returns the site-relative path of the result.
+// If one of them is 0 or missing, it's calculated from the aspect ratio, f.e. 'imageResize "photos/hero.jpg" 800'.
+func (engine *Engine) imageResize(imagePath string, width int, height ...int) (string, error) {
+	processing := imageProcessing{width: width, format: path.Ext(imagePath)}
+	if len(height) > 0 {
+		processing.height = height[0]
+	}
+	if processing.width <= 0 && processing.height <= 0 {
+		return "", errors.New("imageResize: '" + imagePath + "' requires a width or a height greater than 0")
+	}
+	return engine.processImage("imageResize", imagePath, processing)
+}
+
+// imageCrop is the 'imageCrop' template function, which scales the image to cover width and height, cuts off what doesn't fit at the sides equally and returns the site-relative path of the result.
+// F.e. 'imageCrop "photos/hero.jpg" 400 400' for a square thumbnail.
+func (engine *Engine) imageCrop(imagePath string, width int, height int) (string, error) {
+	if width <= 0 || height <= 0 {
+		return "", errors.New("imageCrop: '" + imagePath + "' requires a width and a height greater than 0")
+	}
+	return engine.processImage("imageCrop", imagePath, imageProcessing{width: width, height: height, crop: true, format: path.Ext(imagePath)})
+}
+
+// imageConvert is the 'imageConvert' template function, which converts the image to format ('jpg', 'png' or 'gif') and returns the site-relative path of the result.
+// F.e. 'imageConvert "photos/hero.webp" "jpg"'.
+func (engine *Engine) imageConvert(imagePath string, format string) (string, error) {
+	return engine.processImage("imageConvert", imagePath, imageProcessing{format: "." + strings.TrimPrefix(strings.ToLower(format), ".")})
+}
+
+// processImage writes the image at imagePath, processed as requested, to the outputDir and returns its site-relative path.
+// The image is looked up in the staticDir first and in the inputDir second. The processed image is written next to where the original is copied to, with a hash of the original and the processing in its file name, f.e. 'photos/hero.3fa9c2d1.jpg'.
+// Processed images are cached in the cacheDir, so they are only processed again once the original or the processing changes.
+func (engine *Engine) processImage(function string, imagePath string, processing imageProcessing) (string, error) {
+	imagePath = path.Clean(strings.TrimPrefix(imagePath, "/"))
+	if imagePath == ".." || strings.HasPrefix(imagePath, "../") {
+		return "", errors.New(function + ": the image '" + imagePath + "' must not point outside of the static-dir or input-dir")
+	}
+	if _, ok := imageFormats[strings.ToLower(processing.format)]; !ok {
+		return "", errors.New(function + ": '" + imagePath + "' can't be written as '" + processing.format + "', the supported formats are jpg, png and gif")
+	}
+	processing.format = strings.ToLower(processing.format)
+
+	sourcePath := path.Join(engine.StaticDir, imagePath)
+	if info, err := os.Stat(sourcePath); err != nil || info.IsDir() {
+		sourcePath = path.Join(engine.InputDir, imagePath)
+		if info, err := os.Stat(sourcePath); err != nil || info.IsDir() {
+			return "", errors.New(function + ": the image '" + imagePath + "' does not exist in '" + engine.StaticDir + "' or '" + engine.InputDir + "'")
+		}
+	}
+	content, err := ioutil.ReadFile(sourcePath)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(append(content, fmt.Sprintf("%dx%d,crop=%t,quality=%d", processing.width, processing.height, processing.crop, engine.ImageQuality)...))
+	hashString := hex.EncodeToString(hash[:])
+	processedPath := strings.TrimSuffix(imagePath, path.Ext(imagePath)) + "." + hashString[:8] + processing.format
+
+	engine.lock.Lock()
+	engine.imageSources[sourcePath] = true
+	processed := engine.processedImages[processedPath]
+	engine.processedImages[processedPath] = true // other outputs using the same image don't have to write it again
+	engine.lock.Unlock()
+	if processed {
+		return "/" + processedPath, nil
+	}
+
+	cacheFilePath := path.Join(imageCacheDir, hashString+processing.format)
+	output, err := ioutil.ReadFile(cacheFilePath)
+	if err != nil {
+		engine.logDebug("Processing image '" + sourcePath + "' to '" + processedPath + "' ...")
+		output, err = engine.encodeProcessedImage(content, processing)
+		if err != nil {
+			return "", errors.New(function + ": could not process the image '" + sourcePath + "': " + err.Error())
+		}
+		engine.createFolderIfNotExists(imageCacheDir)
+		if err := ioutil.WriteFile(cacheFilePath, output, engine.fileMode); err != nil {
+			return "", err
+		}
+	}
+	if err := engine.writeTemplateToFile(path.Join(engine.OutputDir, processedPath), output); err != nil {
+		return "", err
+	}
+	return "/" + processedPath, nil
+}
+
+// encodeProcessedImage decodes the image content, scales and crops it as requested and returns it encoded in the requested format.
+func (engine *Engine) encodeProcessedImage(content []byte, processing imageProcessing) ([]byte, error) {
+	source, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	bounds := source.Bounds()
+	width, height := processing.width, processing.height
+	if width <= 0 && height <= 0 { // only converted
+		width, height = bounds.Dx(), bounds.Dy()
+	} else if width <= 0 {
+		width = (bounds.Dx()*height + bounds.Dy()/2) / bounds.Dy()
+	} else if height <= 0 {
+		height = (bounds.Dy()*width + bounds.Dx()/2) / bounds.Dx()
+	}
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	sourceRect := bounds
+	if processing.crop { // the largest centered part of the source with the requested aspect ratio
+		if bounds.Dx()*height > bounds.Dy()*width {
+			croppedWidth := bounds.Dy() * width / height
+			sourceRect.Min.X += (bounds.Dx() - croppedWidth) / 2
+			sourceRect.Max.X = sourceRect.Min.X + croppedWidth
+		} else {
+			croppedHeight := bounds.Dx() * height / width
+			sourceRect.Min.Y += (bounds.Dy() - croppedHeight) / 2
+			sourceRect.Max.Y = sourceRect.Min.Y + croppedHeight
+		}
+	}
+
+	processed := image.NewRGBA(image.Rect(0, 0, width, height))
+	if imageFormats[processing.format] == "jpeg" { // jpeg has no transparency, which would become black otherwise
+		draw.Draw(processed, processed.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	}
+	draw.CatmullRom.Scale(processed, processed.Bounds(), source, sourceRect, draw.Over, nil)
+
+	output := new(bytes.Buffer)
+	switch imageFormats[processing.format] {
+	case "jpeg":
+		err = jpeg.Encode(output, processed, &jpeg.Options{Quality: engine.ImageQuality})
+	case "png":
+		err = png.Encode(output, processed)
+	case "gif":
+		err = gif.Encode(output, processed, nil)
+	}
+	return output.Bytes(), err
+}
+
+// isProcessedImage returns whether the file at filePath is the original of an image processed by one of the image template functions.
+func (engine *Engine) isProcessedImage(filePath string) bool {
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+	return engine.imageSources[path.Clean(filePath)]
+}
@@ -0,0 +1,529 @@
+package temingo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// importDir is the folder the layouts of an imported site are copied to, as they have to be ported manually. It's added to the ignore file, so it's neither rendered nor copied.
+const importDir = "imported"
+
+// ImportSources are the static site generators Import can convert sites from.
+var ImportSources = []string{"hugo", "jekyll"}
+
+var (
+	hugoShortcodeRegexp = regexp.MustCompile(`{{[<%]`)
+	liquidRegexp        = regexp.MustCompile(`{%|{{`)
+	jekyllPostRegexp    = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})-(.+)$`)
+)
+
+// importer converts the site in sourceDir into the layout of the engine and collects the constructs which need manual attention.
+type importer struct {
+	engine    *Engine
+	sourceDir string
+	values    map[string]interface{}
+	layouts   map[string]bool // names of the layouts used by the imported content, which get a placeholder partial
+	notes     []string
+
+	enabledMarkdown bool // whether markdown content was disabled, so the project config has to enable it for the imported content
+}
+
+// Import converts the site of another static site generator ('hugo' or 'jekyll') in sourceDir into the folders of the engine.
+// Content files keep their front matter (converted to yaml) and their urls, static files are copied as they are and the config becomes the values file.
+// The layouts can't be converted automatically, so they are copied to 'imported' and each of them is listed with the partial it corresponds to. Until they are ported, the content is rendered with placeholder layouts.
+// Existing files are never overwritten. Everything that needs manual attention is logged at the end.
+func (engine *Engine) Import(from string, sourceDir string) error {
+	if err := engine.parseModes(); err != nil {
+		return err
+	}
+	if info, err := os.Stat(sourceDir); err != nil || !info.IsDir() {
+		return errors.New("The site to import does not exist or is not a directory: " + sourceDir)
+	}
+	imp := &importer{engine: engine, sourceDir: path.Clean(sourceDir), values: make(map[string]interface{}), layouts: map[string]bool{path.Base(engine.MarkdownLayout): true}}
+	if engine.MarkdownExtension == "" { // the content is imported as markdown files
+		engine.MarkdownExtension = ".md"
+		imp.enabledMarkdown = true
+	}
+
+	var err error
+	switch from {
+	case "hugo":
+		err = imp.importHugo()
+	case "jekyll":
+		err = imp.importJekyll()
+	default:
+		return errors.New("Can't import from '" + from + "', it must be one of '" + strings.Join(ImportSources, "', '") + "'.")
+	}
+	if err != nil {
+		return err
+	}
+	if err := imp.writeProjectFiles(); err != nil {
+		return err
+	}
+
+	if len(imp.notes) > 0 {
+		imp.engine.logInfo("*** The following needs manual attention: ***\n- " + strings.Join(imp.notes, "\n- "))
+	}
+	imp.engine.logInfo("*** Imported the " + from + " site from '" + sourceDir + "'. ***")
+	return nil
+}
+
+// importHugo converts 'content', 'static', 'assets', 'data', 'layouts' and the config of a hugo site.
+// Pages are converted to 'index.md' files of their own folder, so they keep the pretty urls of hugo.
+func (imp *importer) importHugo() error {
+	for _, fileName := range []string{"hugo.toml", "hugo.yaml", "hugo.json", "config.toml", "config.yaml", "config.yml", "config.json"} {
+		configPath := path.Join(imp.sourceDir, fileName)
+		if _, err := os.Stat(configPath); err != nil {
+			continue
+		}
+		config, err := loadDataFile(configPath)
+		if err != nil {
+			return err
+		}
+		for _, key := range sortedKeys(config) { // so the notes are always in the same order
+			value := config[key]
+			switch strings.ToLower(key) {
+			case "title":
+				imp.values["title"] = value
+			case "baseurl":
+				imp.values["baseURL"] = value
+			case "languagecode":
+				imp.values["language"] = value
+			case "params": // '.Site.Params.x' becomes '.Values.x'
+				if params, ok := value.(map[string]interface{}); ok {
+					for paramKey, paramValue := range params {
+						imp.values[paramKey] = paramValue
+					}
+				}
+			case "menu", "menus":
+				imp.values["menus"] = value
+			default:
+				imp.note("The setting '" + key + "' of '" + configPath + "' has no equivalent in temingo and was left out.")
+			}
+		}
+		break
+	}
+
+	err := imp.walk("content", func(relativePath string, content []byte) error {
+		if !isImportedMarkdown(relativePath) {
+			return imp.writeFile(path.Join(imp.engine.InputDir, relativePath), content) // resources of page bundles
+		}
+		fileName := strings.TrimSuffix(path.Base(relativePath), path.Ext(relativePath))
+		targetPath := path.Join(path.Dir(relativePath), fileName, "index")
+		switch fileName {
+		case "index": // a page bundle, which already has its own folder
+			targetPath = path.Join(path.Dir(relativePath), "index")
+		case "_index": // the list page of a section
+			targetPath = path.Join(path.Dir(relativePath), "index")
+			imp.note("'content/" + relativePath + "' is the list page of a section. Its items can be listed via 'pages' or 'list' in its layout.")
+		}
+		return imp.writeContent("content/"+relativePath, targetPath, content, hugoShortcodeRegexp, "hugo shortcodes", nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, staticDir := range []string{"static", "assets"} {
+		err := imp.walk(staticDir, func(relativePath string, content []byte) error {
+			return imp.writeFile(path.Join(imp.engine.StaticDir, relativePath), content)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := os.Stat(path.Join(imp.sourceDir, "assets")); err == nil {
+		imp.note("The files of 'assets' were copied to '" + imp.engine.StaticDir + "'. Hugo pipes like 'resources.Get' are replaced by the 'asset' function, '--fingerprint', '--minifyStatic' and the compilation of Sass stylesheets.")
+	}
+
+	if err := imp.importData("data"); err != nil {
+		return err
+	}
+
+	return imp.importLayouts("layouts", func(relativePath string) string {
+		name := strings.TrimSuffix(relativePath, path.Ext(relativePath))
+		switch {
+		case strings.HasPrefix(name, "partials/"):
+			return "the partial '" + strings.TrimPrefix(name, "partials/") + "'"
+		case strings.HasPrefix(name, "shortcodes/"):
+			return "a partial used via 'include', as markdown content isn't templated"
+		case name == "index":
+			return "the template 'index.html" + imp.engine.TemplateExtension + "'"
+		case name == "_default/single" || name == "_default/baseof":
+			return "the partial '" + imp.engine.MarkdownLayout + "'"
+		case strings.HasSuffix(name, "/single"):
+			return "the partial 'layouts/" + strings.TrimSuffix(name, "/single") + "', set as 'layout' in the '_index.yaml' of the section"
+		case strings.HasSuffix(name, "/list"):
+			return "a list in the layout of the 'index.md' of the section"
+		}
+		return "no direct equivalent"
+	})
+}
+
+// importJekyll converts the posts, drafts, pages, '_data', '_layouts', '_includes', the other files and the '_config.yml' of a jekyll site.
+// Posts are converted to '<year>/<month>/<day>/<title>.md' and keep the default urls of jekyll that way.
+func (imp *importer) importJekyll() error {
+	configPath := path.Join(imp.sourceDir, "_config.yml")
+	if _, err := os.Stat(configPath); err == nil {
+		config, err := loadDataFile(configPath)
+		if err != nil {
+			return err
+		}
+		for _, key := range sortedKeys(config) { // so the notes are always in the same order
+			value := config[key]
+			switch key {
+			case "url":
+				imp.values["baseURL"] = strings.TrimSuffix(toString(value), "/") + toString(config["baseurl"])
+			case "baseurl":
+			case "permalink", "collections", "plugins", "theme", "exclude", "include", "markdown", "kramdown", "sass", "paginate", "paginate_path", "defaults":
+				imp.note("The setting '" + key + "' of '" + configPath + "' has no direct equivalent in temingo and was left out.")
+			default: // 'site.x' becomes '.Values.x'
+				imp.values[key] = value
+			}
+		}
+	}
+
+	for _, postsDir := range []string{"_posts", "_drafts"} {
+		draft := postsDir == "_drafts"
+		err := imp.walk(postsDir, func(relativePath string, content []byte) error {
+			if !isImportedMarkdown(relativePath) {
+				return imp.writeFile(path.Join(imp.engine.StaticDir, relativePath), content)
+			}
+			fileName := strings.TrimSuffix(path.Base(relativePath), path.Ext(relativePath))
+			targetPath := path.Join("drafts", fileName)
+			date := ""
+			if match := jekyllPostRegexp.FindStringSubmatch(fileName); match != nil {
+				targetPath = path.Join(match[1], match[2], match[3], match[4])
+				date = match[1] + "-" + match[2] + "-" + match[3]
+			}
+			return imp.writeContent(postsDir+"/"+relativePath, targetPath, content, liquidRegexp, "liquid tags", func(frontMatter map[string]interface{}) {
+				if _, ok := frontMatter["date"]; !ok && date != "" {
+					frontMatter["date"] = date
+				}
+				if draft {
+					frontMatter["draft"] = true
+				}
+				if _, ok := frontMatter["categories"]; ok {
+					imp.note("'" + postsDir + "/" + relativePath + "' has categories, which aren't part of its url in temingo.")
+				}
+			})
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	err := filepath.Walk(imp.sourceDir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relativePath, err := filepath.Rel(imp.sourceDir, filePath)
+		if err != nil || relativePath == "." {
+			return err
+		}
+		relativePath = filepath.ToSlash(relativePath)
+		name := info.Name()
+		if strings.HasPrefix(name, "_") || strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules" || name == "Gemfile" || name == "Gemfile.lock" {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+		content, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		_, body, hasFrontMatter, _ := splitImportedFrontMatter(content)
+		switch {
+		case hasFrontMatter && isImportedMarkdown(relativePath): // like jekyll, only files with front matter are processed
+			return imp.writeContent(relativePath, strings.TrimSuffix(relativePath, path.Ext(relativePath)), content, liquidRegexp, "liquid tags", nil)
+		case hasFrontMatter && isSassFile(relativePath): // the front matter is usually empty, it only marks the stylesheet for compilation
+			return imp.writeFile(path.Join(imp.engine.StaticDir, relativePath), body)
+		case hasFrontMatter:
+			imp.note("'" + relativePath + "' is processed by liquid in jekyll. It was copied to '" + path.Join(importDir, relativePath) + "' and has to be ported to a template.")
+			return imp.writeFile(path.Join(importDir, relativePath), content)
+		}
+		return imp.writeFile(path.Join(imp.engine.StaticDir, relativePath), content)
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path.Join(imp.sourceDir, "_sass")); err == nil {
+		err := imp.walk("_sass", func(relativePath string, content []byte) error {
+			return imp.writeFile(path.Join(imp.engine.StaticDir, "_sass", relativePath), content)
+		})
+		if err != nil {
+			return err
+		}
+		imp.note("The stylesheets of '_sass' were copied to '" + path.Join(imp.engine.StaticDir, "_sass") + "'. Their imports have to be adjusted to the new path.")
+	}
+
+	if err := imp.importData("_data"); err != nil {
+		return err
+	}
+
+	err = imp.importLayouts("_layouts", func(relativePath string) string {
+		return "the partial 'layouts/" + strings.TrimSuffix(relativePath, path.Ext(relativePath)) + "'"
+	})
+	if err != nil {
+		return err
+	}
+	return imp.importLayouts("_includes", func(relativePath string) string {
+		return "the partial '" + strings.TrimSuffix(relativePath, path.Ext(relativePath)) + "'"
+	})
+}
+
+// importData adds the yaml, json and toml files of dataDir to the values, f.e. 'data/authors.yaml' as '.Values.data.authors'.
+func (imp *importer) importData(dataDir string) error {
+	data := make(map[string]interface{})
+	err := imp.walk(dataDir, func(relativePath string, content []byte) error {
+		extension := path.Ext(relativePath)
+		if extension != ".yaml" && extension != ".yml" && extension != ".json" && extension != ".toml" {
+			imp.note("The data file '" + dataDir + "/" + relativePath + "' has an unsupported format and was left out.")
+			return nil
+		}
+		values, err := loadDataFile(path.Join(imp.sourceDir, dataDir, relativePath))
+		if err != nil {
+			return err
+		}
+		parent := data
+		keys := strings.Split(strings.TrimSuffix(relativePath, extension), "/")
+		for _, key := range keys[:len(keys)-1] { // subfolders become nested values
+			child, ok := parent[key].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				parent[key] = child
+			}
+			parent = child
+		}
+		parent[keys[len(keys)-1]] = values
+		return nil
+	})
+	if len(data) > 0 {
+		imp.values["data"] = data
+	}
+	return err
+}
+
+// importLayouts copies the files of layoutsDir to the importDir and notes which temingo file each of them corresponds to, as returned by mapping.
+func (imp *importer) importLayouts(layoutsDir string, mapping func(relativePath string) string) error {
+	return imp.walk(layoutsDir, func(relativePath string, content []byte) error {
+		imp.note("The layout '" + layoutsDir + "/" + relativePath + "' was copied to '" + path.Join(importDir, layoutsDir, relativePath) + "'. It corresponds to " + mapping(relativePath) + ".")
+		return imp.writeFile(path.Join(importDir, layoutsDir, relativePath), content)
+	})
+}
+
+// writeContent writes a markdown content file to targetPath (relative to the inputDir, without extension), with its front matter converted to yaml.
+// Its 'layout' is prefixed with 'layouts/' and gets a placeholder partial. Template constructs of the other generator, matched by unsupported, are noted.
+func (imp *importer) writeContent(sourcePath string, targetPath string, content []byte, unsupported *regexp.Regexp, unsupportedName string, transform func(frontMatter map[string]interface{})) error {
+	frontMatter, body, _, err := splitImportedFrontMatter(content)
+	if err != nil {
+		return errors.New("Could not parse the front matter of '" + sourcePath + "': " + err.Error())
+	}
+	if transform != nil {
+		transform(frontMatter)
+	}
+	if published, ok := frontMatter["published"].(bool); ok && !published {
+		frontMatter["draft"] = true
+		delete(frontMatter, "published")
+	}
+	if layout, ok := frontMatter["layout"]; ok {
+		imp.layouts[toString(layout)] = true
+		frontMatter["layout"] = "layouts/" + toString(layout)
+	}
+	for _, key := range []string{"url", "slug", "permalink", "aliases"} {
+		if _, ok := frontMatter[key]; ok {
+			imp.note("'" + sourcePath + "' sets '" + key + "', but temingo renders it to its path in '" + imp.engine.InputDir + "' only.")
+		}
+	}
+	if unsupported.Match(body) {
+		imp.note("'" + sourcePath + "' contains " + unsupportedName + ", but markdown content isn't templated in temingo.")
+	}
+
+	output := body
+	if len(frontMatter) > 0 {
+		frontMatterYaml, err := marshalYaml(frontMatter)
+		if err != nil {
+			return err
+		}
+		output = append(append([]byte(frontMatterDelimiter+"\n"), frontMatterYaml...), append([]byte(frontMatterDelimiter+"\n"), body...)...)
+	}
+	return imp.writeFile(path.Join(imp.engine.InputDir, targetPath+imp.engine.MarkdownExtension), output)
+}
+
+// writeProjectFiles writes the values file, a placeholder partial for each used layout and an ignore file excluding the importDir.
+func (imp *importer) writeProjectFiles() error {
+	valuesYaml, err := marshalYaml(imp.values)
+	if err != nil {
+		return err
+	}
+	if len(imp.values) == 0 {
+		valuesYaml = []byte{}
+	}
+	if err := imp.writeFile(imp.engine.ValuesFilePaths[0], valuesYaml); err != nil {
+		return err
+	}
+	if imp.enabledMarkdown {
+		if configFilePath := FindConfigFile(); configFilePath != "" {
+			imp.note("The content was imported as markdown files, set 'markdownExtension: " + imp.engine.MarkdownExtension + "' in '" + configFilePath + "' to render them.")
+		} else if err := imp.writeFile(ConfigFileNames[0], []byte("schemaVersion: "+strconv.Itoa(ConfigSchemaVersion)+"\nmarkdownExtension: "+imp.engine.MarkdownExtension+"\n")); err != nil {
+			return err
+		}
+	}
+
+	layouts := []string{}
+	for layout := range imp.layouts {
+		layouts = append(layouts, layout)
+	}
+	sort.Strings(layouts)
+	for _, layout := range layouts {
+		partialPath := path.Join(imp.engine.PartialsDir, "layouts", layout+imp.engine.PartialExtension)
+		if _, err := os.Stat(partialPath); err == nil {
+			continue
+		}
+		imp.note("'" + partialPath + "' is a placeholder, until the corresponding layout is ported.")
+		if err := imp.writeFile(partialPath, []byte(importPlaceholderLayout)); err != nil {
+			return err
+		}
+	}
+
+	ignoreLine := "/" + importDir + "/\n"
+	if _, err := os.Stat(imp.engine.TemingoignoreFilePath); os.IsNotExist(err) {
+		temingoignore := scaffoldTemingoignore
+		for _, valuesFilePath := range imp.engine.ValuesFilePaths {
+			temingoignore += "/" + path.Clean(valuesFilePath) + "\n"
+		}
+		return imp.writeFile(imp.engine.TemingoignoreFilePath, []byte(temingoignore+ignoreLine))
+	} else if err != nil {
+		return err
+	}
+	return imp.engine.appendTemingoignore(imp.engine.TemingoignoreFilePath, ignoreLine)
+}
+
+const importPlaceholderLayout = `<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>{{ .Page.Params.title }}</title>
+</head>
+<body>
+{{ .Page.Content }}
+</body>
+</html>
+`
+
+// walk calls fn with the path (relative to dir) and the content of each file in dir of the imported site. A missing dir is skipped.
+func (imp *importer) walk(dir string, fn func(relativePath string, content []byte) error) error {
+	root := path.Join(imp.sourceDir, dir)
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		return nil
+	}
+	return filepath.Walk(root, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		relativePath, err := filepath.Rel(root, filePath)
+		if err != nil {
+			return err
+		}
+		content, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		return fn(filepath.ToSlash(relativePath), content)
+	})
+}
+
+// writeFile writes content to filePath, unless the file already exists.
+func (imp *importer) writeFile(filePath string, content []byte) error {
+	if _, err := os.Stat(filePath); err == nil {
+		imp.note("'" + filePath + "' already exists and was kept as it is.")
+		return nil
+	}
+	imp.engine.logDebug("Creating file '" + filePath + "' ...")
+	imp.engine.createFolderIfNotExists(path.Dir(filePath))
+	return ioutil.WriteFile(filePath, content, imp.engine.fileMode)
+}
+
+// marshalYaml returns values as yaml, indented by two spaces like the usual hand-written files.
+func marshalYaml(values interface{}) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	encoder := yaml.NewEncoder(buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(values); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), encoder.Close()
+}
+
+func sortedKeys(values map[string]interface{}) []string {
+	keys := []string{}
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (imp *importer) note(note string) {
+	imp.notes = append(imp.notes, note)
+}
+
+func isImportedMarkdown(filePath string) bool {
+	extension := strings.ToLower(path.Ext(filePath))
+	return extension == ".md" || extension == ".markdown"
+}
+
+// splitImportedFrontMatter returns the front matter of a content file of another generator, which can be yaml (delimited by '---'), toml (delimited by '+++') or json, and the content after it.
+func splitImportedFrontMatter(content []byte) (map[string]interface{}, []byte, bool, error) {
+	frontMatter := make(map[string]interface{})
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	if strings.HasPrefix(text, "{") { // json front matter ends with the closing brace of its object
+		decoder := json.NewDecoder(strings.NewReader(text))
+		if err := decoder.Decode(&frontMatter); err == nil {
+			return frontMatter, []byte(strings.TrimPrefix(text[decoder.InputOffset():], "\n")), true, nil
+		}
+		return make(map[string]interface{}), content, false, nil
+	}
+
+	lines := strings.Split(text, "\n")
+	delimiter := lines[0]
+	if delimiter != frontMatterDelimiter && delimiter != "+++" {
+		return frontMatter, content, false, nil
+	}
+	for end := 1; end < len(lines); end++ {
+		if lines[end] != delimiter {
+			continue
+		}
+		block := strings.Join(lines[1:end], "\n")
+		var err error
+		if delimiter == "+++" {
+			_, err = toml.Decode(block, &frontMatter)
+			frontMatter = normalizeTomlValue(frontMatter).(map[string]interface{})
+		} else {
+			err = yaml.Unmarshal([]byte(block), &frontMatter)
+		}
+		if frontMatter == nil { // empty front matter
+			frontMatter = make(map[string]interface{})
+		}
+		return frontMatter, []byte(strings.Join(lines[end+1:], "\n")), true, err
+	}
+	return frontMatter, content, false, nil // not terminated, so it's not front matter
+}
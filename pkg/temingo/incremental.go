@@ -0,0 +1,328 @@
+package temingo
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/otiai10/copy"
+	"github.com/radovskyb/watcher"
+)
+
+var (
+	templateInvocationRegexp = regexp.MustCompile(`{{-?\s*(?:template|block|include|extends)\s+"([^"]+)"`)                      // templates and partials included by name, and extended layouts
+	templateDefinitionRegexp = regexp.MustCompile(`{{-?\s*(?:define|block)\s+"([^"]+)"`)                                        // templates defined inside a partial
+	listSourceRegexp         = regexp.MustCompile(`\b(?:pages|list|listTree|paginate|taxonomy)\b|\.Site\.(?:Pages|Taxonomies)`) // usage of the page collection or list objects, including paginated and nested ones and the terms of taxonomies
+)
+
+// rebuildChanged rerenders only the outputs affected by the given file changes.
+// Templates and markdown files are rerendered when they, one of the partials they use (directly or via other partials) or - in case of single-views - one of their items changed.
+// Templates using 'pages', 'list' or 'paginate' are additionally rerendered when the values of any page or item changed.
+// Changes which can't be narrowed down, like changed values files, config files, created, moved or deleted files, result in a full rebuild.
+// So does every change after a failed build, as its outputs might be incomplete.
+func (engine *Engine) rebuildChanged(events []watcher.Event) error {
+	engine.startSummary()
+	var changedPaths []string
+	for _, event := range events {
+		if engine.isExcludedFromWatching(event.Path) {
+			continue
+		}
+		if event.Op == watcher.Write && event.IsDir() { // only the modification time of the folder changed, added or removed entries have their own events
+			continue
+		}
+		filePath, ok := engine.getWatchedPath(event)
+		if !ok {
+			engine.logInfo("*** Rebuilding everything because of a change in", event.Path, "***")
+			if engine.isValuesFile(event.Path) {
+				engine.logValuesDiff()
+			}
+			return engine.rebuildOutput()
+		}
+		changedPaths = append(changedPaths, filePath)
+	}
+	if len(changedPaths) == 0 {
+		return nil
+	}
+	if engine.buildFailed {
+		engine.logInfo("*** Rebuilding everything because the previous build failed or was canceled ***")
+		return engine.rebuildOutput()
+	}
+
+	previousPages, previousAliases := engine.sitePages, engine.aliases
+	sources, err := engine.readSources()
+	if err != nil {
+		return err
+	}
+	err = engine.collectPages(sources)
+	if err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(getPagePaths(previousPages), getPagePaths(engine.sitePages)) { // pages were added or removed, so there might be stale outputs
+		engine.logInfo("*** Rebuilding everything because the pages of the site changed ***")
+		return engine.rebuildOutput()
+	}
+	err = engine.collectAliases()
+	if err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(previousAliases, engine.aliases) { // aliases were added or removed, so there might be stale redirects
+		engine.logInfo("*** Rebuilding everything because the aliases of the site changed ***")
+		return engine.rebuildOutput()
+	}
+	pagesChanged := !reflect.DeepEqual(previousPages, engine.sitePages)
+
+	dependencyContents, err := engine.getDependencyContents(sources)
+	if err != nil {
+		return err
+	}
+	errs := BuildErrors{}
+	affectedTemplates := make(map[string]bool)
+	for _, filePath := range changedPaths {
+		switch {
+		case engine.isInside(filePath, engine.PartialsDir) && strings.HasSuffix(filePath, engine.PartialExtension):
+			relativePath, _ := filepath.Rel(engine.PartialsDir, filePath)
+			partialName := strings.TrimSuffix(filepath.ToSlash(relativePath), engine.PartialExtension)
+			changedNames := map[string]bool{partialName: true} // the partial itself and all templates it defines
+			for _, partial := range sources.partials {
+				if partial[0] == partialName {
+					for _, match := range templateDefinitionRegexp.FindAllStringSubmatch(partial[1], -1) {
+						changedNames[match[1]] = true
+					}
+				}
+			}
+			for templateName, content := range dependencyContents {
+				usedNames, _ := getTemplateDependencies(content, sources.partials)
+				for name := range changedNames {
+					if usedNames[name] {
+						affectedTemplates[templateName] = true
+					}
+				}
+			}
+		case engine.isItemFile(filePath): // an item on its own, which is rendered by the single-view templates of its folder, or the nested ones of any folder above
+			for _, template := range sources.singleTemplates {
+				if engine.isInside(path.Dir(filePath), path.Dir(template[0])) {
+					affectedTemplates[template[0]] = true
+				}
+			}
+		case strings.HasSuffix(filePath, engine.TemplateExtension) || strings.HasSuffix(filePath, engine.SingleTemplateExtension) || engine.isMarkdownFile(filePath):
+			affectedTemplates[filePath] = true
+		case engine.isTemplateValuesFile(filePath):
+			for _, templateName := range engine.getValuesFileTemplates(filePath) {
+				affectedTemplates[templateName] = true
+			}
+		case engine.isItemIndexFile(filePath): // an item, which is rendered by the single-view templates of its parent folder, or the nested ones of any folder above
+			listPath := path.Dir(path.Dir(filePath))
+			for _, template := range sources.singleTemplates {
+				if engine.isInside(listPath, path.Dir(template[0])) {
+					affectedTemplates[template[0]] = true
+				}
+			}
+		case engine.isInside(filePath, engine.StaticDir) && isSassFile(filePath): // stylesheets can import each other, so all of them are compiled again
+			errs.add(engine.compileSass())
+			errs.add(engine.minifyStaticFiles())
+			errs.add(engine.buildAssetBundles())
+		case engine.isInside(filePath, engine.StaticDir):
+			relativePath, _ := filepath.Rel(engine.StaticDir, filePath)
+			outputFilePath := path.Join(engine.OutputDir, filepath.ToSlash(relativePath))
+			if err := engine.copyFile(filePath, outputFilePath); err != nil {
+				errs.add(err)
+			} else {
+				errs.add(engine.minifyStaticFile(outputFilePath))
+				errs.add(engine.buildAssetBundles())
+			}
+		case engine.isInside(filePath, engine.InputDir) && !engine.isExcludedFromCopy(filePath):
+			relativePath, _ := filepath.Rel(engine.InputDir, filePath)
+			errs.add(engine.copyFile(filePath, path.Join(engine.OutputDir, filepath.ToSlash(relativePath))))
+		}
+	}
+
+	if pagesChanged {
+		for templateName, content := range dependencyContents {
+			if _, usesListSources := getTemplateDependencies(content, sources.partials); usesListSources {
+				affectedTemplates[templateName] = true
+			}
+		}
+	}
+
+	engine.renderedFiles = make(map[string]bool) // so only the rerendered files are exported to PDF again
+	engine.changedFiles = make(map[string]bool)  // so only the collections containing changed files are exported again
+	for _, filePath := range changedPaths {
+		engine.changedFiles[filePath] = true
+	}
+	defer func() { engine.renderedFiles, engine.changedFiles = nil, nil }()
+	jobs, err := engine.getJobs(sources, func(templateName string) bool { return affectedTemplates[templateName] })
+	errs.add(err)
+	errs.add(engine.runJobs(jobs, sources))
+	if err := errs.err(); err != nil {
+		return err
+	}
+	err = engine.export()
+	if err != nil {
+		return err
+	}
+	err = engine.checkLinks()
+	if err != nil {
+		return err
+	}
+	err = engine.writeManifest()
+	if err != nil {
+		return err
+	}
+
+	engine.logSummary("Successfully rebuilt " + strconv.Itoa(len(affectedTemplates)) + " template(s) because of a change in " + strings.Join(changedPaths, ", "))
+	return nil
+}
+
+// getWatchedPath returns the path of the file changed by event, relative to the working directory.
+// Returns false if the change requires a full rebuild.
+func (engine *Engine) getWatchedPath(event watcher.Event) (string, bool) {
+	if event.Op != watcher.Write { // created, moved and deleted files can change everything
+		return "", false
+	}
+
+	filePath, ok := getRelativePath(event.Path)
+	if !ok || engine.isValuesFile(event.Path) {
+		return "", false
+	}
+	if filePath == cleanPath(engine.TemingoignoreFilePath) {
+		return "", false
+	}
+	if engine.isFingerprinted(filePath) { // its fingerprinted path changes, which can affect any output
+		return "", false
+	}
+	if engine.isInside(filePath, engine.DataDir) { // data is available to all templates
+		return "", false
+	}
+	if engine.isInside(filePath, path.Join(engine.PartialsDir, shortcodesDir)) { // shortcodes can be used by any markdown content
+		return "", false
+	}
+	if engine.isProcessedImage(filePath) { // the path of its processed version changes, which can affect any output
+		return "", false
+	}
+	if len(engine.Languages) > 0 { // each file can affect the outputs of several languages
+		return "", false
+	}
+	for _, configFileName := range configFileNames {
+		if path.Base(filePath) == configFileName {
+			return "", false
+		}
+	}
+
+	return filePath, true
+}
+
+// getRelativePath returns the path of the watched file at absolutePath relative to the working directory, and false if it's outside of it.
+func getRelativePath(absolutePath string) (string, bool) {
+	workingDir, err := os.Getwd()
+	if err != nil {
+		return "", false
+	}
+	relativePath, err := filepath.Rel(workingDir, absolutePath)
+	if err != nil || strings.HasPrefix(relativePath, "..") {
+		return "", false
+	}
+	return filepath.ToSlash(relativePath), true
+}
+
+// isValuesFile returns whether the watched file at absolutePath is one of the values files or their overlays of environments and languages.
+func (engine *Engine) isValuesFile(absolutePath string) bool {
+	filePath, ok := getRelativePath(absolutePath)
+	if !ok {
+		return false
+	}
+	for _, valuesFilePath := range engine.ValuesFilePaths {
+		if filePath == path.Clean(valuesFilePath) {
+			return true
+		}
+		if matched, _ := path.Match(getValuesOverlayPattern(valuesFilePath), filePath); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// getDependencyContents returns the content of each template, single-view template and markdown file by its name, as it's analysed for dependencies.
+// Markdown files only depend on their layout.
+func (engine *Engine) getDependencyContents(sources renderSources) (map[string]string, error) {
+	contents := make(map[string]string)
+	for _, template := range append(append([][]string{}, sources.templates...), sources.singleTemplates...) {
+		contents[template[0]] = template[1]
+		if _, body, err := splitFrontMatter(template[0], template[1]); err == nil { // includes the 'layout' of the front matter
+			contents[template[0]] = body
+		}
+	}
+	for _, markdownFile := range sources.markdownFiles {
+		layout, err := engine.getMarkdownLayout(markdownFile, sources)
+		if err != nil {
+			return nil, err
+		}
+		contents[markdownFile[0]] = getLayoutInvocation(layout)
+	}
+	return contents, nil
+}
+
+// getTemplateDependencies returns the names of all templates and partials the template content uses, directly or via other partials.
+// Additionally returns whether it - or one of the used partials - uses 'pages' or 'list'.
+func getTemplateDependencies(content string, partials [][]string) (map[string]bool, bool) {
+	usedNames := make(map[string]bool)
+	usesListSources := listSourceRegexp.MatchString(content)
+
+	pending := []string{content}
+	for len(pending) > 0 {
+		current := pending[0]
+		pending = pending[1:]
+		for _, match := range templateInvocationRegexp.FindAllStringSubmatch(current, -1) {
+			if usedNames[match[1]] {
+				continue
+			}
+			usedNames[match[1]] = true
+			for _, partial := range partials { // all partials which provide the used name
+				if partial[0] == match[1] || templateDefinesName(partial[1], match[1]) {
+					pending = append(pending, partial[1])
+					if listSourceRegexp.MatchString(partial[1]) {
+						usesListSources = true
+					}
+				}
+			}
+		}
+	}
+
+	return usedNames, usesListSources
+}
+
+func templateDefinesName(content string, name string) bool {
+	for _, match := range templateDefinitionRegexp.FindAllStringSubmatch(content, -1) {
+		if match[1] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// getPagePaths returns the paths of the given pages, in their order.
+func getPagePaths(pages []interface{}) []string {
+	paths := []string{}
+	for _, page := range pages {
+		paths = append(paths, toString(page.(map[string]interface{})["Path"]))
+	}
+	return paths
+}
+
+// isInside returns whether filePath is located inside dirPath.
+func (engine *Engine) isInside(filePath string, dirPath string) bool {
+	relativePath, err := filepath.Rel(dirPath, filePath)
+	return err == nil && !strings.HasPrefix(relativePath, "..")
+}
+
+func (engine *Engine) copyFile(src string, dst string) error {
+	engine.logDebug("Copying '" + src + "' to '" + dst + "' ...")
+	if err := engine.checkOutputFilePath(dst); err != nil {
+		return err
+	}
+	engine.countCopied(src)
+	return copy.Copy(src, dst)
+}
@@ -0,0 +1,42 @@
+package temingo
+
+import (
+	"errors"
+	"text/template/parse"
+)
+
+// getExtendedLayout returns the name of the layout the template with the parse tree extends via '{{ extends "layouts/base" }}', or an empty string if it doesn't extend one.
+// Only an 'extends' at the top level of the template counts, with the name of the layout as string.
+func getExtendedLayout(tree *parse.Tree) (string, error) {
+	if tree == nil || tree.Root == nil {
+		return "", nil
+	}
+	layout := ""
+	for _, node := range tree.Root.Nodes {
+		action, ok := node.(*parse.ActionNode)
+		if !ok || len(action.Pipe.Cmds) != 1 || len(action.Pipe.Decl) > 0 {
+			continue
+		}
+		command := action.Pipe.Cmds[0]
+		if identifier, ok := command.Args[0].(*parse.IdentifierNode); !ok || identifier.Ident != "extends" {
+			continue
+		}
+		if layout != "" {
+			return "", errors.New("'" + tree.ParseName + "' extends more than one layout, but can only extend one")
+		}
+		if len(command.Args) != 2 {
+			return "", errors.New("'extends' of '" + tree.ParseName + "' requires exactly one argument, the name of the layout")
+		}
+		name, ok := command.Args[1].(*parse.StringNode)
+		if !ok {
+			return "", errors.New("'extends' of '" + tree.ParseName + "' requires the name of the layout as string, like '{{ extends \"layouts/base\" }}'")
+		}
+		layout = name.Text
+	}
+	return layout, nil
+}
+
+// extendsOutsideTopLevel is the 'extends' template function, which is only called if 'extends' isn't used at the top level of a template, as it's replaced by the layout otherwise.
+func extendsOutsideTopLevel(layout string) (string, error) {
+	return "", errors.New("extends: '" + layout + "' can only be extended at the top level of a template, not inside of 'define', 'block', 'if', 'range', 'with' or partials")
+}
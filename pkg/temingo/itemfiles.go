@@ -0,0 +1,58 @@
+package temingo
+
+import (
+	"errors"
+	"path"
+	"path/filepath"
+	"strings"
+
+	gitignore "github.com/sabhiram/go-gitignore"
+)
+
+// isItemFile returns whether the file at filePath is an item on its own, as it matches one of the itemFiles patterns, f.e. '/notes/*.md' for 'notes/idea.md'.
+// Only yaml, json, toml and markdown files can be items, item index files, the values files of templates and per-collection config files like '_index.yaml' never are.
+func (engine *Engine) isItemFile(filePath string) bool {
+	if len(engine.ItemFiles) == 0 || engine.isItemIndexFile(filePath) || engine.isTemplateValuesFile(filePath) {
+		return false
+	}
+	for _, configFileName := range configFileNames {
+		if path.Base(filePath) == configFileName {
+			return false
+		}
+	}
+	if extension := path.Ext(filePath); extension != ".yaml" && extension != ".yml" && extension != ".json" && extension != ".toml" && !engine.isMarkdownFile(filePath) {
+		return false
+	}
+	return gitignore.CompileIgnoreLines(engine.ItemFiles...).MatchesPath("/" + path.Clean(filepath.ToSlash(filePath)))
+}
+
+// getItemFilePath returns the path of the item defined by the item file at filePath, which is its path without extension, f.e. 'notes/idea' for 'notes/idea.md'.
+func (engine *Engine) getItemFilePath(filePath string) string {
+	if engine.isMarkdownFile(filePath) {
+		return strings.TrimSuffix(filePath, engine.MarkdownExtension)
+	}
+	return strings.TrimSuffix(filePath, path.Ext(filePath))
+}
+
+// addItem adds the item at itemPath with its index file to itemIndexPaths, unless another file already defines the same item, f.e. both 'notes/idea/index.yaml' and 'notes/idea.md'.
+func addItem(itemIndexPaths map[string]string, itemPath string, indexPath string) error {
+	if other, ok := itemIndexPaths[itemPath]; ok {
+		return errors.New("Both '" + other + "' and '" + indexPath + "' define the item '" + itemPath + "', but there can be only one.")
+	}
+	itemIndexPaths[itemPath] = indexPath
+	return nil
+}
+
+// removeItemFiles returns the markdown files which aren't items on their own, see isItemFile.
+func (engine *Engine) removeItemFiles(markdownFiles [][]string) [][]string {
+	if len(engine.ItemFiles) == 0 {
+		return markdownFiles
+	}
+	pages := [][]string{}
+	for _, markdownFile := range markdownFiles {
+		if !engine.isItemFile(markdownFile[0]) {
+			pages = append(pages, markdownFile)
+		}
+	}
+	return pages
+}
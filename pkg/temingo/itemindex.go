@@ -0,0 +1,119 @@
+package temingo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// getItemIndexFile returns the path of the index file of the folder at itemPath, which is the first of the itemIndexFiles that exists in it.
+// Returns false if there is none, so the folder is no item.
+func (engine *Engine) getItemIndexFile(itemPath string) (string, bool) {
+	for _, fileName := range engine.ItemIndexFiles {
+		indexPath := path.Join(itemPath, fileName)
+		if info, err := os.Stat(indexPath); err == nil && !info.IsDir() {
+			return indexPath, true
+		}
+	}
+	return "", false
+}
+
+// isItemIndexFile returns whether the file at filePath is named like one of the itemIndexFiles.
+func (engine *Engine) isItemIndexFile(filePath string) bool {
+	for _, fileName := range engine.ItemIndexFiles {
+		if path.Base(filePath) == fileName {
+			return true
+		}
+	}
+	return false
+}
+
+// loadItemIndexFile returns the values of an item index file, depending on its format.
+// yaml, json and toml files contain the values, markdown files contain them as front matter and their content is converted to html and available as 'Content'.
+// Items with markdown or an html 'content' value additionally get its 'Summary' and 'ReadingTime', see getSummary.
+func (engine *Engine) loadItemIndexFile(indexPath string) (map[string]interface{}, error) {
+	if engine.isMarkdownFile(indexPath) {
+		content, err := ioutil.ReadFile(indexPath)
+		if err != nil {
+			return nil, err
+		}
+		frontMatter, body, _, err := parseFrontMatter(indexPath, string(content))
+		if err != nil {
+			return nil, err
+		}
+		html, err := engine.convertMarkdown(indexPath, body)
+		if err != nil {
+			return nil, err
+		}
+		frontMatter["Content"] = html
+		engine.addSummary(frontMatter, string(html))
+		return frontMatter, nil
+	}
+
+	switch path.Ext(indexPath) {
+	case ".yaml", ".yml", ".json", ".toml":
+		values, err := loadDataFile(indexPath)
+		if err != nil {
+			return nil, err
+		}
+		if content, ok := values["content"].(string); ok { // html content of the item
+			engine.addSummary(values, content)
+		}
+		return values, nil
+	}
+	return nil, errors.New("The format of the item index file '" + indexPath + "' is not supported, it must be yaml, json, toml or markdown.")
+}
+
+// loadDataFile returns the values of a yaml, json or toml file.
+func loadDataFile(filePath string) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	switch path.Ext(filePath) {
+	case ".toml":
+		_, err = toml.Decode(string(content), &values)
+		values = normalizeTomlValue(values).(map[string]interface{})
+	case ".json":
+		err = json.Unmarshal(content, &values)
+	default:
+		err = yaml.Unmarshal(bytes.TrimSpace(content), &values)
+	}
+	if err != nil {
+		return nil, errors.New("Could not parse '" + filePath + "': " + err.Error())
+	}
+	if values == nil { // empty file
+		values = make(map[string]interface{})
+	}
+	return values, nil
+}
+
+// normalizeTomlValue converts the arrays of tables of toml to lists, so they look like the ones of yaml and json.
+func normalizeTomlValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, element := range v {
+			v[key] = normalizeTomlValue(element)
+		}
+		return v
+	case []map[string]interface{}:
+		list := []interface{}{}
+		for _, element := range v {
+			list = append(list, normalizeTomlValue(element))
+		}
+		return list
+	case []interface{}:
+		for i, element := range v {
+			v[i] = normalizeTomlValue(element)
+		}
+		return v
+	}
+	return value
+}
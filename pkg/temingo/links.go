@@ -0,0 +1,145 @@
+package temingo
+
+import (
+	"errors"
+	"html"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tdewolff/parse/v2"
+	htmlparse "github.com/tdewolff/parse/v2/html"
+)
+
+// linkAttributes are the attributes of html elements which reference other files of the site.
+var linkAttributes = map[string]bool{"href": true, "src": true, "srcset": true, "poster": true}
+
+// checkLinks reports references of the rendered html pages to files which don't exist in the outputDir, like 'href' of links and 'src' of images.
+// Absolute URLs are only checked if they start with the base URL of the site. Incremental rebuilds only check the rerendered pages.
+// Broken links are logged as warnings, or returned as errors with failOnBrokenLinks.
+func (engine *Engine) checkLinks() error {
+	if !engine.CheckLinks && !engine.FailOnBrokenLinks {
+		return nil
+	}
+	engine.lock.Lock()
+	outputFilePaths := []string{}
+	for filePath := range engine.renderedSources {
+		if engine.renderedFiles == nil || engine.renderedFiles[filePath] {
+			outputFilePaths = append(outputFilePaths, filePath)
+		}
+	}
+	engine.lock.Unlock()
+	sort.Strings(outputFilePaths)
+
+	errs := BuildErrors{}
+	broken := 0
+	for _, filePath := range outputFilePaths {
+		if !engine.isHtmlOutput(filePath) {
+			continue
+		}
+		content, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		relativePath, err := filepath.Rel(engine.OutputDir, filePath)
+		if err != nil {
+			return err
+		}
+		pagePath := "/" + filepath.ToSlash(relativePath)
+		for _, link := range getLinks(content) {
+			target, ok := engine.getInternalLinkTarget(pagePath, link)
+			if !ok || engine.linkTargetExists(target) {
+				continue
+			}
+			broken++
+			message := "'" + pagePath + "' links to '" + link + "', which doesn't exist in the output-dir."
+			if engine.FailOnBrokenLinks {
+				errs.add(errors.New(message))
+			} else {
+				engine.logWarn(message)
+			}
+		}
+	}
+	if broken == 0 {
+		engine.logDebug("*** No broken links found in " + strconv.Itoa(len(outputFilePaths)) + " output(s) ***")
+	}
+	return errs.err()
+}
+
+// getLinks returns the values of the linkAttributes of all elements in the html content, with the urls of a 'srcset' as separate links.
+func getLinks(content []byte) []string {
+	links := []string{}
+	lexer := htmlparse.NewLexer(parse.NewInputBytes(content))
+	for {
+		tokenType, _ := lexer.Next()
+		if tokenType == htmlparse.ErrorToken { // the end of the page
+			return links
+		}
+		if tokenType != htmlparse.AttributeToken {
+			continue
+		}
+		name := strings.ToLower(string(lexer.Text()))
+		if !linkAttributes[name] {
+			continue
+		}
+		value := strings.TrimSpace(unquoteAttribute(lexer.AttrVal()))
+		if name == "srcset" {
+			for _, candidate := range strings.Split(value, ",") {
+				if fields := strings.Fields(candidate); len(fields) > 0 {
+					links = append(links, fields[0])
+				}
+			}
+			continue
+		}
+		if value != "" {
+			links = append(links, value)
+		}
+	}
+}
+
+// unquoteAttribute returns the attribute value without surrounding quotes and with html entities decoded.
+func unquoteAttribute(value []byte) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		value = value[1 : len(value)-1]
+	}
+	return html.UnescapeString(string(value))
+}
+
+// getInternalLinkTarget returns the site-relative path link on the page at pagePath points to, without query and fragment.
+// Returns false for links to other sites, other schemes like 'mailto:' and links to the page itself like '#top'.
+func (engine *Engine) getInternalLinkTarget(pagePath string, link string) (string, bool) {
+	if engine.siteBaseURL != "" && strings.HasPrefix(link, strings.TrimSuffix(engine.siteBaseURL, "/")+"/") {
+		link = "/" + strings.TrimPrefix(link, strings.TrimSuffix(engine.siteBaseURL, "/")+"/")
+	}
+	parsed, err := url.Parse(link)
+	if err != nil || parsed.Scheme != "" || parsed.Host != "" || parsed.Path == "" || strings.Contains(link, "{{") { // template expressions f.e. in inline scripts aren't links
+		return "", false
+	}
+	target := parsed.Path
+	if !strings.HasPrefix(target, "/") {
+		target = path.Join(path.Dir(pagePath), target)
+		if strings.HasSuffix(parsed.Path, "/") {
+			target += "/"
+		}
+	}
+	return target, true
+}
+
+// linkTargetExists returns whether the site-relative target exists in the outputDir. Folders exist if they contain an 'index.html', like they are served.
+func (engine *Engine) linkTargetExists(target string) bool {
+	filePath := path.Join(engine.OutputDir, target)
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return false
+	}
+	if !info.IsDir() {
+		return true
+	}
+	_, err = os.Stat(path.Join(filePath, "index.html"))
+	return err == nil
+}
@@ -0,0 +1,251 @@
+package temingo
+
+import (
+	"errors"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template/parse"
+)
+
+// LintIssue is a problem found in a template or partial by Lint.
+type LintIssue struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"` // 'error' or 'warning'
+	Message  string `json:"message"`
+}
+
+// LintRules are the rules Lint checks, with their default severity. The severity of each can be changed via the lintRules option, 'off' disables it.
+var LintRules = map[string]string{
+	"syntax":              "error",   // templates that can't be parsed
+	"unknown-function":    "error",   // calls of functions that don't exist
+	"undefined-template":  "error",   // 'template', 'include' and 'extends' of partials and defined templates that don't exist
+	"unsafe-html":         "warning", // 'safeHTML' and 'safeCSS' of values instead of literals, which might contain user data
+	"deprecated-function": "warning", // calls of functions that will be removed
+}
+
+// builtinFunctions are the functions of text/template, which are available in every template.
+var builtinFunctions = []string{"and", "call", "eq", "ge", "gt", "html", "index", "js", "le", "len", "lt", "ne", "not", "or", "print", "printf", "println", "slice", "urlquery"}
+
+var (
+	undefinedFunctionRegexp = regexp.MustCompile(`function "([^"]+)" not defined`)
+	parseErrorLineRegexp    = regexp.MustCompile(`^template: [^:]*:(\d+):`)
+)
+
+// lintFile is a template or partial to lint, with the trees of the templates it defines.
+type lintFile struct {
+	file  string // path of the file
+	name  string // name the template is parsed as, the name of partials
+	trees map[string]*parse.Tree
+}
+
+// Lint statically analyzes all templates, single-view templates and partials, without rendering them.
+// The issues are sorted by file and line. Rules are configured via the lintRules option, see LintRules.
+func (engine *Engine) Lint() ([]LintIssue, error) {
+	if err := engine.validate(); err != nil {
+		return nil, err
+	}
+	severities, err := engine.getLintSeverities()
+	if err != nil {
+		return nil, err
+	}
+
+	templates, err := engine.getTemplates(engine.InputDir, engine.TemplateExtension, []string{
+		path.Join(engine.InputDir, engine.PartialsDir, "**"),
+		path.Join(engine.InputDir, engine.OutputDir, "**"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	partials, err := engine.getPartialTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	functions := engine.getFuncMap("", nil, nil) // only the names are used, as the templates aren't executed
+	for _, name := range builtinFunctions {
+		functions[name] = true // the parser only needs non-nil values
+	}
+
+	issues := []LintIssue{}
+	addIssue := func(file string, line int, rule string, message string) {
+		if severities[rule] != "off" {
+			issues = append(issues, LintIssue{file, line, rule, severities[rule], message})
+		}
+	}
+
+	files := []lintFile{}
+	for _, partial := range partials {
+		files = append(files, lintFile{file: path.Join(engine.PartialsDir, partial[0]+engine.PartialExtension), name: partial[0]})
+	}
+	for _, template := range templates { // contains the single-view templates as well
+		files = append(files, lintFile{file: template[0], name: template[0]})
+	}
+	contents := append(append([][]string{}, partials...), templates...)
+
+	defined := make(map[string]bool) // the partials and the templates they define, which are available to all templates
+	for i := range files {
+		_, body, err := splitFrontMatter(files[i].file, contents[i][1])
+		if err != nil {
+			addIssue(files[i].file, 1, "syntax", err.Error())
+			continue
+		}
+		files[i].trees, err = parse.Parse(files[i].name, body, "{{", "}}", functions)
+		if err != nil {
+			line := 0
+			if match := parseErrorLineRegexp.FindStringSubmatch(err.Error()); match != nil {
+				line, _ = strconv.Atoi(match[1])
+			}
+			if match := undefinedFunctionRegexp.FindStringSubmatch(err.Error()); match != nil {
+				addIssue(files[i].file, line, "unknown-function", "The function '"+match[1]+"' does not exist.")
+			} else {
+				addIssue(files[i].file, line, "syntax", err.Error())
+			}
+			continue
+		}
+		if i < len(partials) {
+			for name := range files[i].trees {
+				defined[name] = true
+			}
+		}
+	}
+
+	for i, file := range files {
+		if file.trees == nil {
+			continue
+		}
+		ownDefinitions := make(map[string]bool)
+		if i >= len(partials) { // templates can additionally use their own definitions
+			for name := range file.trees {
+				ownDefinitions[name] = true
+			}
+		}
+		for _, tree := range file.trees {
+			engine.lintNode(tree, tree.Root, func(node parse.Node, rule string, message string) {
+				addIssue(file.file, getNodeLine(tree, node), rule, message)
+			}, func(name string) bool {
+				return defined[name] || ownDefinitions[name]
+			})
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].File != issues[j].File {
+			return issues[i].File < issues[j].File
+		}
+		return issues[i].Line < issues[j].Line
+	})
+	return issues, nil
+}
+
+// lintNode reports the issues of node and the nodes beneath it.
+func (engine *Engine) lintNode(tree *parse.Tree, node parse.Node, report func(node parse.Node, rule string, message string), isDefined func(name string) bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			engine.lintNode(tree, child, report, isDefined)
+		}
+	case *parse.ActionNode:
+		engine.lintNode(tree, n.Pipe, report, isDefined)
+	case *parse.IfNode:
+		engine.lintBranch(tree, &n.BranchNode, report, isDefined)
+	case *parse.RangeNode:
+		engine.lintBranch(tree, &n.BranchNode, report, isDefined)
+	case *parse.WithNode:
+		engine.lintBranch(tree, &n.BranchNode, report, isDefined)
+	case *parse.TemplateNode:
+		if !isDefined(n.Name) {
+			report(n, "undefined-template", "The partial or template '"+n.Name+"' does not exist.")
+		}
+		engine.lintNode(tree, n.Pipe, report, isDefined)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for i, command := range n.Cmds {
+			engine.lintCommand(command, i > 0, report, isDefined)
+			for _, arg := range command.Args {
+				engine.lintNode(tree, arg, report, isDefined)
+			}
+		}
+	}
+}
+
+func (engine *Engine) lintBranch(tree *parse.Tree, branch *parse.BranchNode, report func(node parse.Node, rule string, message string), isDefined func(name string) bool) {
+	engine.lintNode(tree, branch.Pipe, report, isDefined)
+	engine.lintNode(tree, branch.List, report, isDefined)
+	engine.lintNode(tree, branch.ElseList, report, isDefined)
+}
+
+// lintCommand reports the issues of a function call. piped is whether the command gets the result of the previous one of its pipeline as last argument.
+func (engine *Engine) lintCommand(command *parse.CommandNode, piped bool, report func(node parse.Node, rule string, message string), isDefined func(name string) bool) {
+	identifier, ok := command.Args[0].(*parse.IdentifierNode)
+	if !ok {
+		return
+	}
+	args := command.Args[1:]
+	switch identifier.Ident {
+	case "include", "extends":
+		if len(args) > 0 {
+			if name, ok := args[0].(*parse.StringNode); ok && !isDefined(name.Text) {
+				report(command, "undefined-template", "The partial or template '"+name.Text+"' does not exist.")
+			}
+		}
+	case "safeHTML", "safeCSS":
+		if piped || len(args) == 0 {
+			report(command, "unsafe-html", "'"+identifier.Ident+"' disables the escaping of the piped value, make sure it can't contain user data.")
+		} else if _, ok := args[0].(*parse.StringNode); !ok {
+			report(command, "unsafe-html", "'"+identifier.Ident+"' disables the escaping of '"+args[0].String()+"', make sure it can't contain user data.")
+		}
+	}
+	if id, ok := deprecatedFunctions[identifier.Ident]; ok {
+		report(command, "deprecated-function", "The function '"+identifier.Ident+"' is deprecated, "+deprecations[id].hint+".")
+	}
+}
+
+// getNodeLine returns the line of node in the file of tree.
+func getNodeLine(tree *parse.Tree, node parse.Node) int {
+	location, _ := tree.ErrorContext(node) // f.e. 'blog/index.html.template:4:12'
+	parts := strings.Split(location, ":")
+	if len(parts) < 3 {
+		return 0
+	}
+	line, _ := strconv.Atoi(parts[len(parts)-2])
+	return line
+}
+
+// getLintSeverities returns the severity of each rule, which is the default one unless it's changed via the lintRules option.
+func (engine *Engine) getLintSeverities() (map[string]string, error) {
+	severities := make(map[string]string)
+	for rule, severity := range LintRules {
+		severities[rule] = severity
+	}
+	if engine.Future { // deprecated functions are handled as if they were already removed
+		severities["deprecated-function"] = "error"
+	}
+	for rule, severity := range engine.LintRules {
+		if _, ok := LintRules[rule]; !ok {
+			return nil, errors.New("The lint rule '" + rule + "' does not exist, it must be one of '" + strings.Join(sortedKeys(toInterfaceMap(LintRules)), "', '") + "'.")
+		}
+		if severity != "error" && severity != "warning" && severity != "off" {
+			return nil, errors.New("The severity of the lint rule '" + rule + "' must be 'error', 'warning' or 'off', but is '" + severity + "'.")
+		}
+		severities[rule] = severity
+	}
+	return severities, nil
+}
+
+func toInterfaceMap(values map[string]string) map[string]interface{} {
+	converted := make(map[string]interface{})
+	for key, value := range values {
+		converted[key] = value
+	}
+	return converted
+}
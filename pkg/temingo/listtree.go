@@ -0,0 +1,76 @@
+package temingo
+
+import (
+	"errors"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+)
+
+// getListTree returns the list objects of treePath together with the ones of its subsections, f.e. for the navigation of a documentation.
+// Subsections are the subfolders which are no items themselves, but contain items - directly or via their own subsections.
+// Each level contains its 'Path', 'Name', 'Values' (of its own '_index.yaml'), 'Items', 'Sections', 'Count' (number of its items) and 'TotalCount' (including the items of all subsections).
+// Items and sections are sorted by the keys in sortBy (see parseSortKeys), sections by the values of their '_index.yaml'. Without sortBy, both are sorted by their path.
+func (engine *Engine) getListTree(treePath string, sortBy string) (map[string]interface{}, error) {
+	treePath = path.Clean(treePath)
+	items, err := engine.getSortedListObjects(treePath, sortBy, false)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := engine.loadOwnSectionValues(treePath)
+	if err != nil {
+		return nil, err
+	}
+
+	dirContents, err := ioutil.ReadDir(treePath)
+	if err != nil {
+		return nil, err
+	}
+	sections := []map[string]interface{}{}
+	totalCount := len(items)
+	for _, dirEntry := range dirContents {
+		sectionPath := path.Join(treePath, dirEntry.Name())
+		if !dirEntry.IsDir() || strings.HasPrefix(dirEntry.Name(), ".") || engine.isExcluded(sectionPath, []string{"/" + path.Join(engine.PartialsDir, "**")}) {
+			continue
+		}
+		if _, ok := engine.getItemIndexFile(sectionPath); ok { // items are leaves of the tree
+			continue
+		}
+		section, err := engine.getListTree(sectionPath, sortBy)
+		if err != nil {
+			return nil, err
+		}
+		if section["TotalCount"].(int) == 0 { // folders without any items, like the ones of assets
+			continue
+		}
+		sections = append(sections, section)
+		totalCount += section["TotalCount"].(int)
+	}
+	if sortBy != "" {
+		sortKeys, err := parseSortKeys(strings.Split(sortBy, ","))
+		if err != nil {
+			return nil, errors.New("Could not sort '" + treePath + "': " + err.Error())
+		}
+		sort.SliceStable(sections, func(i, j int) bool { return lessByKeys(sections[i]["Values"], sections[j]["Values"], sortKeys) })
+	}
+
+	itemList := []interface{}{}
+	for _, item := range items {
+		itemList = append(itemList, item)
+	}
+	sectionList := []interface{}{}
+	for _, section := range sections {
+		sectionList = append(sectionList, section)
+	}
+	return map[string]interface{}{
+		"Path":       path.Join("/", treePath),
+		"Name":       path.Base(treePath),
+		"Values":     values,
+		"Items":      itemList,
+		"Sections":   sectionList,
+		"Count":      len(items),
+		"TotalCount": totalCount,
+	}, nil
+}
@@ -0,0 +1,76 @@
+package temingo
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const lockFileName = ".temingo.lock"
+
+// acquireLock creates the lock file of the project, so no other temingo process writes to the outputDir at the same time. The returned function releases the lock again.
+// The lock file contains the pid and the host of the process holding it. If that process doesn't exist anymore, f.e. because it was killed, the lock is stale and taken over.
+func (engine *Engine) acquireLock() (func(), error) {
+	if engine.NoLock {
+		return func() {}, nil
+	}
+
+	hostname, _ := os.Hostname()
+	content := strconv.Itoa(os.Getpid()) + "\n" + hostname + "\n"
+	for attempt := 0; attempt < 2; attempt++ { // the second attempt is after removing a stale lock
+		file, err := os.OpenFile(lockFileName, os.O_WRONLY|os.O_CREATE|os.O_EXCL, engine.fileMode)
+		if err == nil {
+			_, err = file.WriteString(content)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(lockFileName)
+				return nil, err
+			}
+			return func() { os.Remove(lockFileName) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+
+		pid, lockHostname, err := readLockFile()
+		if err != nil {
+			return nil, err
+		}
+		if lockHostname != hostname || isRunning(pid) {
+			return nil, errors.New("Another temingo process (pid " + strconv.Itoa(pid) + " on '" + lockHostname + "') is building this project, as '" + lockFileName + "' exists. Wait for it to finish, or delete the file if that process doesn't exist anymore. Use '--noLock' to build anyway.")
+		}
+		engine.logDebug("Removing the stale lock of process " + strconv.Itoa(pid) + ", which doesn't exist anymore.") // f.e. after stopping a watching process
+		if err := os.Remove(lockFileName); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	return nil, errors.New("Could not acquire '" + lockFileName + "', as it was recreated by another temingo process.")
+}
+
+// readLockFile returns the pid and the host of the process holding the lock.
+func readLockFile() (int, string, error) {
+	content, err := ioutil.ReadFile(lockFileName)
+	if err != nil {
+		return 0, "", err
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	pid, err := strconv.Atoi(lines[0])
+	if err != nil || len(lines) != 2 {
+		return 0, "", errors.New("The lock file '" + lockFileName + "' is invalid. Delete it, if no other temingo process is running.")
+	}
+	return pid, lines[1], nil
+}
+
+// isRunning returns whether the process with the pid exists. If that can't be determined, it's assumed to exist.
+func isRunning(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil { // on windows, if the process doesn't exist
+		return false
+	}
+	return !errors.Is(process.Signal(syscall.Signal(0)), os.ErrProcessDone)
+}
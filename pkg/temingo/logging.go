@@ -0,0 +1,180 @@
+package temingo
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// logLevel is the severity of a log message. A message is logged if its level isn't more verbose than the one of the engine, see getLogLevel.
+type logLevel int
+
+const (
+	levelError logLevel = iota
+	levelWarn
+	levelInfo
+	levelDebug
+)
+
+// LogFormats are the formats the log messages can be written in.
+var LogFormats = []string{"text", "json"}
+
+var logLevelNames = []string{"error", "warn", "info", "debug"}
+
+var logWriteLock sync.Mutex // so messages logged by concurrently rendered templates don't interleave
+
+// getLogLevel returns the most verbose level that is logged: debug with the debug or verbose option, warn with the quiet option and info otherwise.
+func (engine *Engine) getLogLevel() logLevel {
+	switch {
+	case engine.Debug || engine.Verbose:
+		return levelDebug
+	case engine.Quiet:
+		return levelWarn
+	default:
+		return levelInfo
+	}
+}
+
+func (engine *Engine) isLogged(level logLevel) bool {
+	return level <= engine.getLogLevel()
+}
+
+// logMessage writes the message, formatted like log.Println does, to the output of the standard logger.
+// As text, warnings are prefixed with 'Warning: ' and the timestamp is the one of the standard logger. As json, each message is an object with its 'level', 'message', optional 'time' and additional fields.
+func (engine *Engine) logMessage(level logLevel, fields map[string]interface{}, v ...interface{}) {
+	if !engine.isLogged(level) {
+		return
+	}
+	message := strings.TrimSuffix(fmt.Sprintln(v...), "\n")
+	var line string
+	if engine.LogFormat == "json" {
+		entry := map[string]interface{}{"level": logLevelNames[level], "message": message}
+		if engine.LogTimestamps {
+			entry["time"] = time.Now().Format(time.RFC3339)
+		}
+		for key, value := range fields {
+			entry[key] = value
+		}
+		content, err := json.Marshal(entry)
+		if err != nil { // only happens for unsupported fields, so the message itself is kept
+			content, _ = json.Marshal(map[string]interface{}{"level": logLevelNames[level], "message": message})
+		}
+		line = string(content)
+	} else {
+		if level == levelWarn {
+			message = "Warning: " + message
+		}
+		if engine.LogTimestamps {
+			message = time.Now().Format("2006/01/02 15:04:05 ") + message
+		}
+		line = message
+	}
+	logWriteLock.Lock()
+	defer logWriteLock.Unlock()
+	fmt.Fprintln(log.Writer(), line)
+}
+
+func (engine *Engine) logError(v ...interface{}) {
+	engine.logMessage(levelError, nil, v...)
+}
+
+func (engine *Engine) logWarn(v ...interface{}) {
+	engine.logMessage(levelWarn, nil, v...)
+}
+
+func (engine *Engine) logInfo(v ...interface{}) {
+	engine.logMessage(levelInfo, nil, v...)
+}
+
+func (engine *Engine) logDebug(v ...interface{}) {
+	engine.logMessage(levelDebug, nil, v...)
+}
+
+// LogError logs err in the log format of the engine, f.e. for errors returned by Render. Errors are logged even with the quiet option.
+func (engine *Engine) LogError(err error) {
+	engine.logError(err.Error())
+}
+
+// LogInfo logs an informational message in the log format of the engine, unless the quiet option is set.
+func (engine *Engine) LogInfo(v ...interface{}) {
+	engine.logInfo(v...)
+}
+
+// LogDebug logs a message in the log format of the engine, if the debug or verbose option is set.
+func (engine *Engine) LogDebug(v ...interface{}) {
+	engine.logDebug(v...)
+}
+
+// buildSummary counts what a build did, so it can be logged once it's done.
+type buildSummary struct {
+	started  time.Time
+	rendered int
+	copied   int
+	pages    []string        // the rendered output files, relative to the outputDir
+	skipped  map[string]bool // ignored files and unpublished content, by path, as they are checked several times per build
+	lock     sync.Mutex      // the summary is updated while templates are rendered concurrently
+}
+
+// startSummary resets the counts of the summary for a new build.
+func (engine *Engine) startSummary() {
+	engine.summary = &buildSummary{started: time.Now(), skipped: make(map[string]bool)}
+}
+
+// countRendered counts the output file at outputFilePath as rendered.
+func (engine *Engine) countRendered(outputFilePath string) {
+	if engine.summary == nil {
+		return
+	}
+	engine.summary.lock.Lock()
+	engine.summary.rendered++
+	if pagePath, err := filepath.Rel(engine.OutputDir, outputFilePath); err == nil { // the same while rendering to the stagingDir
+		outputFilePath = filepath.ToSlash(pagePath)
+	}
+	engine.summary.pages = append(engine.summary.pages, outputFilePath)
+	engine.summary.lock.Unlock()
+}
+
+// countCopied counts the file at filePath as copied to the outputDir. Folders aren't counted.
+func (engine *Engine) countCopied(filePath string) {
+	if engine.summary == nil {
+		return
+	}
+	if info, err := os.Stat(filePath); err != nil || info.IsDir() {
+		return
+	}
+	engine.summary.lock.Lock()
+	engine.summary.copied++
+	engine.summary.lock.Unlock()
+}
+
+// countSkipped counts the file or folder at filePath as left out of the build, f.e. as it's ignored or a draft.
+func (engine *Engine) countSkipped(filePath string) {
+	if engine.summary == nil {
+		return
+	}
+	engine.summary.lock.Lock()
+	engine.summary.skipped[filePath] = true
+	engine.summary.lock.Unlock()
+}
+
+// logSummary logs message together with the counts and the duration of the build, f.e. '*** Successfully built contents (12 rendered, 3 copied, 1 skipped in 35ms) ***'.
+func (engine *Engine) logSummary(message string) {
+	if engine.summary == nil {
+		engine.logInfo("*** " + message + " ***")
+		return
+	}
+	summary := engine.summary
+	duration := time.Since(summary.started).Round(time.Millisecond)
+	engine.logMessage(levelInfo, map[string]interface{}{
+		"rendered":   summary.rendered,
+		"copied":     summary.copied,
+		"skipped":    len(summary.skipped),
+		"durationMs": duration.Milliseconds(),
+	}, "*** "+message+" ("+strconv.Itoa(summary.rendered)+" rendered, "+strconv.Itoa(summary.copied)+" copied, "+strconv.Itoa(len(summary.skipped))+" skipped in "+duration.String()+") ***")
+}
@@ -0,0 +1,109 @@
+package temingo
+
+import (
+	"errors"
+	"path"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+var markdownRenderer = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+	goldmark.WithRendererOptions(html.WithUnsafe()), // content files are part of the site, so raw html is kept
+)
+
+// isMarkdownFile returns whether the file at filePath is a markdown file. Markdown is opt-in, so without a markdownExtension there are none.
+func (engine *Engine) isMarkdownFile(filePath string) bool {
+	return engine.MarkdownExtension != "" && strings.HasSuffix(filePath, engine.MarkdownExtension)
+}
+
+// getMarkdownJob converts a markdown content file to html and returns the job rendering it through its layout, f.e. 'blog/post.md' to 'blog/post.html'.
+func (engine *Engine) getMarkdownJob(markdownFile []string, sources renderSources) (renderJob, error) {
+	frontMatter, body, _, err := parseFrontMatter(markdownFile[0], markdownFile[1])
+	if err != nil {
+		return renderJob{}, err
+	}
+
+	content, err := engine.convertMarkdown(markdownFile[0], body)
+	if err != nil {
+		return renderJob{}, err
+	}
+
+	sectionValues, err := engine.getSectionValues(path.Dir(markdownFile[0]))
+	if err != nil {
+		return renderJob{}, err
+	}
+	ownValues, err := engine.getTemplateValues(markdownFile[0], frontMatter)
+	if err != nil {
+		return renderJob{}, err
+	}
+	templateValues := mergeValues(sources.values, sectionValues, ownValues) // section values override the global values, the values of the markdown file override both
+	layout, err := engine.getMarkdownLayout(markdownFile, sources)
+	if err != nil {
+		return renderJob{}, err
+	}
+	outputPath, err := engine.getMarkdownOutputPath(markdownFile[0], frontMatter)
+	if err != nil {
+		return renderJob{}, err
+	}
+	outputFilePath, err := engine.getOutputFilePath(outputPath)
+	if err != nil {
+		return renderJob{}, err
+	}
+	engine.logDebug("Writing markdown output file '" + outputFilePath + "' with layout '" + layout + "' ...")
+
+	context := engine.createContext(templateValues, markdownFile[0], outputFilePath, nil, "")
+	if engine.FlatContext {
+		context["Content"] = content
+		engine.addSummary(context, string(content))
+	} else {
+		page := context["Page"].(map[string]interface{})
+		page["Content"] = content
+		engine.addSummary(page, string(content))
+	}
+	engine.setPageIndexing(outputFilePath, context, frontMatter)
+	engine.setPageReview(context, frontMatter)
+	engine.setPageParams(context, frontMatter)
+	return renderJob{context, markdownFile[0], getLayoutInvocation(layout), outputFilePath, []string{markdownFile[0]}, nil}, nil
+}
+
+// getMarkdownLayout returns the name of the partial a markdown file is rendered with.
+// It's the 'layout' of its front matter, of the (section) values or the markdownLayout, in that order.
+func (engine *Engine) getMarkdownLayout(markdownFile []string, sources renderSources) (string, error) {
+	frontMatter, _, _, err := parseFrontMatter(markdownFile[0], markdownFile[1])
+	if err != nil {
+		return "", err
+	}
+	layout := toString(frontMatter["layout"])
+	if layout == "" {
+		sectionValues, err := engine.getSectionValues(path.Dir(markdownFile[0]))
+		if err != nil {
+			return "", err
+		}
+		layout = toString(mergeValues(sources.values, sectionValues)["layout"])
+	}
+	if layout == "" {
+		layout = engine.MarkdownLayout
+	}
+
+	for _, partial := range sources.partials {
+		if partial[0] == layout || templateDefinesName(partial[1], layout) {
+			return layout, nil
+		}
+	}
+	return "", errors.New("The layout '" + layout + "' for '" + markdownFile[0] + "' does not exist in the partials-directory.")
+}
+
+// getMarkdownOutputPath returns the path of the rendered markdown file relative to the outputDir, unless its front matter declares a 'permalink'.
+func (engine *Engine) getMarkdownOutputPath(markdownFilePath string, frontMatter map[string]interface{}) (string, error) {
+	return engine.getPageOutputPath(markdownFilePath, frontMatter, engine.trimLanguage(strings.TrimSuffix(markdownFilePath, engine.MarkdownExtension))+".html")
+}
+
+func getLayoutInvocation(layout string) string {
+	return `{{ template "` + layout + `" . }}`
+}
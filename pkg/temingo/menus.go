@@ -0,0 +1,117 @@
+package temingo
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// menusValuesKey is the key of the values containing the menus, by their name.
+const menusValuesKey = "menus"
+
+// validateMenus checks the menus in the values are well-formed, see getMenus, before anything is rendered.
+func validateMenus(values map[string]interface{}) error {
+	_, err := getMenus(values[menusValuesKey], "")
+	return err
+}
+
+// getMenus returns the menus declared in the values by their name, f.e. 'main'. Each menu is a list of entries with a 'name', an optional 'url' and 'weight' and nested entries as 'children'.
+// The entries are sorted by their weight, entries with the same weight keep their order. Each contains 'Name', 'URL', 'Weight', 'Children', the other keys of the entry as they are,
+// and whether it is the page at pagePath ('IsActive') or one of its children or the folder of its url contains the page ('HasActiveChild').
+func getMenus(value interface{}, pagePath string) (map[string]interface{}, error) {
+	menus := make(map[string]interface{})
+	if value == nil {
+		return menus, nil
+	}
+	declared, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("The '" + menusValuesKey + "' in the values must be a map of menus by their name, f.e. 'main'.")
+	}
+	for name, entries := range declared {
+		menu, _, err := getMenuEntries(entries, menusValuesKey+"."+name, pagePath)
+		if err != nil {
+			return nil, err
+		}
+		menus[name] = menu
+	}
+	return menus, nil
+}
+
+// getMenuEntries returns the entries of a menu or of the children of an entry at keyPath (used in messages), and whether one of them is active or has an active child.
+func getMenuEntries(value interface{}, keyPath string, pagePath string) ([]interface{}, bool, error) {
+	declared, ok := value.([]interface{})
+	if !ok && value != nil {
+		return nil, false, errors.New("The menu '" + keyPath + "' in the values must be a list of entries.")
+	}
+	entries := []map[string]interface{}{}
+	containsActive := false
+	for i, element := range declared {
+		entryPath := keyPath + "[" + strconv.Itoa(i) + "]"
+		declaredEntry, ok := element.(map[string]interface{})
+		if !ok {
+			return nil, false, errors.New("The menu entry '" + entryPath + "' in the values must be a map with a 'name' and an optional 'url', 'weight' and 'children'.")
+		}
+		entry := make(map[string]interface{})
+		for key, value := range declaredEntry {
+			if key != "children" {
+				entry[key] = value
+			}
+		}
+		name, ok := declaredEntry["name"].(string)
+		if !ok || name == "" {
+			return nil, false, errors.New("The menu entry '" + entryPath + "' in the values must have a 'name'.")
+		}
+		weight := 0.0
+		if value, ok := declaredEntry["weight"]; ok {
+			if weight, ok = toFloat(value); !ok {
+				return nil, false, errors.New("The weight of the menu entry '" + entryPath + "' in the values must be a number, but is '" + toString(value) + "'.")
+			}
+		}
+		url := toString(declaredEntry["url"])
+		children, childActive, err := getMenuEntries(declaredEntry["children"], entryPath+".children", pagePath)
+		if err != nil {
+			return nil, false, err
+		}
+		entry["Name"] = name
+		entry["URL"] = url
+		entry["Weight"] = weight
+		entry["Children"] = children
+		entry["IsActive"] = pagePath != "" && isActiveMenuURL(pagePath, url)
+		entry["HasActiveChild"] = childActive || (pagePath != "" && hasActiveMenuChild(pagePath, url))
+		containsActive = containsActive || entry["IsActive"].(bool) || entry["HasActiveChild"].(bool)
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i]["Weight"].(float64) < entries[j]["Weight"].(float64) })
+
+	result := []interface{}{}
+	for _, entry := range entries {
+		result = append(result, entry)
+	}
+	return result, containsActive, nil
+}
+
+// normalizeMenuPath returns the site-relative path of url as it's compared to the path of pages, f.e. '/blog/' for '/blog/index.html' and 'blog'. External urls and fragments return an empty string.
+func normalizeMenuPath(url string) string {
+	if url == "" || strings.Contains(url, "://") || strings.HasPrefix(url, "//") || strings.HasPrefix(url, "mailto:") || strings.HasPrefix(url, "#") {
+		return ""
+	}
+	url = strings.SplitN(strings.SplitN(url, "#", 2)[0], "?", 2)[0]
+	if !strings.HasPrefix(url, "/") {
+		url = "/" + url
+	}
+	return strings.TrimSuffix(url, "index.html")
+}
+
+// isActiveMenuURL is the 'isActive' template function, which returns whether url points to the page at pagePath, f.e. '{{ if isActive .Page.Path "/blog/" }}'.
+func isActiveMenuURL(pagePath string, url string) bool {
+	target := normalizeMenuPath(url)
+	return target != "" && strings.TrimSuffix(target, "/") == strings.TrimSuffix(normalizeMenuPath(pagePath), "/")
+}
+
+// hasActiveMenuChild is the 'hasActiveChild' template function, which returns whether the page at pagePath is below the folder url points to, f.e. for '/blog/2023/post/' with '/blog/'.
+func hasActiveMenuChild(pagePath string, url string) bool {
+	target := normalizeMenuPath(url)
+	page := normalizeMenuPath(pagePath)
+	return strings.HasSuffix(target, "/") && target != "/" && strings.HasPrefix(page, target) && page != target
+}
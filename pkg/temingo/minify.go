@@ -0,0 +1,94 @@
+package temingo
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/tdewolff/minify/v2"
+	"github.com/tdewolff/minify/v2/css"
+	"github.com/tdewolff/minify/v2/html"
+	"github.com/tdewolff/minify/v2/js"
+)
+
+// minifier minifies html, including its inline styles and scripts, as well as css and js files.
+// The document and end tags are kept, so the output stays valid for parsers which don't implement the optional tags of html5.
+var minifier = newMinifier()
+
+// minifyMediaTypes are the media types of the minified files, by their extension.
+var minifyMediaTypes = map[string]string{
+	".html": "text/html",
+	".htm":  "text/html",
+	".css":  "text/css",
+	".js":   "application/javascript",
+	".mjs":  "application/javascript",
+}
+
+func newMinifier() *minify.M {
+	m := minify.New()
+	m.Add("text/html", &html.Minifier{KeepDocumentTags: true, KeepEndTags: true, KeepDefaultAttrVals: true})
+	m.AddFunc("text/css", css.Minify)
+	m.AddFunc("application/javascript", js.Minify)
+	return m
+}
+
+// minifyOutput returns the content of the output at outputFilePath minified, if it's html, css or js. Other content is returned unchanged.
+func (engine *Engine) minifyOutput(outputFilePath string, content []byte) ([]byte, error) {
+	mediaType, ok := minifyMediaTypes[strings.ToLower(path.Ext(outputFilePath))]
+	if !ok {
+		return content, nil
+	}
+	minified, err := minifier.Bytes(mediaType, content)
+	if err != nil {
+		return nil, errors.New("Could not minify '" + outputFilePath + "': " + err.Error())
+	}
+	return minified, nil
+}
+
+// minifyStaticFiles minifies the css and js files copied from the staticDir to the outputDir.
+func (engine *Engine) minifyStaticFiles() error {
+	if !engine.MinifyStatic {
+		return nil
+	}
+
+	engine.logDebug("*** Minifying static files ... ***")
+
+	return filepath.Walk(engine.StaticDir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		relativePath, err := filepath.Rel(engine.StaticDir, filePath)
+		if err != nil {
+			return err
+		}
+		outputPath := getStaticOutputPath(filepath.ToSlash(relativePath))
+		if outputPath == "" { // a Sass partial
+			return nil
+		}
+		return engine.minifyStaticFile(path.Join(engine.OutputDir, outputPath))
+	})
+}
+
+// minifyStaticFile minifies the copied static file at outputFilePath in place, if it's a css or js file and the minification of static files is enabled.
+func (engine *Engine) minifyStaticFile(outputFilePath string) error {
+	extension := strings.ToLower(path.Ext(outputFilePath))
+	if !engine.MinifyStatic || minifyMediaTypes[extension] == "" || minifyMediaTypes[extension] == "text/html" {
+		return nil
+	}
+	content, err := ioutil.ReadFile(outputFilePath)
+	if err != nil {
+		return err
+	}
+	minified, err := engine.minifyOutput(outputFilePath, content)
+	if err != nil {
+		return err
+	}
+	engine.logDebug("Minifying '" + outputFilePath + "' ...")
+	return engine.writeTemplateToFile(outputFilePath, minified)
+}
@@ -0,0 +1,99 @@
+package temingo
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+)
+
+// isNestedSingleTemplate returns whether the single-view template declares 'nested: true' in its front matter, so its items are searched in all subfolders instead of only the ones next to it.
+func isNestedSingleTemplate(templateName string, frontMatter map[string]interface{}) (bool, error) {
+	value, ok := frontMatter["nested"]
+	if !ok {
+		return false, nil
+	}
+	nested, ok := value.(bool)
+	if !ok {
+		return false, errors.New("The front matter 'nested' of '" + templateName + "' must be either true or false.")
+	}
+	return nested, nil
+}
+
+// getItemIndexPaths returns the index files of the items of listPath by the paths of the items. Without nested, these are the subfolders of listPath which contain an index file and the itemFiles in listPath.
+// With nested, the subfolders which are no items are searched as well, f.e. for 'blog/2023/06/post/index.yaml'. Items are leaves, so the folders inside of an item are never searched.
+func (engine *Engine) getItemIndexPaths(listPath string, nested bool) (map[string]string, error) {
+	dirContents, err := ioutil.ReadDir(path.Join(path.Clean("."), path.Clean(listPath)))
+	if err != nil {
+		return nil, err
+	}
+	itemIndexPaths := make(map[string]string)
+	for _, dirEntry := range dirContents {
+		itemPath := path.Join(listPath, dirEntry.Name())
+		if !dirEntry.IsDir() {
+			if !strings.HasPrefix(dirEntry.Name(), ".") && engine.isItemFile(itemPath) { // f.e. "idea.md" with '--itemFiles /notes/*.md'
+				if err := addItem(itemIndexPaths, engine.getItemFilePath(itemPath), itemPath); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		if indexPath, ok := engine.getItemIndexFile(itemPath); ok { // if the folder contains an index file, f.e. "index.yaml"
+			if err := addItem(itemIndexPaths, itemPath, indexPath); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if !nested || strings.HasPrefix(dirEntry.Name(), ".") || engine.isExcluded(itemPath, []string{"/" + path.Join(engine.PartialsDir, "**")}) {
+			continue
+		}
+		nestedIndexPaths, err := engine.getItemIndexPaths(itemPath, true)
+		if err != nil {
+			return nil, err
+		}
+		for nestedPath, indexPath := range nestedIndexPaths {
+			if err := addItem(itemIndexPaths, nestedPath, indexPath); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return itemIndexPaths, nil
+}
+
+// getItemAncestry returns the depth of the item at itemPath below listPath (1 for the items next to a single-view template) and the folders in between, outermost first.
+// Each of these parents has its 'Name', 'Path' and 'Values' (of its own '_index.yaml'), f.e. to render breadcrumbs like 'blog > 2023 > 06'.
+func (engine *Engine) getItemAncestry(listPath string, itemPath string) (int, []interface{}, error) {
+	parents := []interface{}{}
+	relativePath := strings.TrimPrefix(path.Clean(itemPath), path.Clean(listPath)+"/")
+	names := strings.Split(relativePath, "/")
+	parentPath := path.Clean(listPath)
+	for _, name := range names[:len(names)-1] {
+		parentPath = path.Join(parentPath, name)
+		values, err := engine.loadOwnSectionValues(parentPath)
+		if err != nil {
+			return 0, nil, err
+		}
+		parents = append(parents, map[string]interface{}{
+			"Name":   name,
+			"Path":   path.Join("/", parentPath),
+			"Values": values,
+		})
+	}
+	return len(names), parents, nil
+}
+
+// loadOwnSectionValues returns the values of the '_index.yaml' of dirPath, without the cascaded ones of its parent folders. It's empty if there is none.
+func (engine *Engine) loadOwnSectionValues(dirPath string) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	if _, err := os.Stat(path.Join(dirPath, sectionValuesFileName)); err == nil {
+		values, err = engine.loadValuesFile(path.Join(dirPath, sectionValuesFileName))
+		if err != nil {
+			return nil, err
+		}
+		if values == nil { // empty file
+			values = make(map[string]interface{})
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,95 @@
+package temingo
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// buildNotification is posted as json to the notifyWebhook after each build while watching.
+type buildNotification struct {
+	Status     string `json:"status"`           // 'success' or 'failure'
+	Message    string `json:"message"`          // the message of the desktop notification
+	Rendered   int    `json:"rendered"`         // number of rendered outputs
+	Copied     int    `json:"copied"`           // number of copied files
+	Skipped    int    `json:"skipped"`          // number of ignored files and unpublished content
+	DurationMs int64  `json:"durationMs"`       // duration of the build
+	Errors     string `json:"errors,omitempty"` // the errors of a failed build
+}
+
+// notifyBuild sends a desktop notification and posts to the notifyWebhook after a build while watching, if enabled, so broken builds are noticed while working in another window.
+// Both are sent in the background, failing to send them is only logged as warning.
+func (engine *Engine) notifyBuild(err error) {
+	if !engine.Notify && engine.NotifyWebhook == "" {
+		return
+	}
+	notification := buildNotification{Status: "success"}
+	if summary := engine.summary; summary != nil {
+		notification.Rendered, notification.Copied, notification.Skipped = summary.rendered, summary.copied, len(summary.skipped)
+		notification.DurationMs = time.Since(summary.started).Milliseconds()
+	}
+	if err != nil {
+		notification.Status, notification.Errors = "failure", err.Error()
+		errorCount := 1
+		if errs, ok := err.(BuildErrors); ok {
+			errorCount = len(errs)
+		}
+		notification.Message = "Build failed with " + strconv.Itoa(errorCount) + " error(s): " + strings.SplitN(err.Error(), "\n", 2)[0]
+	} else {
+		notification.Message = "Built contents (" + strconv.Itoa(notification.Rendered) + " rendered, " + strconv.Itoa(notification.Copied) + " copied, " + strconv.Itoa(notification.Skipped) + " skipped in " + strconv.FormatInt(notification.DurationMs, 10) + "ms)"
+	}
+
+	if engine.Notify {
+		go engine.sendDesktopNotification(notification.Message)
+	}
+	if engine.NotifyWebhook != "" {
+		go engine.postBuildNotification(notification)
+	}
+}
+
+// sendDesktopNotification shows message as desktop notification, via 'notify-send' on linux, 'osascript' on macOS and PowerShell on Windows.
+func (engine *Engine) sendDesktopNotification(message string) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "linux", "freebsd", "openbsd", "netbsd":
+		cmd = exec.Command("notify-send", "temingo", message)
+	case "darwin":
+		cmd = exec.Command("osascript", "-e", `display notification "`+escapeAppleScript(message)+`" with title "temingo"`)
+	case "windows":
+		cmd = exec.Command("powershell", "-NoProfile", "-Command", "Add-Type -AssemblyName System.Windows.Forms; $notification = New-Object System.Windows.Forms.NotifyIcon; $notification.Icon = [System.Drawing.SystemIcons]::Information; $notification.Visible = $true; $notification.ShowBalloonTip(5000, 'temingo', '"+strings.ReplaceAll(message, "'", "''")+"', 'Info'); Start-Sleep -Seconds 5; $notification.Dispose()")
+	default:
+		engine.logWarn("Desktop notifications are not supported on " + runtime.GOOS + ".")
+		return
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		engine.logWarn("Could not send the desktop notification: " + strings.TrimSpace(err.Error()+" "+string(output)))
+	}
+}
+
+func escapeAppleScript(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, `\`, `\\`), `"`, `\"`)
+}
+
+// postBuildNotification posts the notification as json to the notifyWebhook.
+func (engine *Engine) postBuildNotification(notification buildNotification) {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		engine.logWarn("Could not post the build notification: " + err.Error())
+		return
+	}
+	client := http.Client{Timeout: 10 * time.Second}
+	response, err := client.Post(engine.NotifyWebhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		engine.logWarn("Could not post the build notification: " + err.Error())
+		return
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		engine.logWarn("Could not post the build notification: unexpected status '" + response.Status + "'")
+	}
+}
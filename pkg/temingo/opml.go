@@ -0,0 +1,154 @@
+package temingo
+
+import (
+	"encoding/xml"
+	"errors"
+	"io/ioutil"
+	"path"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const opmlConfigFileName = "opml.yaml"
+
+func init() {
+	configFileNames = append(configFileNames, opmlConfigFileName)
+}
+
+// opmlConfig is the content of an 'opml.yaml' file, which results in an OPML file in the corresponding folder of the outputDir.
+type opmlConfig struct {
+	Title    string        `yaml:"title"`
+	Output   string        `yaml:"output"`   // file name of the OPML file, relative to the folder in the outputDir
+	Sections []opmlOutline `yaml:"sections"` // sections/feeds of the site itself
+	Blogroll string        `yaml:"blogroll"` // optional path to a yaml file containing a list of external feeds
+}
+
+type opmlOutline struct {
+	Title    string        `yaml:"title" xml:"title,attr"`
+	Text     string        `yaml:"-" xml:"text,attr"`
+	Type     string        `yaml:"type" xml:"type,attr,omitempty"`
+	Path     string        `yaml:"path" xml:"htmlUrl,attr,omitempty"`
+	Feed     string        `yaml:"feed" xml:"xmlUrl,attr,omitempty"`
+	HtmlUrl  string        `yaml:"htmlUrl" xml:"-"`
+	XmlUrl   string        `yaml:"xmlUrl" xml:"-"`
+	Category string        `yaml:"category" xml:"category,attr,omitempty"`
+	Outlines []opmlOutline `yaml:"-" xml:"outline"`
+}
+
+type opmlDocument struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    struct {
+		Title       string `xml:"title"`
+		DateCreated string `xml:"dateCreated"`
+	} `xml:"head"`
+	Body struct {
+		Outlines []opmlOutline `xml:"outline"`
+	} `xml:"body"`
+}
+
+// exportOpmls creates an OPML file for each 'opml.yaml' in the inputDir.
+// The configured sections of the site are listed first, followed by the feeds of the optional blogroll file.
+func (engine *Engine) exportOpmls() error {
+	configPaths, err := engine.getConfigFiles(opmlConfigFileName)
+	if err != nil {
+		return err
+	}
+	for _, configPath := range configPaths {
+		folderPath := path.Dir(configPath)
+
+		config := opmlConfig{}
+		if err := loadConfigFile(configPath, &config); err != nil {
+			return err
+		}
+		if config.Output == "" {
+			config.Output = "feeds.opml"
+		}
+		if !engine.isChangedExport(configPath, config.Blogroll) { // the file only depends on them, not on the contents of its folder
+			continue
+		}
+
+		document := opmlDocument{Version: "2.0"}
+		document.Head.Title = config.Title
+		inputPaths := []string{configPath}
+		if config.Blogroll != "" {
+			inputPaths = append(inputPaths, config.Blogroll)
+		}
+		if created, ok := getLastModification(inputPaths); ok { // instead of the time of the build, so the file only changes together with its inputs
+			document.Head.DateCreated = created.UTC().Format(time.RFC1123Z)
+		}
+
+		for _, section := range config.Sections {
+			section.Path = engine.absoluteURL(section.Path)
+			section.Feed = engine.absoluteURL(section.Feed)
+			document.Body.Outlines = append(document.Body.Outlines, completeOpmlOutline(section))
+		}
+
+		if config.Blogroll != "" {
+			entries, err := loadBlogroll(config.Blogroll)
+			if err != nil {
+				return err
+			}
+			blogroll := opmlOutline{Title: "Blogroll", Text: "Blogroll"}
+			for _, entry := range entries {
+				if entry.Path == "" {
+					entry.Path = entry.HtmlUrl
+				}
+				if entry.Feed == "" {
+					entry.Feed = entry.XmlUrl
+				}
+				blogroll.Outlines = append(blogroll.Outlines, completeOpmlOutline(entry))
+			}
+			document.Body.Outlines = append(document.Body.Outlines, blogroll)
+		}
+
+		content, err := xml.MarshalIndent(document, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		outputFilePath, err := engine.getOutputFilePath(folderPath, config.Output)
+		if err != nil {
+			return err
+		}
+		engine.logDebug("Writing OPML file '" + outputFilePath + "' ...")
+		err = engine.writeTemplateToFile(outputFilePath, append([]byte(xml.Header), append(content, '\n')...))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// completeOpmlOutline sets the values OPML readers expect to be present.
+func completeOpmlOutline(outline opmlOutline) opmlOutline {
+	outline.Text = outline.Title
+	if outline.Type == "" && outline.Feed != "" {
+		outline.Type = "rss"
+	}
+	return outline
+}
+
+func loadBlogroll(filePath string) ([]opmlOutline, error) {
+	entries := []opmlOutline{}
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	err = yaml.Unmarshal(content, &entries)
+	if err != nil {
+		return nil, errors.New("Could not parse blogroll '" + filePath + "': " + err.Error())
+	}
+	return entries, nil
+}
+
+// absoluteURL prefixes site-relative paths with the baseURL. Empty values and already absolute URLs are returned unchanged.
+func (engine *Engine) absoluteURL(url string) string {
+	if url == "" || engine.siteBaseURL == "" || strings.Contains(url, "://") {
+		return url
+	}
+	return strings.TrimSuffix(engine.siteBaseURL, "/") + "/" + strings.TrimPrefix(url, "/")
+}
@@ -0,0 +1,48 @@
+package temingo
+
+import (
+	"errors"
+	"path"
+	"strings"
+
+	gitignore "github.com/sabhiram/go-gitignore"
+)
+
+// outputNameRule is one of the outputNames rules: the pages whose source matches the gitignore pattern get the output path of the permalink pattern.
+type outputNameRule struct {
+	pattern   string
+	permalink string
+}
+
+// getOutputNameRules parses the outputNames option. Each rule is a gitignore pattern and a permalink pattern separated by '=', f.e. '*.template=:dir/:slug/'.
+func (engine *Engine) getOutputNameRules() ([]outputNameRule, error) {
+	rules := []outputNameRule{}
+	for _, rule := range engine.OutputNames {
+		parts := strings.SplitN(rule, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, errors.New("The output name rule '" + rule + "' must be a pattern and a permalink separated by '=', f.e. '*.template=:dir/:slug/'.")
+		}
+		rules = append(rules, outputNameRule{strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])})
+	}
+	return rules, nil
+}
+
+// getOutputNamePermalink returns the permalink pattern of the first outputNames rule matching the page at source, or an empty string if none does.
+// Rules resulting in a folder don't apply to index files, so f.e. 'blog/index.html.template' stays 'blog/index.html' instead of becoming 'blog/index/index.html'.
+func (engine *Engine) getOutputNamePermalink(source string, defaultPath string) (string, error) {
+	rules, err := engine.getOutputNameRules()
+	if err != nil {
+		return "", err
+	}
+	base := path.Base(defaultPath)
+	isIndex := strings.TrimSuffix(base, path.Ext(base)) == "index"
+	for _, rule := range rules {
+		if isIndex && strings.HasSuffix(rule.permalink, "/") {
+			continue
+		}
+		if gitignore.CompileIgnoreLines(rule.pattern).MatchesPath("/" + source) {
+			return rule.permalink, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,45 @@
+package temingo
+
+import (
+	"errors"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// getOutputFilePath joins the elements to a path inside the outputDir.
+// As the elements might come from user input (like config files, front matter or values), absolute paths and '..' traversal are rejected.
+func (engine *Engine) getOutputFilePath(elements ...string) (string, error) {
+	for _, element := range elements {
+		if strings.HasPrefix(element, "/") || strings.HasPrefix(element, "\\") || filepath.IsAbs(element) || filepath.VolumeName(element) != "" {
+			return "", errors.New("The output path '" + element + "' must be relative to the output-directory, but is absolute.")
+		}
+		for _, segment := range strings.FieldsFunc(element, func(r rune) bool { return r == '/' || r == '\\' }) {
+			if segment == ".." {
+				return "", errors.New("The output path '" + element + "' must not contain '..', as it could point outside of the output-directory.")
+			}
+		}
+	}
+	return path.Join(append([]string{engine.OutputDir, engine.getLanguagePrefix()}, elements...)...), nil
+}
+
+// checkOutputFilePath returns an error if filePath doesn't resolve to a location inside the outputDir.
+// It's the safety net for every write, independent of how the path was computed.
+func (engine *Engine) checkOutputFilePath(filePath string) error {
+	absoluteOutputDir, err := filepath.Abs(engine.OutputDir)
+	if err != nil {
+		return err
+	}
+	absoluteFilePath, err := filepath.Abs(filePath)
+	if err != nil {
+		return err
+	}
+	relativePath, err := filepath.Rel(absoluteOutputDir, absoluteFilePath)
+	if err != nil {
+		return err
+	}
+	if relativePath == "." || relativePath == ".." || strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+		return errors.New("Refusing to write '" + filePath + "', as it is not inside the output-directory '" + engine.OutputDir + "'.")
+	}
+	return nil
+}
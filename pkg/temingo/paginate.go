@@ -0,0 +1,91 @@
+package temingo
+
+import (
+	"errors"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// paginatedPage is one page of a paginated list template.
+type paginatedPage struct {
+	OutputPath string                 // path relative to the outputDir, f.e. 'blog/page/2/index.html'
+	Paginator  map[string]interface{} // available as '.Paginator' in the template
+}
+
+// getPaginatedPages returns the pages of a template that declares 'paginate' in its front matter, and false if it doesn't.
+// 'paginate.size' is the number of list objects per page, 'paginate.list' the (optional) folder of the list objects and 'paginate.sortBy' their (optional) order, like for 'list' and the 'sortBy' of the 'epub.yaml'.
+// The list defaults to the folder of the template, `paginate: 10` is short for 'paginate: {size: 10}'.
+// F.e. 'blog/index.html.template' results in 'blog/index.html', 'blog/page/2/index.html', 'blog/page/3/index.html' and so on.
+func (engine *Engine) getPaginatedPages(templateName string, frontMatter map[string]interface{}) ([]paginatedPage, bool, error) {
+	paginate, ok := frontMatter["paginate"]
+	if !ok {
+		return nil, false, nil
+	}
+	if _, ok := frontMatter["generate"]; ok {
+		return nil, true, errors.New("The front matter of '" + templateName + "' can contain either 'generate' or 'paginate', but not both.")
+	}
+
+	config, ok := paginate.(map[string]interface{})
+	if !ok {
+		config = map[string]interface{}{"size": paginate}
+	}
+	size, ok := toFloat(config["size"])
+	if !ok || size < 1 || size != float64(int(size)) {
+		return nil, true, errors.New("The front matter 'paginate' of '" + templateName + "' must contain a positive 'size', but is '" + toString(config["size"]) + "'.")
+	}
+	listPath := toString(config["list"])
+	if listPath == "" {
+		listPath = path.Dir(templateName)
+	}
+
+	listObjects, err := engine.getSortedListObjects(listPath, toString(config["sortBy"]), false)
+	if err != nil {
+		return nil, true, err
+	}
+	items := []interface{}{}
+	for _, listObject := range listObjects {
+		items = append(items, listObject)
+	}
+
+	outputPath := engine.trimLanguage(strings.TrimSuffix(templateName, engine.TemplateExtension))
+	totalPages := (len(items) + int(size) - 1) / int(size)
+	if totalPages == 0 { // an empty list still results in the first page
+		totalPages = 1
+	}
+
+	pages := []paginatedPage{}
+	for pageNumber := 1; pageNumber <= totalPages; pageNumber++ {
+		start := (pageNumber - 1) * int(size)
+		end := start + int(size)
+		if end > len(items) {
+			end = len(items)
+		}
+		paginator := map[string]interface{}{
+			"Items":      items[start:end],
+			"PageNumber": pageNumber,
+			"TotalPages": totalPages,
+			"PrevPage":   "",
+			"NextPage":   "",
+		}
+		if pageNumber > 1 {
+			paginator["PrevPage"] = "/" + getPaginatedOutputPath(outputPath, pageNumber-1)
+		}
+		if pageNumber < totalPages {
+			paginator["NextPage"] = "/" + getPaginatedOutputPath(outputPath, pageNumber+1)
+		}
+		pages = append(pages, paginatedPage{
+			OutputPath: getPaginatedOutputPath(outputPath, pageNumber),
+			Paginator:  paginator,
+		})
+	}
+	return pages, true, nil
+}
+
+// getPaginatedOutputPath returns the path of the page with the given number, f.e. 'blog/page/2/index.html' for 'blog/index.html'. The first page keeps the path of the template.
+func getPaginatedOutputPath(outputPath string, pageNumber int) string {
+	if pageNumber == 1 {
+		return outputPath
+	}
+	return path.Join(path.Dir(outputPath), "page", strconv.Itoa(pageNumber), path.Base(outputPath))
+}
@@ -0,0 +1,89 @@
+package temingo
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var paramRegexp = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)(?::([a-z]+))?(\?|=(.*))?$`) // 'name', 'name:type', 'name:type?' or 'name:type=default'
+
+// partialParam is a parameter a partial declares via 'params'.
+type partialParam struct {
+	name       string
+	field      schemaField
+	hasDefault bool
+	defaultVal interface{}
+}
+
+// parsePartialParam parses the declaration of a parameter, f.e. 'title:string' (required), 'level:number=2' (with default) or 'items:list?' (optional). The type defaults to 'any'.
+// Defaults are parsed as yaml, so '2' is a number and 'true' a bool.
+func parsePartialParam(declaration string) (partialParam, error) {
+	match := paramRegexp.FindStringSubmatch(strings.TrimSpace(declaration))
+	if match == nil {
+		return partialParam{}, errors.New("the parameter '" + declaration + "' must be declared like 'name:type', 'name:type?' or 'name:type=default'")
+	}
+	param := partialParam{name: match[1], field: schemaField{Type: match[2], Required: match[3] == ""}}
+	if err := checkSchemaField(&param.field, param.name); err != nil {
+		return partialParam{}, err
+	}
+	if strings.HasPrefix(match[3], "=") {
+		param.hasDefault = true
+		if err := yaml.Unmarshal([]byte(match[4]), &param.defaultVal); err != nil {
+			return partialParam{}, errors.New("the default of the parameter '" + param.name + "' is invalid: " + err.Error())
+		}
+	}
+	return param, nil
+}
+
+// getPartialParams is the 'params' template function, which declares the parameters a partial expects, f.e. '{{ $p := params . "title:string" "level:number=2" }}'.
+// It returns the arguments the partial was included with, completed with the defaults, or an error if arguments are missing, have the wrong type or aren't declared.
+// The arguments are passed as map, f.e. '{{ include "card" (dict "title" "Hello") }}'.
+func getPartialParams(data interface{}, declarations ...string) (map[string]interface{}, error) {
+	args, ok := data.(map[string]interface{})
+	if !ok && data != nil {
+		return nil, errors.New("params: the arguments must be a map, f.e. '(dict \"title\" \"Hello\")', but are '" + toString(data) + "'")
+	}
+	params := make(map[string]interface{})
+	declared := []string{}
+	isDeclared := make(map[string]bool)
+	mismatches := []string{}
+	for _, declaration := range declarations {
+		param, err := parsePartialParam(declaration)
+		if err != nil {
+			return nil, errors.New("params: " + err.Error())
+		}
+		declared = append(declared, param.name)
+		isDeclared[param.name] = true
+		value, ok := args[param.name]
+		switch {
+		case ok && value != nil:
+			params[param.name] = value
+			mismatches = append(mismatches, validateValue(value, &param.field, param.name)...)
+		case param.hasDefault:
+			params[param.name] = param.defaultVal
+		case param.field.Required:
+			mismatches = append(mismatches, "the required parameter '"+param.name+"' is missing")
+		default:
+			params[param.name] = nil
+		}
+	}
+	for name := range args {
+		if isDeclared[name] {
+			continue
+		}
+		message := "the parameter '" + name + "' is unknown"
+		if suggestion := getClosestKey(name, declared); suggestion != "" {
+			message += " (did you mean '" + suggestion + "'?)"
+		}
+		mismatches = append(mismatches, message)
+	}
+	if len(mismatches) > 0 {
+		sort.Strings(mismatches)
+		return nil, errors.New("params: " + strings.Join(mismatches, ", "))
+	}
+	return params, nil
+}
@@ -0,0 +1,82 @@
+package temingo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// getPartialTemplates returns the name and content of all partials.
+// The name is the path of the partial relative to the partialsDir without extension, f.e. 'nav/header' for 'partials/nav/header.partial', so each partial can be included by its location as well as by the templates it defines.
+// Partials are read freshly for every build and files vanishing in the meantime are skipped, so partials can be created, moved and deleted while watching.
+func (engine *Engine) getPartialTemplates() ([][]string, error) {
+	var partials [][]string
+
+	if _, err := os.Stat(engine.PartialsDir); os.IsNotExist(err) {
+		engine.logWarn("The partials-directory '" + engine.PartialsDir + "' does not exist (anymore), continuing without partials.")
+		return partials, nil
+	}
+
+	err := filepath.Walk(engine.PartialsDir, func(filePath string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) { // deleted since the folder was listed
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if filePath != engine.PartialsDir && strings.HasPrefix(info.Name(), ".") { // ignore hidden files/folders
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() || !strings.HasSuffix(info.Name(), engine.PartialExtension) {
+			return nil
+		}
+
+		fileContent, err := ioutil.ReadFile(filePath)
+		if os.IsNotExist(err) {
+			engine.logDebug("Skipping partial '" + filePath + "', as it was deleted in the meantime.")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		relativePath, err := filepath.Rel(engine.PartialsDir, filePath)
+		if err != nil {
+			return err
+		}
+		partialName := strings.TrimSuffix(filepath.ToSlash(relativePath), engine.PartialExtension)
+		engine.logDebug("Registering partial '" + partialName + "' from '" + filePath + "'.")
+		partials = append(partials, []string{partialName, string(fileContent)})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return partials, nil
+}
+
+// rewatchPartials adds the partialsDir to the watcher again, if it was deleted and recreated while watching.
+// Returns whether it was added again.
+func (engine *Engine) rewatchPartials(w fileWatcher) bool {
+	absolutePath, err := filepath.Abs(engine.PartialsDir)
+	if err != nil {
+		engine.logWarn("Could not watch the recreated partials-directory: " + err.Error())
+		return false
+	}
+	if _, err := os.Stat(engine.PartialsDir); err != nil { // not (yet) recreated
+		return false
+	}
+	if w.isWatched(absolutePath) { // still watched
+		return false
+	}
+	if err := w.addRecursive(engine.PartialsDir); err != nil {
+		engine.logWarn("Could not watch the recreated partials-directory: " + err.Error())
+		return false
+	}
+	return true
+}
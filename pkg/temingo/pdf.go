@@ -0,0 +1,67 @@
+package temingo
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	gitignore "github.com/sabhiram/go-gitignore"
+)
+
+// exportPdfs converts all rendered files in the outputDir which match one of the pdfPatterns to PDF.
+// The conversion itself is done by the external pdfCommand, where '{input}' and '{output}' are replaced with the respective paths.
+func (engine *Engine) exportPdfs() error {
+	if len(engine.PdfPatterns) == 0 { // if pdf export is not configured
+		return nil
+	}
+
+	engine.logDebug("*** Exporting pages to PDF ... ***")
+
+	matcher := gitignore.CompileIgnoreLines(engine.PdfPatterns...)
+
+	return filepath.Walk(engine.OutputDir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || strings.HasSuffix(filePath, ".pdf") { // skip folders and already exported files
+			return nil
+		}
+		if engine.renderedFiles != nil && !engine.renderedFiles[filepath.ToSlash(filePath)] { // unchanged by an incremental rebuild
+			return nil
+		}
+		relativePath, err := filepath.Rel(engine.OutputDir, filePath)
+		if err != nil {
+			return err
+		}
+		if matcher.MatchesPath("/" + filepath.ToSlash(relativePath)) {
+			pdfPath := strings.TrimSuffix(filePath, filepath.Ext(filePath)) + ".pdf" // f.e. output/invoice.html -> output/invoice.pdf
+			return engine.runFileCommand(engine.PdfCommand, "PDF export", filePath, pdfPath)
+		}
+		return nil
+	})
+}
+
+// runFileCommand runs the external command which converts the file at inputPath to outputPath, where '{input}' and '{output}' are replaced with the respective paths.
+// purpose describes the conversion in errors, f.e. 'PDF export'.
+func (engine *Engine) runFileCommand(command string, purpose string, inputPath string, outputPath string) error {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return errors.New("The command for the " + purpose + " must not be empty.")
+	}
+	for i, arg := range args {
+		arg = strings.ReplaceAll(arg, "{input}", inputPath)
+		args[i] = strings.ReplaceAll(arg, "{output}", outputPath)
+	}
+
+	engine.logDebug("Converting '" + inputPath + "' to '" + outputPath + "' via '" + strings.Join(args, " ") + "' ...")
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return errors.New(purpose + " of '" + inputPath + "' failed: " + err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package temingo
+
+import (
+	"errors"
+	"io/ioutil"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var permalinkTokenRegexp = regexp.MustCompile(`:([a-z]+)`)
+
+// resolvePermalink replaces the tokens of the permalink declared by source with the values of the page or item and returns the path relative to the outputDir. A trailing '/' is kept.
+// defaultPath is the path the output would have without permalink. It provides ':slug', its file or folder name without extension, ':file', its file name including the extension,
+// ':dir', the folder containing it, and ':section', its top-level folder.
+// ':year', ':month' and ':day' are taken from the 'date' value, ':title' is the urlized 'title' value.
+func (engine *Engine) resolvePermalink(source string, permalink string, values map[string]interface{}, defaultPath string) (string, error) {
+	var resolveErr error
+	resolved := permalinkTokenRegexp.ReplaceAllStringFunc(permalink, func(token string) string {
+		if resolveErr != nil {
+			return ""
+		}
+		switch token {
+		case ":slug":
+			base := path.Base(defaultPath)
+			return strings.TrimSuffix(base, path.Ext(base))
+		case ":file":
+			return path.Base(defaultPath)
+		case ":dir":
+			if dir := path.Dir(defaultPath); dir != "." {
+				return dir
+			}
+			return ""
+		case ":section":
+			return getSection("/" + defaultPath)
+		case ":year", ":month", ":day":
+			date, ok := toTime(values["date"])
+			if !ok {
+				resolveErr = errors.New("The permalink '" + permalink + "' of '" + source + "' contains '" + token + "', but there is no valid 'date' value.")
+				return ""
+			}
+			return date.Format(map[string]string{":year": "2006", ":month": "01", ":day": "02"}[token])
+		case ":title":
+			title := toString(values["title"])
+			if title == "" {
+				resolveErr = errors.New("The permalink '" + permalink + "' of '" + source + "' contains ':title', but there is no 'title' value.")
+				return ""
+			}
+			urlized, err := engine.urlize(title)
+			resolveErr = err
+			return urlized
+		}
+		resolveErr = errors.New("The permalink '" + permalink + "' of '" + source + "' contains the unknown token '" + token + "', supported are ':year', ':month', ':day', ':title', ':slug', ':file', ':dir' and ':section'.")
+		return ""
+	})
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+	for strings.Contains(resolved, "//") { // empty tokens, f.e. ':dir' at the top level
+		resolved = strings.ReplaceAll(resolved, "//", "/")
+	}
+	resolved = strings.TrimPrefix(resolved, "/") // permalinks are site-relative
+	if strings.Trim(resolved, "/") == "" {
+		return "", errors.New("The permalink '" + permalink + "' of '" + source + "' results in an empty path.")
+	}
+	return resolved, nil
+}
+
+// getPageOutputPath returns the path of the page relative to the outputDir, which is defaultPath unless its front matter declares a 'permalink' or one of the outputNames rules matches it.
+// A permalink ending with '/' is a folder, which contains the page as its index file with the extension of defaultPath, f.e. 'index.html', or '.html' if defaultPath has none.
+func (engine *Engine) getPageOutputPath(source string, frontMatter map[string]interface{}, defaultPath string) (string, error) {
+	permalink := toString(frontMatter["permalink"])
+	if permalink == "" {
+		rulePermalink, err := engine.getOutputNamePermalink(source, defaultPath)
+		if err != nil {
+			return "", err
+		}
+		permalink = rulePermalink
+	}
+	if permalink == "" {
+		return defaultPath, nil
+	}
+	resolved, err := engine.resolvePermalink(source, permalink, frontMatter, defaultPath)
+	if err != nil {
+		return "", err
+	}
+	if strings.HasSuffix(resolved, "/") {
+		extension := path.Ext(defaultPath)
+		if extension == "" {
+			extension = ".html"
+		}
+		resolved += "index" + extension
+	}
+	return resolved, nil
+}
+
+// getItemOutputPath returns the path of the folder of the item relative to the outputDir, which is defaultPath unless permalink is set.
+// The single-view templates of the item are rendered into that folder.
+func (engine *Engine) getItemOutputPath(source string, permalink string, values map[string]interface{}, defaultPath string) (string, error) {
+	if permalink == "" {
+		return defaultPath, nil
+	}
+	resolved, err := engine.resolvePermalink(source, permalink, values, defaultPath)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(resolved, "/"), nil
+}
+
+// loadItemOutputPath returns the output folder of the item with the index file at indexPath, as declared by the 'permalink' of its values, including the cascaded section values.
+// Only the values are loaded, so the content of markdown index files isn't converted for it.
+func (engine *Engine) loadItemOutputPath(itemDir string, indexPath string, defaultPath string) (string, error) {
+	sectionValues, err := engine.getSectionValues(itemDir)
+	if err != nil {
+		return "", err
+	}
+	var values map[string]interface{}
+	if engine.isMarkdownFile(indexPath) {
+		content, err := ioutil.ReadFile(indexPath)
+		if err != nil {
+			return "", err
+		}
+		values, _, _, err = parseFrontMatter(indexPath, string(content))
+		if err != nil {
+			return "", err
+		}
+	} else {
+		values, err = engine.loadItemIndexFile(indexPath)
+		if err != nil {
+			return "", err
+		}
+	}
+	values = mergeValues(sectionValues, values)
+	return engine.getItemOutputPath(indexPath, toString(values["permalink"]), values, defaultPath)
+}
@@ -0,0 +1,134 @@
+package temingo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"time"
+)
+
+// profileEntry contains the number of calls and the time spent for a template, an included partial or a loaded list while profiling.
+type profileEntry struct {
+	kind     string // 'template', 'include' or 'list'
+	name     string
+	calls    int
+	duration time.Duration
+}
+
+// recordProfile adds a call of the named template, partial, list or exec function to the profile. It's called concurrently, so the profile is guarded by the lock of the engine.
+func (engine *Engine) recordProfile(kind string, name string, start time.Time) {
+	duration := time.Since(start)
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+	key := kind + ":" + name
+	entry, ok := engine.profile[key]
+	if !ok {
+		entry = &profileEntry{kind: kind, name: name}
+		engine.profile[key] = entry
+	}
+	entry.calls++
+	entry.duration += duration
+}
+
+// logProfile logs the recorded profile, ordered by the time spent, so the slowest templates and partials are listed first.
+// The times of includes and lists are part of the times of the templates using them.
+func (engine *Engine) logProfile() {
+	entries := []*profileEntry{}
+	for _, entry := range engine.profile {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].duration != entries[j].duration {
+			return entries[i].duration > entries[j].duration
+		}
+		return entries[i].kind+entries[i].name < entries[j].kind+entries[j].name
+	})
+
+	lines := []string{fmt.Sprintf("%-8s %12s %7s %12s  %s", "kind", "total", "calls", "average", "name")}
+	for _, entry := range entries {
+		average := entry.duration / time.Duration(entry.calls)
+		lines = append(lines, fmt.Sprintf("%-8s %12s %7d %12s  %s", entry.kind, entry.duration.Round(time.Microsecond), entry.calls, average.Round(time.Microsecond), entry.name))
+	}
+	engine.logInfo("*** Template profile: ***\n" + strings.Join(lines, "\n"))
+}
+
+// phaseTiming is the time spent in a phase of a build while profiling, f.e. copying the static-dir.
+type phaseTiming struct {
+	name     string
+	duration time.Duration
+}
+
+// isProfilingTemplates returns whether the time spent per template, included partial and list is recorded, which is part of the profile as well.
+func (engine *Engine) isProfilingTemplates() bool {
+	return engine.ProfileTemplates || engine.Profile
+}
+
+// recordPhase adds the time since start to the phase with name, if the profile option is set. Phases running once per language are summed up.
+func (engine *Engine) recordPhase(name string, start time.Time) {
+	if !engine.Profile {
+		return
+	}
+	duration := time.Since(start)
+	for _, phase := range engine.phases {
+		if phase.name == name {
+			phase.duration += duration
+			return
+		}
+	}
+	engine.phases = append(engine.phases, &phaseTiming{name: name, duration: duration})
+}
+
+// logPhases logs the time spent per phase of the build which started at start, in the order they ran, if the profile option is set.
+// Phases which aren't measured on their own, like running the build hooks, are part of the total only.
+func (engine *Engine) logPhases(start time.Time) {
+	if !engine.Profile {
+		return
+	}
+	total := time.Since(start)
+	lines := []string{fmt.Sprintf("%-24s %12s %6s", "phase", "total", "share")}
+	for _, phase := range engine.phases {
+		lines = append(lines, fmt.Sprintf("%-24s %12s %5.1f%%", phase.name, phase.duration.Round(time.Microsecond), 100*phase.duration.Seconds()/total.Seconds()))
+	}
+	lines = append(lines, fmt.Sprintf("%-24s %12s", "build", total.Round(time.Microsecond)))
+	engine.logInfo("*** Build profile: ***\n" + strings.Join(lines, "\n"))
+}
+
+// startCPUProfile starts writing the pprof cpu profile to 'cpu.pprof' in the profileDir, if it's set. The returned function stops it and writes the heap profile to 'heap.pprof'.
+func (engine *Engine) startCPUProfile() (func() error, error) {
+	if engine.ProfileDir == "" {
+		return func() error { return nil }, nil
+	}
+	if err := os.MkdirAll(engine.ProfileDir, engine.dirMode); err != nil {
+		return nil, err
+	}
+	cpuFile, err := os.Create(filepath.Join(engine.ProfileDir, "cpu.pprof"))
+	if err != nil {
+		return nil, err
+	}
+	if err := pprof.StartCPUProfile(cpuFile); err != nil {
+		cpuFile.Close()
+		return nil, errors.New("Could not start the cpu profile: " + err.Error())
+	}
+	return func() error {
+		pprof.StopCPUProfile()
+		if err := cpuFile.Close(); err != nil {
+			return err
+		}
+		heapFile, err := os.Create(filepath.Join(engine.ProfileDir, "heap.pprof"))
+		if err != nil {
+			return err
+		}
+		defer heapFile.Close()
+		runtime.GC() // so the heap profile contains the allocations of the whole build
+		if err := pprof.WriteHeapProfile(heapFile); err != nil {
+			return err
+		}
+		engine.logInfo("Wrote the cpu and heap profiles of the build to '" + engine.ProfileDir + "', view them with 'go tool pprof -http=: " + filepath.Join(engine.ProfileDir, "cpu.pprof") + "'.")
+		return nil
+	}, nil
+}
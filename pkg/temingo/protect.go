@@ -0,0 +1,108 @@
+package temingo
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	protectIterations = 100000 // the PBKDF2 iterations the key is derived from the password with, the browser repeats them to decrypt
+	protectSaltLength = 16
+)
+
+// isProtected returns whether the page, item or template with the given values is encrypted, as declared via 'protected: true' in its front matter or values.
+func isProtected(values map[string]interface{}) bool {
+	protected, ok := values["protected"].(bool)
+	return ok && protected
+}
+
+// protectOutput encrypts the html output with the protectPassword and returns a page which decrypts it in the browser once the password is entered.
+// The key is derived via PBKDF2 with SHA-256 and a random salt, the page is encrypted with AES-GCM, so the Web Crypto API of the browser can decrypt it without further scripts.
+func (engine *Engine) protectOutput(outputFilePath string, output []byte) ([]byte, error) {
+	if engine.ProtectPassword == "" {
+		return nil, errors.New("'" + outputFilePath + "' has 'protected' set, but no password is set via '--protectPassword' or the 'TEMINGO_PROTECT_PASSWORD' environment variable.")
+	}
+	salt := make([]byte, protectSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	key := pbkdf2.Key([]byte(engine.ProtectPassword), salt, protectIterations, 32, sha256.New)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	encrypted, _ := json.Marshal(map[string]string{ // can't fail for strings, and escapes '<', so it can't end the script
+		"salt":       base64.StdEncoding.EncodeToString(salt),
+		"iv":         base64.StdEncoding.EncodeToString(nonce),
+		"ciphertext": base64.StdEncoding.EncodeToString(gcm.Seal(nil, nonce, output, nil)), // contains the authentication tag at its end, as the Web Crypto API expects it
+	})
+	return []byte(getProtectedPage(string(encrypted))), nil
+}
+
+// getProtectedPage returns the html page asking for the password and decrypting the encrypted page with it.
+// The password is kept in the sessionStorage, so it's only asked for once per visit.
+func getProtectedPage(encrypted string) string {
+	return `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Protected page</title>
+</head>
+<body>
+<form id="temingo-protected">
+<p>This page is protected, please enter the password.</p>
+<input type="password" name="password" autocomplete="current-password" autofocus required>
+<button type="submit">Unlock</button>
+<p id="temingo-protected-error" hidden>The password is wrong.</p>
+</form>
+<script>
+(function () {
+  var encrypted = ` + encrypted + `;
+  var decode = function (value) { return Uint8Array.from(atob(value), function (c) { return c.charCodeAt(0); }); };
+  var unlock = function (password) {
+    return crypto.subtle.importKey("raw", new TextEncoder().encode(password), "PBKDF2", false, ["deriveKey"]).then(function (material) {
+      return crypto.subtle.deriveKey({name: "PBKDF2", salt: decode(encrypted.salt), iterations: ` + strconv.Itoa(protectIterations) + `, hash: "SHA-256"}, material, {name: "AES-GCM", length: 256}, false, ["decrypt"]);
+    }).then(function (key) {
+      return crypto.subtle.decrypt({name: "AES-GCM", iv: decode(encrypted.iv)}, key, decode(encrypted.ciphertext));
+    }).then(function (page) {
+      sessionStorage.setItem("temingo-protected", password);
+      document.open();
+      document.write(new TextDecoder().decode(page));
+      document.close();
+    });
+  };
+  var form = document.getElementById("temingo-protected");
+  form.addEventListener("submit", function (event) {
+    event.preventDefault();
+    unlock(form.password.value).catch(function () {
+      document.getElementById("temingo-protected-error").hidden = false;
+    });
+  });
+  var remembered = sessionStorage.getItem("temingo-protected");
+  if (remembered) {
+    unlock(remembered).catch(function () {});
+  }
+})();
+</script>
+</body>
+</html>
+`
+}
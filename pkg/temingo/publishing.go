@@ -0,0 +1,54 @@
+package temingo
+
+import (
+	"path"
+	"time"
+)
+
+// isPublished returns whether the item, page or template with the given values is part of the build.
+// Drafts ('draft: true') are only included with the buildDrafts option, content with a 'date' in the future only with the buildFuture option.
+func (engine *Engine) isPublished(name string, values map[string]interface{}) bool {
+	if draft, ok := values["draft"].(bool); ok && draft && !engine.BuildDrafts {
+		engine.logDebug("Skipping '" + name + "', as it is a draft.")
+		engine.countSkipped(name)
+		return false
+	}
+	if date, ok := toTime(values["date"]); ok && date.After(time.Now()) && !engine.BuildFuture {
+		engine.logDebug("Skipping '" + name + "', as its date " + date.Format(time.RFC3339) + " is in the future.")
+		engine.countSkipped(name)
+		return false
+	}
+	return true
+}
+
+// isInUnpublishedItem returns whether filePath is the folder of an item which is left out of the build or is located inside of it, so f.e. the images of drafts aren't published either.
+func (engine *Engine) isInUnpublishedItem(filePath string) bool {
+	if engine.BuildDrafts && engine.BuildFuture {
+		return false
+	}
+	for dirPath := path.Clean(filePath); dirPath != "." && dirPath != "/" && engine.isInside(dirPath, engine.InputDir); dirPath = path.Dir(dirPath) {
+		indexPath, ok := engine.getItemIndexFile(dirPath)
+		if !ok {
+			continue
+		}
+		values, err := engine.loadItemIndexFile(indexPath)
+		if err == nil && !engine.isPublished(indexPath, values) { // broken index files are reported when the item is loaded
+			return true
+		}
+	}
+	return false
+}
+
+// getPublishedFiles returns the templates or markdown files whose front matter doesn't mark them as draft or future content, see isPublished.
+// Files with a broken front matter are kept, so the error is reported when they are rendered.
+func (engine *Engine) getPublishedFiles(files [][]string) [][]string {
+	published := [][]string{}
+	for _, file := range files {
+		frontMatter, _, _, err := parseFrontMatter(file[0], file[1])
+		if err == nil && !engine.isPublished(file[0], frontMatter) {
+			continue
+		}
+		published = append(published, file)
+	}
+	return published
+}
@@ -0,0 +1,533 @@
+package temingo
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// collectPages creates the global page collection the 'pages' function operates on.
+// It contains an entry for each normal template and for each item of the single-view templates.
+// Each entry has the keys 'Path', 'Section' and 'Kind' ('page', 'item' or 'term'), items additionally contain their values.
+// Content with 'lastReviewed' and a review interval additionally has 'LastReviewed', 'ReviewDue' and 'ReviewOverdue'.
+// Pages generated from values collections are items as well. Markdown content files are pages, which additionally contain their front matter.
+func (engine *Engine) collectPages(sources renderSources) error {
+	engine.sitePages = []interface{}{}
+	taxonomyTemplates := make(map[string]string) // by taxonomy, their pages are collected once the terms are known
+	taxonomyNoIndex := make(map[string]bool)     // by taxonomy, whether its template has 'noindex' set
+
+	for _, template := range sources.templates {
+		frontMatter, _, err := splitFrontMatter(template[0], template[1])
+		if err != nil {
+			return err
+		}
+		taxonomy, ok, err := engine.getTaxonomy(template[0], frontMatter)
+		if err != nil {
+			return err
+		}
+		if ok {
+			if other, ok := taxonomyTemplates[taxonomy]; ok {
+				return errors.New("Both '" + other + "' and '" + template[0] + "' are templates of the taxonomy '" + taxonomy + "', but there can be only one.")
+			}
+			taxonomyTemplates[taxonomy] = template[0]
+			taxonomyNoIndex[taxonomy] = getPageIndexing(frontMatter).NoIndex
+			continue
+		}
+		sectionValues, err := engine.getSectionValues(path.Dir(template[0]))
+		if err != nil {
+			return err
+		}
+		ownValues, err := engine.getTemplateValues(template[0], frontMatter)
+		if err != nil {
+			return err
+		}
+		templateValues := mergeValues(sources.values, sectionValues, ownValues)
+		dataPages, ok, err := engine.getDataPages(template[0], frontMatter, templateValues)
+		if err != nil {
+			return err
+		}
+		if ok {
+			for _, dataPage := range dataPages {
+				page := map[string]interface{}{}
+				if values, ok := dataPage.Item.(map[string]interface{}); ok {
+					for key, value := range values {
+						page[key] = value
+					}
+				}
+				page["Path"] = "/" + dataPage.ItemPath
+				page["Section"] = getSection(page["Path"].(string))
+				page["Kind"] = "item"
+				page["Template"] = template[0]
+				page["NoIndex"] = getPageIndexing(mergeValues(frontMatter, page)).NoIndex
+				if err := engine.addReviewStatus(page, template[0], mergeValues(frontMatter, page)); err != nil {
+					return err
+				}
+				engine.sitePages = append(engine.sitePages, page)
+			}
+			continue
+		}
+
+		outputPath, err := engine.getPageOutputPath(template[0], frontMatter, engine.trimLanguage(strings.TrimSuffix(template[0], engine.TemplateExtension)))
+		if err != nil {
+			return err
+		}
+		pagePath := "/" + outputPath
+		if strings.HasPrefix(path.Base(pagePath), ".") { // hidden outputs like '.htaccess' are no pages
+			continue
+		}
+		pagePaths := []string{pagePath}
+		paginatedPages, ok, err := engine.getPaginatedPages(template[0], frontMatter)
+		if err != nil {
+			return err
+		}
+		if ok { // each page of a paginated list is a page of its own
+			pagePaths = []string{}
+			for _, paginatedPage := range paginatedPages {
+				pagePaths = append(pagePaths, "/"+paginatedPage.OutputPath)
+			}
+		}
+		for i, pagePath := range pagePaths {
+			page := map[string]interface{}{
+				"Path":     pagePath,
+				"Section":  getSection(pagePath),
+				"Kind":     "page",
+				"Template": template[0],
+				"NoIndex":  getPageIndexing(frontMatter).NoIndex,
+			}
+			if aliases, ok := frontMatter["aliases"]; ok && i == 0 { // aliases of paginated lists redirect to their first page
+				page["aliases"] = aliases
+			}
+			if err := engine.addReviewStatus(page, template[0], frontMatter); err != nil {
+				return err
+			}
+			engine.sitePages = append(engine.sitePages, page)
+		}
+	}
+
+	for _, markdownFile := range sources.markdownFiles {
+		frontMatter, _, _, err := parseFrontMatter(markdownFile[0], markdownFile[1])
+		if err != nil {
+			return err
+		}
+		page := make(map[string]interface{})
+		for key, value := range frontMatter {
+			page[key] = value
+		}
+		outputPath, err := engine.getMarkdownOutputPath(markdownFile[0], frontMatter)
+		if err != nil {
+			return err
+		}
+		page["Path"] = "/" + outputPath
+		page["Section"] = getSection(page["Path"].(string))
+		page["Kind"] = "page"
+		page["Template"] = markdownFile[0]
+		page["NoIndex"] = getPageIndexing(frontMatter).NoIndex
+		if err := engine.addReviewStatus(page, markdownFile[0], frontMatter); err != nil {
+			return err
+		}
+		engine.sitePages = append(engine.sitePages, page)
+	}
+
+	collected := make(map[string]bool) // by the folder of the item, as multiple single-view templates can share the same items
+	for _, template := range sources.singleTemplates {
+		frontMatter, _, err := splitFrontMatter(template[0], template[1])
+		if err != nil {
+			return err
+		}
+		nested, err := isNestedSingleTemplate(template[0], frontMatter)
+		if err != nil {
+			return err
+		}
+		listObjects, err := engine.loadListObjects(path.Dir(template[0]), nested)
+		if err != nil {
+			return err
+		}
+		itemDirs := []string{}
+		for itemDir := range listObjects {
+			itemDirs = append(itemDirs, itemDir)
+		}
+		sort.Strings(itemDirs)
+		for _, itemDir := range itemDirs {
+			if collected[itemDir] {
+				continue
+			}
+			collected[itemDir] = true
+			item := listObjects[itemDir].(map[string]interface{})
+			page := make(map[string]interface{})
+			for key, value := range item {
+				page[key] = value
+			}
+			page["Section"] = getSection(toString(item["Path"]))
+			page["Kind"] = "item"
+			page["NoIndex"] = getPageIndexing(item).NoIndex
+			if err := engine.addReviewStatus(page, toString(item["Path"]), item); err != nil {
+				return err
+			}
+			engine.sitePages = append(engine.sitePages, page)
+		}
+	}
+
+	return engine.collectTaxonomies(taxonomyTemplates, taxonomyNoIndex)
+}
+
+// getSection returns the top-level folder of the site-relative pagePath, or an empty string for pages in the root.
+func getSection(pagePath string) string {
+	dir := path.Dir(strings.TrimPrefix(pagePath, "/"))
+	if dir == "." {
+		return ""
+	}
+	return strings.Split(dir, "/")[0]
+}
+
+// queryPages returns a copy of the global page collection, so it can be filtered and sorted.
+func (engine *Engine) queryPages() []interface{} {
+	return append([]interface{}{}, engine.sitePages...)
+}
+
+// queryWhere filters a collection. It's called as 'where "key" "value" collection' or 'where "key" "operator" "value" collection'.
+// Supported operators are '==' (default), '!=', '<', '<=', '>', '>=', 'in', 'not in' and 'intersect'.
+func queryWhere(args ...interface{}) ([]interface{}, error) {
+	if len(args) != 3 && len(args) != 4 {
+		return nil, errors.New("where expects a key, an optional operator, a value and a collection")
+	}
+	key := toString(args[0])
+	operator := "=="
+	value := args[1]
+	if len(args) == 4 {
+		operator = toString(args[1])
+		value = args[2]
+	}
+	collection, err := toCollection(args[len(args)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	result := []interface{}{}
+	for _, element := range collection {
+		fieldValue, ok := getField(element, key)
+		matches, err := compareField(fieldValue, ok, operator, value)
+		if err != nil {
+			return nil, err
+		}
+		if matches {
+			result = append(result, element)
+		}
+	}
+	return result, nil
+}
+
+// querySortBy sorts a collection by one or more keys, each with an optional order.
+// It's called as 'sortBy "key" collection', 'sortBy "key" "desc" collection' or f.e. 'sortBy "weight" "date desc" "title" collection', where later keys are only used for elements that are equal on the previous ones.
+// Elements without the key are placed at the end.
+func querySortBy(args ...interface{}) ([]interface{}, error) {
+	if len(args) < 2 {
+		return nil, errors.New("sortBy expects at least one key, each with an optional order ('asc' or 'desc'), and a collection")
+	}
+	keyArgs := []string{}
+	for _, arg := range args[:len(args)-1] {
+		keyArgs = append(keyArgs, toString(arg))
+	}
+	sortKeys, err := parseSortKeys(keyArgs)
+	if err != nil {
+		return nil, errors.New("sortBy: " + err.Error())
+	}
+	collection, err := toCollection(args[len(args)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := append([]interface{}{}, collection...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return lessByKeys(sorted[i], sorted[j], sortKeys)
+	})
+	return sorted, nil
+}
+
+// queryFirst returns the first n elements of a collection when called as 'first n collection'.
+// Called with a single list, it behaves like the sprig function and returns its first element.
+func queryFirst(sprigFirst func(interface{}) interface{}) func(...interface{}) (interface{}, error) {
+	return func(args ...interface{}) (interface{}, error) {
+		switch len(args) {
+		case 1:
+			return sprigFirst(args[0]), nil
+		case 2:
+			return limitCollection("first", args[0], args[1])
+		}
+		return nil, errors.New("first expects either a list or a number and a collection")
+	}
+}
+
+// queryLimit returns the first n elements of a collection when called as 'limit n collection', like 'first n collection'.
+func queryLimit(n interface{}, value interface{}) ([]interface{}, error) {
+	return limitCollection("limit", n, value)
+}
+
+func limitCollection(name string, n interface{}, value interface{}) ([]interface{}, error) {
+	limit, ok := toFloat(n)
+	if !ok || limit < 0 {
+		return nil, errors.New(name + " expects a positive number as first argument")
+	}
+	collection, err := toCollection(value)
+	if err != nil {
+		return nil, err
+	}
+	if int(limit) < len(collection) {
+		collection = collection[:int(limit)]
+	}
+	return collection, nil
+}
+
+// queryReverse returns the elements of a collection in reverse order, f.e. 'list "blog" | reverse' for the items in descending order of their paths.
+// Unlike the sprig function, it accepts maps as well.
+func queryReverse(value interface{}) ([]interface{}, error) {
+	collection, err := toCollection(value)
+	if err != nil {
+		return nil, errors.New("reverse: " + err.Error())
+	}
+	reversed := make([]interface{}, len(collection))
+	for i, element := range collection {
+		reversed[len(collection)-1-i] = element
+	}
+	return reversed, nil
+}
+
+// querySlice creates a list from its arguments, like 'slice "go" "web"'.
+// If the first argument already is a list, it behaves like the sprig function and slices it.
+func querySlice(sprigSlice func(interface{}, ...interface{}) interface{}) func(...interface{}) interface{} {
+	return func(args ...interface{}) interface{} {
+		if len(args) > 0 && args[0] != nil {
+			kind := reflect.TypeOf(args[0]).Kind()
+			if kind == reflect.Slice || kind == reflect.Array {
+				return sprigSlice(args[0], args[1:]...)
+			}
+		}
+		return args
+	}
+}
+
+// queryCount returns the number of elements of a collection, f.e. 'pages | where "Section" "blog" | count'.
+func queryCount(value interface{}) (int, error) {
+	collection, err := toCollection(value)
+	if err != nil {
+		return 0, errors.New("count: " + err.Error())
+	}
+	return len(collection), nil
+}
+
+// querySumBy returns the sum of the values of key in a collection, when called as 'sumBy "key" collection'. Elements without the key are skipped.
+func querySumBy(key string, value interface{}) (float64, error) {
+	collection, err := toCollection(value)
+	if err != nil {
+		return 0, errors.New("sumBy: " + err.Error())
+	}
+	sum := 0.0
+	for _, element := range collection {
+		fieldValue, ok := getField(element, key)
+		if !ok {
+			continue
+		}
+		number, ok := toFloat(fieldValue)
+		if !ok {
+			return 0, fmt.Errorf("sumBy: the value '%v' of '%s' is not a number", fieldValue, key)
+		}
+		sum += number
+	}
+	return sum, nil
+}
+
+// queryMinBy returns the element with the smallest value of key in a collection, when called as 'minBy "key" collection'.
+// Elements without the key are skipped, so it returns nothing if none of them has it.
+func queryMinBy(key string, value interface{}) (interface{}, error) {
+	return queryExtremeBy("minBy", key, value, false)
+}
+
+// queryMaxBy returns the element with the largest value of key in a collection, when called as 'maxBy "key" collection', f.e. '(maxBy "date" (list "blog")).date'.
+// Elements without the key are skipped, so it returns nothing if none of them has it.
+func queryMaxBy(key string, value interface{}) (interface{}, error) {
+	return queryExtremeBy("maxBy", key, value, true)
+}
+
+func queryExtremeBy(name string, key string, value interface{}, max bool) (interface{}, error) {
+	collection, err := toCollection(value)
+	if err != nil {
+		return nil, errors.New(name + ": " + err.Error())
+	}
+	var result, resultValue interface{}
+	for _, element := range collection {
+		fieldValue, ok := getField(element, key)
+		if !ok {
+			continue
+		}
+		if result == nil || (max && lessValue(resultValue, fieldValue)) || (!max && lessValue(fieldValue, resultValue)) { // the first of equal elements wins
+			result, resultValue = element, fieldValue
+		}
+	}
+	return result, nil
+}
+
+// queryUniqBy returns the elements of a collection with distinct values of key, when called as 'uniqBy "key" collection'.
+// Of elements with the same value the first one is kept, elements without the key are left out.
+func queryUniqBy(key string, value interface{}) ([]interface{}, error) {
+	collection, err := toCollection(value)
+	if err != nil {
+		return nil, errors.New("uniqBy: " + err.Error())
+	}
+	result := []interface{}{}
+	seen := []interface{}{}
+	for _, element := range collection {
+		fieldValue, ok := getField(element, key)
+		if !ok || containsValue(seen, fieldValue) {
+			continue
+		}
+		seen = append(seen, fieldValue)
+		result = append(result, element)
+	}
+	return result, nil
+}
+
+// toCollection converts lists and maps (like the result of 'list') to a slice. Maps are ordered by their keys.
+func toCollection(value interface{}) ([]interface{}, error) {
+	if value == nil {
+		return []interface{}{}, nil
+	}
+	reflected := reflect.ValueOf(value)
+	switch reflected.Kind() {
+	case reflect.Slice, reflect.Array:
+		collection := make([]interface{}, reflected.Len())
+		for i := range collection {
+			collection[i] = reflected.Index(i).Interface()
+		}
+		return collection, nil
+	case reflect.Map:
+		keys := getSortedMapKeys(reflected)
+		collection := make([]interface{}, len(keys))
+		for i, key := range keys {
+			collection[i] = reflected.MapIndex(key).Interface()
+		}
+		return collection, nil
+	}
+	return nil, errors.New("expected a list or map, got " + reflected.Kind().String())
+}
+
+// querySortedKeys returns the keys of a map in sorted order, f.e. '{{ range sortedKeys .Values.authors }}'.
+func querySortedKeys(value interface{}) ([]interface{}, error) {
+	reflected := reflect.ValueOf(value)
+	if reflected.Kind() != reflect.Map {
+		return nil, errors.New("sortedKeys expects a map, got " + reflected.Kind().String())
+	}
+	keys := []interface{}{}
+	for _, key := range getSortedMapKeys(reflected) {
+		keys = append(keys, key.Interface())
+	}
+	return keys, nil
+}
+
+// querySortedPairs returns the entries of a map ordered by their keys, each with a 'Key' and a 'Value'.
+// Unlike the map itself, the result can be passed on to 'where', 'sortBy' and 'first'.
+func querySortedPairs(value interface{}) ([]interface{}, error) {
+	reflected := reflect.ValueOf(value)
+	if reflected.Kind() != reflect.Map {
+		return nil, errors.New("sortedPairs expects a map, got " + reflected.Kind().String())
+	}
+	pairs := []interface{}{}
+	for _, key := range getSortedMapKeys(reflected) {
+		pairs = append(pairs, map[string]interface{}{
+			"Key":   key.Interface(),
+			"Value": reflected.MapIndex(key).Interface(),
+		})
+	}
+	return pairs, nil
+}
+
+// getSortedMapKeys returns the keys of the reflected map, so maps are always iterated in the same order.
+func getSortedMapKeys(reflected reflect.Value) []reflect.Value {
+	keys := reflected.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return lessValue(keys[i].Interface(), keys[j].Interface()) })
+	return keys
+}
+
+// getField returns the value of key in element. If there is no exact match, the key is matched case-insensitive.
+func getField(element interface{}, key string) (interface{}, bool) {
+	object, ok := element.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	if value, ok := object[key]; ok {
+		return value, true
+	}
+	for objectKey, value := range object {
+		if strings.EqualFold(objectKey, key) {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+func compareField(fieldValue interface{}, exists bool, operator string, value interface{}) (bool, error) {
+	switch operator {
+	case "=", "==", "eq":
+		return exists && equalValue(fieldValue, value), nil
+	case "!=", "ne":
+		return !exists || !equalValue(fieldValue, value), nil
+	case "<", "lt":
+		return exists && lessValue(fieldValue, value), nil
+	case "<=", "le":
+		return exists && !lessValue(value, fieldValue), nil
+	case ">", "gt":
+		return exists && lessValue(value, fieldValue), nil
+	case ">=", "ge":
+		return exists && !lessValue(fieldValue, value), nil
+	case "in", "not in":
+		values, err := toCollection(value)
+		if err != nil {
+			return false, err
+		}
+		contained := exists && containsValue(values, fieldValue)
+		return contained == (operator == "in"), nil
+	case "intersect":
+		if !exists {
+			return false, nil
+		}
+		fieldValues, err := toCollection(fieldValue)
+		if err != nil {
+			return false, err
+		}
+		values, err := toCollection(value)
+		if err != nil {
+			return false, err
+		}
+		for _, fieldValue := range fieldValues {
+			if containsValue(values, fieldValue) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return false, errors.New("unknown where operator '" + operator + "'")
+}
+
+func equalValue(a interface{}, b interface{}) bool {
+	if aTime, ok := toTime(a); ok {
+		if bTime, ok := toTime(b); ok {
+			return aTime.Equal(bTime)
+		}
+	}
+	if aNumber, ok := toFloat(a); ok {
+		if bNumber, ok := toFloat(b); ok {
+			return aNumber == bNumber
+		}
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, element := range values {
+		if equalValue(element, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package temingo
+
+import (
+	"errors"
+	"path"
+	"strings"
+)
+
+// redactedValue replaces the values of redacted keys in the logs.
+const redactedValue = "[redacted]"
+
+// validateRedactedKeys checks the patterns of the redactedKeys option.
+func (engine *Engine) validateRedactedKeys() error {
+	for _, pattern := range engine.RedactedKeys {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return errors.New("The redacted key pattern '" + pattern + "' is invalid: " + err.Error())
+		}
+	}
+	return nil
+}
+
+// isRedactedKey returns whether the values of key are redacted in the logs, as it case-insensitively matches one of the redactedKeys patterns, f.e. '*token*'.
+func (engine *Engine) isRedactedKey(key string) bool {
+	for _, pattern := range engine.RedactedKeys {
+		if matched, _ := path.Match(strings.ToLower(pattern), strings.ToLower(key)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// redactValues returns a copy of the values loaded from yaml to be logged, where the values of all redacted keys are replaced, at any depth.
+func (engine *Engine) redactValues(values interface{}) interface{} {
+	switch v := values.(type) {
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			if engine.isRedactedKey(key) {
+				redacted[key] = redactedValue
+			} else {
+				redacted[key] = engine.redactValues(value)
+			}
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, value := range v {
+			redacted[i] = engine.redactValues(value)
+		}
+		return redacted
+	}
+	return values
+}
+
+// redactURLQuery returns rawURL to be logged, with its query string replaced, as it often contains credentials, f.e. the 'token' of the webmentionsAPI.
+func redactURLQuery(rawURL string) string {
+	if index := strings.Index(rawURL, "?"); index != -1 {
+		return rawURL[:index] + "?" + redactedValue
+	}
+	return rawURL
+}
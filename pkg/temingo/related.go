@@ -0,0 +1,112 @@
+package temingo
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// defaultRelatedKeys are the keys items are related by, if 'related' isn't called with any.
+var defaultRelatedKeys = []string{"tags", "categories"}
+
+// relatedKey is one of the keys items are related by, and the weight of each shared value of it.
+type relatedKey struct {
+	key    string
+	weight float64
+}
+
+// parseRelatedKeys parses the keys items are related by, each either as key ('tags') or with its weight ('tags=2'). The weight defaults to 1.
+func parseRelatedKeys(args []string) ([]relatedKey, error) {
+	keys := []relatedKey{}
+	for _, arg := range args {
+		parts := strings.SplitN(arg, "=", 2)
+		key := relatedKey{key: strings.TrimSpace(parts[0]), weight: 1}
+		if len(parts) == 2 {
+			weight, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+			if err != nil || weight < 0 {
+				return nil, errors.New("the weight of '" + key.key + "' must be a positive number, but is '" + parts[1] + "'")
+			}
+			key.weight = weight
+		}
+		if key.key == "" {
+			return nil, errors.New("the key '" + arg + "' must be like 'tags' or 'tags=2'")
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// getRelated is the 'related' template function, which returns the other items of listPath sharing values of the keys with item, f.e. '{{ range related .Item "blog" | limit 3 }}'.
+// The keys default to 'tags' and 'categories', each can be weighted like 'tags=2' (see parseRelatedKeys). Items are ranked by the sum of their shared values times the weights, then by the newest 'date', then by path. Items sharing nothing are left out.
+func (engine *Engine) getRelated(item interface{}, listPath string, keyArgs ...string) ([]interface{}, error) {
+	values, ok := item.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("related: the item must be a map like '.Item', but is '" + toString(item) + "'")
+	}
+	if len(keyArgs) == 0 {
+		keyArgs = defaultRelatedKeys
+	}
+	keys, err := parseRelatedKeys(keyArgs)
+	if err != nil {
+		return nil, errors.New("related: " + err.Error())
+	}
+	candidates, err := engine.getSortedListObjects(listPath, "", false)
+	if err != nil {
+		return nil, errors.New("related: could not load the list objects of '" + listPath + "': " + err.Error())
+	}
+
+	ownTerms := make(map[string]map[string]bool) // by key
+	for _, key := range keys {
+		terms, err := getTaxonomyTerms(values, key.key)
+		if err != nil {
+			return nil, errors.New("related: " + err.Error())
+		}
+		ownTerms[key.key] = make(map[string]bool)
+		for _, term := range terms {
+			ownTerms[key.key][strings.ToLower(term)] = true
+		}
+	}
+
+	scores := make(map[string]float64) // by path
+	related := []map[string]interface{}{}
+	for _, candidate := range candidates {
+		candidatePath := toString(candidate["Path"])
+		if candidatePath == toString(values["Path"]) { // the item itself
+			continue
+		}
+		score := 0.0
+		for _, key := range keys {
+			terms, err := getTaxonomyTerms(candidate, key.key)
+			if err != nil {
+				return nil, errors.New("related: " + err.Error())
+			}
+			for _, term := range terms {
+				if ownTerms[key.key][strings.ToLower(term)] {
+					score += key.weight
+				}
+			}
+		}
+		if score > 0 {
+			scores[candidatePath] = score
+			related = append(related, candidate)
+		}
+	}
+
+	sort.SliceStable(related, func(i, j int) bool { // the candidates are already sorted by path
+		if scoreI, scoreJ := scores[toString(related[i]["Path"])], scores[toString(related[j]["Path"])]; scoreI != scoreJ {
+			return scoreI > scoreJ
+		}
+		dateI, okI := toTime(related[i]["date"])
+		dateJ, okJ := toTime(related[j]["date"])
+		if okI && okJ && !dateI.Equal(dateJ) {
+			return dateI.After(dateJ)
+		}
+		return okI && !okJ // items with a date first
+	})
+	result := []interface{}{}
+	for _, candidate := range related {
+		result = append(result, candidate)
+	}
+	return result, nil
+}
@@ -0,0 +1,104 @@
+package temingo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// remoteDataCacheDir is the folder in the cacheDir the responses of 'getJSON' and 'getYAML' are cached in, one file per url.
+var remoteDataCacheDir = path.Join(cacheDir, "remote")
+
+// getRemoteJSON is the 'getJSON' template function, which returns the parsed json at url, see getRemoteData.
+// F.e. '(getJSON "https://api.github.com/repos/thetillhoff/temingo/releases").0.tag_name' or with headers 'getJSON "https://cms.example.com/api/posts" (dict "Authorization" "Bearer ...")'.
+func (engine *Engine) getRemoteJSON(url string, headers ...map[string]interface{}) (interface{}, error) {
+	return engine.getRemoteData("getJSON", url, headers, func(content []byte, data *interface{}) error {
+		return json.Unmarshal(content, data)
+	})
+}
+
+// getRemoteYAML is the 'getYAML' template function, which returns the parsed yaml at url, see getRemoteData.
+func (engine *Engine) getRemoteYAML(url string, headers ...map[string]interface{}) (interface{}, error) {
+	return engine.getRemoteData("getYAML", url, headers, func(content []byte, data *interface{}) error {
+		return yaml.Unmarshal(content, data)
+	})
+}
+
+// getRemoteData returns the data at url, parsed by unmarshal. It's fetched once per run and cached in the cacheDir, so templates requesting the same url don't fetch it again.
+// If fetching fails, the cached response is used instead. With the offline option, only cached responses are used and urls which were never fetched are an error.
+func (engine *Engine) getRemoteData(function string, url string, headers []map[string]interface{}, unmarshal func(content []byte, data *interface{}) error) (interface{}, error) {
+	engine.lock.Lock()
+	content, ok := engine.fetchedData[url]
+	engine.lock.Unlock()
+
+	cacheFilePath := getRemoteDataCacheFilePath(url)
+	if !ok && !engine.Offline {
+		fetched, err := engine.fetchRemoteData(url, headers)
+		if err == nil {
+			content, ok = fetched, true
+			engine.createFolderIfNotExists(path.Dir(cacheFilePath))
+			err = ioutil.WriteFile(cacheFilePath, content, engine.fileMode)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			engine.logWarn("Could not fetch '" + url + "', using the cached response instead: " + err.Error())
+		}
+	}
+	if !ok {
+		cached, err := ioutil.ReadFile(cacheFilePath)
+		if err != nil {
+			if engine.Offline {
+				return nil, errors.New(function + ": '" + url + "' was never fetched, so there is no cached response to use with '--offline'")
+			}
+			return nil, errors.New(function + ": could not fetch '" + url + "' and there is no cached response")
+		}
+		content = cached
+	}
+	engine.lock.Lock()
+	engine.fetchedData[url] = content
+	engine.lock.Unlock()
+
+	var data interface{}
+	if err := unmarshal(content, &data); err != nil {
+		return nil, errors.New(function + ": could not parse the response of '" + url + "': " + err.Error())
+	}
+	return data, nil
+}
+
+// fetchRemoteData requests the content at url, with the given headers.
+func (engine *Engine) fetchRemoteData(url string, headers []map[string]interface{}) ([]byte, error) {
+	engine.logDebug("Fetching '" + url + "' ...")
+
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, header := range headers {
+		for name, value := range header {
+			request.Header.Set(name, toString(value))
+		}
+	}
+	client := http.Client{Timeout: 10 * time.Second}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.New("unexpected status '" + response.Status + "'")
+	}
+	return ioutil.ReadAll(response.Body)
+}
+
+func getRemoteDataCacheFilePath(url string) string {
+	hash := sha256.Sum256([]byte(url))
+	return path.Join(remoteDataCacheDir, hex.EncodeToString(hash[:]))
+}
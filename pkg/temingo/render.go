@@ -0,0 +1,775 @@
+package temingo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/otiai10/copy"
+	"gopkg.in/yaml.v3"
+)
+
+// createFolderIfNotExists creates the folder at path and its parents with the dirMode, reduced by the umask of the process.
+func (engine *Engine) createFolderIfNotExists(path string) {
+	os.MkdirAll(path, engine.dirMode)
+}
+
+// writeTemplateToFile writes content to filePath with the fileMode, reduced by the umask of the process. Missing folders are created.
+func (engine *Engine) writeTemplateToFile(filePath string, content []byte) error {
+	if err := engine.checkOutputFilePath(filePath); err != nil {
+		return err
+	}
+	dirPath := strings.TrimSuffix(filePath, path.Base(filePath))
+	engine.createFolderIfNotExists(dirPath)
+	err := ioutil.WriteFile(filePath, content, engine.fileMode)
+	return err
+}
+
+// renderJob is a single output file, which is rendered independently of all others.
+type renderJob struct {
+	context        map[string]interface{}
+	templateName   string
+	template       string
+	outputFilePath string
+	sourceFiles    []string                                     // the files the output is rendered from, f.e. the single-view template and the 'index.yaml' of the item
+	loadContext    func() (map[string]interface{}, bool, error) // if set, the context is only loaded right before rendering, so it isn't held in memory for all jobs at once. Returns false if there is nothing to render
+}
+
+// runTemplate renders a job. It's called concurrently, so the state of the engine is only modified while holding its lock.
+func (engine *Engine) runTemplate(job renderJob, sources renderSources) error {
+	if job.loadContext != nil {
+		context, ok, err := job.loadContext()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+		job.context = context
+	}
+
+	engine.lock.Lock()
+	source, ok := engine.outputSources[job.outputFilePath]
+	if !ok {
+		engine.outputSources[job.outputFilePath] = job.templateName
+	}
+	engine.lock.Unlock()
+	if ok { // f.e. 'about.html.template' and 'about.md'
+		return errors.New("Both '" + source + "' and '" + job.templateName + "' are rendered to '" + job.outputFilePath + "', so one would overwrite the other.")
+	}
+
+	if engine.isProfilingTemplates() {
+		defer engine.recordProfile("template", job.templateName, time.Now())
+	}
+	outputBuffer := new(bytes.Buffer)
+	values, _ := job.context["Values"].(map[string]interface{})
+	if engine.FlatContext {
+		values = job.context
+	}
+	var (
+		output []byte
+		reused bool
+		err    error
+	)
+	cacheKey, cached := engine.getTemplateCacheKey(job, sources)
+	if cached {
+		output, reused = engine.readCachedOutput(job.outputFilePath, cacheKey)
+	}
+	if reused {
+		engine.logDebug("Reusing the cached output of '" + job.templateName + "' for '" + job.outputFilePath + "'.")
+	} else {
+		tpl, err := engine.parseTemplateFiles(job.templateName, job.template, sources.partials, values)
+		if err != nil {
+			return engine.describeTemplateError(err, sources)
+		}
+		err = tpl.Execute(outputBuffer, job.context)
+		if err != nil {
+			return engine.describeTemplateError(err, sources)
+		}
+		output = outputBuffer.Bytes()
+		if cached {
+			engine.writeCachedOutput(job.outputFilePath, cacheKey, output)
+		}
+	}
+	if _, err := os.Stat(engine.OutputDir); os.IsNotExist(err) { // If output directory doesn't exist
+		engine.createFolderIfNotExists(engine.OutputDir)
+	}
+	if engine.getOutputIndexing(job.outputFilePath).NoIndex && engine.isHtmlOutput(job.outputFilePath) {
+		output = engine.addNoIndexMetaTag(job.outputFilePath, output)
+	}
+	if engine.Minify {
+		output, err = engine.minifyOutput(job.outputFilePath, output)
+		if err != nil {
+			return err
+		}
+	}
+	if engine.getOutputIndexing(job.outputFilePath).Protected {
+		if engine.isHtmlOutput(job.outputFilePath) {
+			output, err = engine.protectOutput(job.outputFilePath, output)
+			if err != nil {
+				return err
+			}
+		} else {
+			engine.logWarn("'" + job.outputFilePath + "' has 'protected' set, but only html outputs can be protected.")
+		}
+	}
+	err = engine.writeTemplateToFile(job.outputFilePath, output)
+	if err != nil {
+		return err
+	}
+	engine.countRendered(job.outputFilePath)
+	engine.lock.Lock()
+	engine.renderedSources[job.outputFilePath] = job.sourceFiles
+	if engine.renderedFiles != nil {
+		engine.renderedFiles[job.outputFilePath] = true
+	}
+	engine.lock.Unlock()
+	return nil
+}
+
+// runSafely runs the job and returns a panic as error, so a single broken output can't end the watching process or the program using the package.
+func (engine *Engine) runSafely(job renderJob, sources renderSources) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("Rendering '%s' to '%s' failed unexpectedly: %v", job.templateName, job.outputFilePath, recovered)
+		}
+	}()
+	return engine.runTemplate(job, sources)
+}
+
+// runJobs renders the jobs concurrently, with up to concurrency (defaults to GOMAXPROCS) jobs at the same time.
+// The errors are returned in the order of the jobs, so they don't change between builds.
+func (engine *Engine) runJobs(jobs []renderJob, sources renderSources) error {
+	workers := engine.Concurrency
+	if workers == 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if engine.isProfilingTemplates() {
+		engine.profile = make(map[string]*profileEntry)
+		defer engine.logProfile()
+	}
+
+	jobErrors := make([]error, len(jobs))
+	indices := make(chan int)
+	waitGroup := sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for index := range indices {
+				if engine.isBuildCanceled() {
+					jobErrors[index] = errBuildCanceled
+					continue
+				}
+				jobErrors[index] = engine.runSafely(jobs[index], sources)
+			}
+		}()
+	}
+	for index := range jobs {
+		indices <- index
+	}
+	close(indices)
+	waitGroup.Wait()
+
+	errs := BuildErrors{}
+	for _, err := range jobErrors {
+		errs.add(err)
+	}
+	return errs.err()
+}
+
+// renderSources contains everything that is read from the values files, the inputDir and the partialsDir for templating.
+type renderSources struct {
+	values          map[string]interface{}
+	templates       [][]string
+	singleTemplates [][]string
+	markdownFiles   [][]string
+	partials        [][]string
+}
+
+func (engine *Engine) readSources() (renderSources, error) {
+	// #####
+	// START reading value files
+	// #####
+	engine.logDebug("*** Reading values file(s) ... ***")
+	start := time.Now()
+	mappedValues, err := engine.getMappedValues()
+	if err != nil {
+		return renderSources{}, err
+	}
+	err = engine.loadTranslations()
+	if err != nil {
+		return renderSources{}, err
+	}
+	err = engine.loadData()
+	if err != nil {
+		return renderSources{}, err
+	}
+	engine.previousValues = mappedValues
+	engine.siteBaseURL = engine.BaseURL
+	if engine.siteBaseURL == "" {
+		engine.siteBaseURL = toString(mappedValues["baseURL"])
+	}
+	engine.sectionValuesCache = make(map[string]map[string]interface{}) // '_index.yaml' files might have changed since the last build
+	engine.server, err = engine.loadServerConfig()
+	if err != nil {
+		return renderSources{}, err
+	}
+	engine.buildInfo, err = engine.getBuildInfo()
+	if err != nil {
+		return renderSources{}, err
+	}
+	err = engine.validateSchemas(mappedValues)
+	if err != nil {
+		return renderSources{}, err
+	}
+	err = validateMenus(mappedValues)
+	if err != nil {
+		return renderSources{}, err
+	}
+	if engine.isLogged(levelDebug) {
+		valuesYaml, err := yaml.Marshal(engine.redactValues(mappedValues)) // so credentials don't end up in build logs
+		if err != nil {
+			return renderSources{}, err
+		}
+		engine.logDebug("*** General values-object: ***\n" + string(valuesYaml))
+	}
+
+	engine.recordPhase("load values", start)
+
+	// #####
+	// END reading value files
+	// START reading templates
+	// #####
+
+	start = time.Now()
+
+	templates, err := engine.getTemplates(engine.InputDir, engine.TemplateExtension, []string{"**/*" + engine.SingleTemplateExtension}) // get full html templates - with names
+	if err != nil {
+		return renderSources{}, err
+	}
+	partialTemplates, err := engine.getPartialTemplates() // get partial html templates - named by their path
+	if err != nil {
+		return renderSources{}, err
+	}
+	engine.partials = partialTemplates
+
+	// identify & collect single-view templates via their extension
+	singleTemplates, err := engine.getTemplates(engine.InputDir, engine.SingleTemplateExtension, []string{
+		path.Join(engine.InputDir, engine.PartialsDir, "**"),
+		path.Join(engine.InputDir, engine.OutputDir, "**"),
+	}) // get full html templates - with names
+	if err != nil {
+		return renderSources{}, err
+	}
+
+	markdownExclusions := []string{path.Join(engine.InputDir, engine.PartialsDir, "**")}
+	for _, fileName := range engine.ItemIndexFiles { // markdown index files are the values of items, not pages of their own
+		markdownExclusions = append(markdownExclusions, "**/"+fileName)
+	}
+	markdownFiles := [][]string{}
+	if engine.MarkdownExtension != "" { // markdown content is opt-in
+		markdownFiles, err = engine.getTemplates(engine.InputDir, engine.MarkdownExtension, markdownExclusions) // get markdown content files - with names
+		if err != nil {
+			return renderSources{}, err
+		}
+		markdownFiles = engine.removeItemFiles(markdownFiles) // markdown item files are the values of items as well
+	}
+
+	engine.recordPhase("discover templates", start)
+
+	// #####
+	// END reading templates
+	// #####
+
+	return renderSources{
+		values:          mappedValues,
+		templates:       engine.getPublishedFiles(engine.filterLanguageFiles(templates, engine.TemplateExtension)), // drafts and future content are left out, unless they should be built
+		singleTemplates: engine.getPublishedFiles(engine.filterLanguageFiles(singleTemplates, engine.SingleTemplateExtension)),
+		markdownFiles:   engine.getPublishedFiles(engine.filterLanguageFiles(markdownFiles, engine.MarkdownExtension)),
+		partials:        partialTemplates,
+	}, nil
+}
+
+// render renders all templates concurrently, once per language. A broken template doesn't stop the others from being rendered, so the errors of all of them are returned at once.
+func (engine *Engine) render() error {
+	engine.renderedSources = make(map[string][]string)
+	engine.pageIndexing = make(map[string]pageIndexing)
+	engine.processedImages = make(map[string]bool)
+	engine.imageSources = make(map[string]bool)
+	engine.execFunctionResults = make(map[string][]byte)
+	errs := BuildErrors{}
+	languages := engine.getLanguages()
+	for i := len(languages) - 1; i >= 0; i-- { // the default language is rendered last, so the exports use its values and pages
+		engine.language = languages[i]
+		if engine.language != "" {
+			engine.logDebug("*** Rendering the language '" + engine.language + "' ... ***")
+		}
+		errs.add(engine.renderLanguage())
+	}
+	engine.language = ""
+	return errs.err()
+}
+
+// renderLanguage renders all templates for the current language.
+func (engine *Engine) renderLanguage() error {
+	sources, err := engine.readSources()
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	err = engine.collectPages(sources) // so the 'pages' function knows about all pages and items
+	if err != nil {
+		return err
+	}
+
+	err = engine.collectAliases()
+	if err != nil {
+		return err
+	}
+	engine.recordPhase("collect pages", start)
+
+	start = time.Now()
+	jobs, err := engine.getJobs(sources, func(string) bool { return true })
+	errs := BuildErrors{}
+	errs.add(err)
+	errs.add(engine.runJobs(jobs, sources)) // the jobs of the other templates are rendered anyway
+	errs.add(engine.writeAliases())         // after the pages, so aliases colliding with them are reported
+	engine.recordPhase("render templates", start)
+	return errs.err()
+}
+
+// getJobs returns the jobs of all templates, single-view templates and markdown files whose name is selected.
+// The errors of all templates are returned at once.
+func (engine *Engine) getJobs(sources renderSources, selected func(templateName string) bool) ([]renderJob, error) {
+	engine.outputSources = make(map[string]string)
+	engine.templateCaches = make(map[string]*templateCache)
+	engine.templateEngines = make(map[string]bool)
+	jobs := []renderJob{}
+	errs := BuildErrors{}
+	for _, template := range sources.templates {
+		if selected(template[0]) {
+			templateJobs, err := engine.getTemplateJobs(template, sources)
+			jobs = append(jobs, templateJobs...)
+			errs.add(err)
+		}
+	}
+	for _, template := range sources.singleTemplates {
+		if selected(template[0]) {
+			templateJobs, err := engine.getSingleTemplateJobs(template, sources)
+			jobs = append(jobs, templateJobs...)
+			errs.add(err)
+		}
+	}
+	for _, markdownFile := range sources.markdownFiles {
+		if selected(markdownFile[0]) {
+			markdownJob, err := engine.getMarkdownJob(markdownFile, sources)
+			if err == nil {
+				jobs = append(jobs, markdownJob)
+			}
+			errs.add(err)
+		}
+	}
+	return jobs, errs.err()
+}
+
+// getTemplateJobs returns the jobs of a normal template, either one or - if it generates data-driven pages - one per element of the values collection.
+func (engine *Engine) getTemplateJobs(template []string, sources renderSources) ([]renderJob, error) {
+	frontMatter, body, err := splitFrontMatter(template[0], template[1])
+	if err != nil {
+		return nil, err
+	}
+	sectionValues, err := engine.getSectionValues(path.Dir(template[0]))
+	if err != nil {
+		return nil, err
+	}
+	ownValues, err := engine.getTemplateValues(template[0], frontMatter)
+	if err != nil {
+		return nil, err
+	}
+	templateValues := mergeValues(sources.values, sectionValues, ownValues) // section values override the global values, the values of the template override both
+	if err := engine.registerTemplateCache(template[0], frontMatter, ownValues); err != nil {
+		return nil, err
+	}
+	if err := engine.registerTemplateEngine(template[0], frontMatter); err != nil {
+		return nil, err
+	}
+
+	taxonomy, ok, err := engine.getTaxonomy(template[0], frontMatter)
+	if err != nil {
+		return nil, err
+	}
+	if ok { // rendered once per term of the taxonomy instead
+		if _, ok := frontMatter["permalink"]; ok {
+			return nil, errors.New("The taxonomy template '" + template[0] + "' can't have a 'permalink', as its outputs are located at the paths of the terms.")
+		}
+		jobs := []renderJob{}
+		fileName := engine.trimLanguage(strings.TrimSuffix(path.Base(template[0]), engine.TemplateExtension))
+		for _, term := range engine.taxonomies[taxonomy] {
+			outputFilePath, err := engine.getOutputFilePath(strings.TrimPrefix(toString(term.(map[string]interface{})["Path"]), "/"), fileName)
+			if err != nil {
+				return nil, err
+			}
+			engine.logDebug("Writing taxonomy output file '" + outputFilePath + "' ...")
+			context := engine.createContext(templateValues, template[0], outputFilePath, nil, "")
+			context["Term"] = term
+			engine.setPageIndexing(outputFilePath, context, frontMatter)
+			engine.setPageReview(context, frontMatter)
+			engine.setPageParams(context, frontMatter)
+			jobs = append(jobs, renderJob{context, template[0], body, outputFilePath, []string{template[0]}, nil})
+		}
+		return jobs, nil
+	}
+
+	dataPages, ok, err := engine.getDataPages(template[0], frontMatter, templateValues)
+	if err != nil {
+		return nil, err
+	}
+	if ok { // rendered once per element of a values collection instead
+		jobs := []renderJob{}
+		fileName := engine.trimLanguage(strings.TrimSuffix(path.Base(template[0]), engine.TemplateExtension))
+		for _, dataPage := range dataPages {
+			outputFilePath, err := engine.getOutputFilePath(dataPage.ItemPath, fileName)
+			if err != nil {
+				return nil, err
+			}
+			engine.logDebug("Writing data-driven output file '" + outputFilePath + "' ...")
+			context := engine.createContext(templateValues, template[0], outputFilePath, dataPage.Item, "/"+dataPage.ItemPath)
+			itemValues, _ := dataPage.Item.(map[string]interface{})
+			engine.setPageIndexing(outputFilePath, context, mergeValues(frontMatter, itemValues)) // the values of the element override the front matter
+			engine.setPageReview(context, mergeValues(frontMatter, itemValues))
+			engine.setPageParams(context, frontMatter)
+			jobs = append(jobs, renderJob{context, template[0], body, outputFilePath, []string{template[0]}, nil})
+		}
+		return jobs, nil
+	}
+
+	paginatedPages, ok, err := engine.getPaginatedPages(template[0], frontMatter)
+	if err != nil {
+		return nil, err
+	}
+	if ok { // rendered once per page of the list objects instead
+		if _, ok := frontMatter["permalink"]; ok {
+			return nil, errors.New("The paginated template '" + template[0] + "' can't have a 'permalink', as its outputs are located at the paths of the pages.")
+		}
+		jobs := []renderJob{}
+		for _, paginatedPage := range paginatedPages {
+			outputFilePath, err := engine.getOutputFilePath(paginatedPage.OutputPath)
+			if err != nil {
+				return nil, err
+			}
+			engine.logDebug("Writing paginated output file '" + outputFilePath + "' ...")
+			context := engine.createContext(templateValues, template[0], outputFilePath, nil, "")
+			context["Paginator"] = paginatedPage.Paginator
+			engine.setPageIndexing(outputFilePath, context, frontMatter)
+			engine.setPageReview(context, frontMatter)
+			engine.setPageParams(context, frontMatter)
+			jobs = append(jobs, renderJob{context, template[0], body, outputFilePath, []string{template[0]}, nil})
+		}
+		return jobs, nil
+	}
+
+	outputPath, err := engine.getPageOutputPath(template[0], frontMatter, engine.trimLanguage(strings.TrimSuffix(template[0], engine.TemplateExtension)))
+	if err != nil {
+		return nil, err
+	}
+	outputFilePath, err := engine.getOutputFilePath(outputPath)
+	if err != nil {
+		return nil, err
+	}
+	engine.logDebug("Writing output file '" + outputFilePath + "' ...")
+	context := engine.createContext(templateValues, template[0], outputFilePath, nil, "")
+	engine.setPageIndexing(outputFilePath, context, frontMatter)
+	engine.setPageReview(context, frontMatter)
+	engine.setPageParams(context, frontMatter)
+	return []renderJob{{context, template[0], body, outputFilePath, []string{template[0]}, nil}}, nil
+}
+
+// getSingleTemplateJobs returns the jobs of a single-view template, one per item in its folder.
+// Only the paths of the items are collected here. Their values are loaded by the job right before rendering, so sites with very many items don't hold all of them in memory at once.
+func (engine *Engine) getSingleTemplateJobs(template []string, sources renderSources) ([]renderJob, error) {
+	templateName := template[0]
+	frontMatter, body, err := splitFrontMatter(templateName, template[1])
+	if err != nil {
+		return nil, err
+	}
+	nested, err := isNestedSingleTemplate(templateName, frontMatter)
+	if err != nil {
+		return nil, err
+	}
+	itemIndexPaths, err := engine.getItemIndexPaths(path.Dir(templateName), nested) // the index file of each item
+	if err != nil {
+		return nil, err
+	}
+
+	sectionValues, err := engine.getSectionValues(path.Dir(templateName))
+	if err != nil {
+		return nil, err
+	}
+	ownValues, err := engine.getTemplateValues(template[0], frontMatter)
+	if err != nil {
+		return nil, err
+	}
+	templateValues := mergeValues(sources.values, sectionValues, ownValues) // section values override the global values, the values of the template override both
+	if err := engine.registerTemplateCache(templateName, frontMatter, ownValues); err != nil {
+		return nil, err
+	}
+	if err := engine.registerTemplateEngine(templateName, frontMatter); err != nil {
+		return nil, err
+	}
+
+	itemPaths := []string{}
+	for itemPath := range itemIndexPaths {
+		itemPaths = append(itemPaths, itemPath)
+	}
+	sort.Strings(itemPaths) // so the jobs and their errors are always in the same order
+
+	jobs := []renderJob{}
+	for _, itemPath := range itemPaths {
+		indexPath := itemIndexPaths[itemPath]
+		sourceFiles := []string{templateName, indexPath}
+		itemDir := itemPath
+		itemPath, err = engine.loadItemOutputPath(itemDir, indexPath, strings.TrimSuffix(itemPath, filepath.Ext(itemPath)))
+		if err != nil {
+			return nil, err
+		}
+		fileName := engine.trimLanguage(strings.TrimSuffix(path.Base(templateName), engine.SingleTemplateExtension))
+		outputFilePath, err := engine.getOutputFilePath(itemPath, fileName)
+		if err != nil {
+			return nil, err
+		}
+		engine.logDebug("Writing single-view output from '" + itemPath + "*' to '" + outputFilePath + "' ...") // itemPath is incomplete; either its a yaml-file or a folder containing an index.yaml -> Therefore it has the '*' behind it.
+		contextPath := "/" + itemPath
+		loadContext := func() (map[string]interface{}, bool, error) {
+			itemSectionValues, err := engine.getSectionValues(itemDir)
+			if err != nil {
+				return nil, false, err
+			}
+			values, err := engine.loadItemIndexFile(indexPath)
+			if err != nil {
+				return nil, false, err
+			}
+			if !engine.isPublished(indexPath, values) {
+				return nil, false, nil
+			}
+			itemValue := mergeValues(itemSectionValues, values) // item values override the cascaded section values
+			// the path of the item, like the one of the list objects
+			itemValue["Path"] = contextPath
+			if nested {
+				itemValue["Depth"], itemValue["Parents"], err = engine.getItemAncestry(path.Dir(templateName), itemDir)
+				if err != nil {
+					return nil, false, err
+				}
+			}
+			context := engine.createContext(templateValues, templateName, outputFilePath, itemValue, contextPath)
+			engine.setPageIndexing(outputFilePath, context, mergeValues(frontMatter, itemValue)) // the values of the item override the front matter of the template
+			engine.setPageReview(context, mergeValues(frontMatter, itemValue))
+			engine.setPageParams(context, frontMatter)
+			return context, true, nil
+		}
+		jobs = append(jobs, renderJob{nil, templateName, body, outputFilePath, sourceFiles, loadContext})
+	}
+	return jobs, nil
+}
+
+// renderOutput deletes the contents of the outputDir, copies the static contents and other files and renders all templates into it.
+func (engine *Engine) renderOutput() error {
+	engine.startSummary()
+
+	// #####
+	// START Delete output-dir contents
+	// #####
+
+	start := time.Now()
+	err := engine.loadTemingoignore()
+	if err != nil {
+		return err
+	}
+	err = engine.deleteOutput()
+	if err != nil {
+		return err
+	}
+	engine.recordPhase("delete output-dir", start)
+
+	// #####
+	// END Delete output-dir contents
+	// START Copy static-dir contents to output-dir
+	// #####
+
+	engine.logDebug("*** Copying contents of static-dir to output-dir ... ***")
+
+	start = time.Now()
+	err = copy.Copy(engine.StaticDir, engine.OutputDir, copy.Options{
+		Skip: func(src string) (bool, error) {
+			if isSassFile(src) { // only the compiled css is written to the output-dir
+				return true, nil
+			}
+			engine.countCopied(src)
+			return false, nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+	engine.recordPhase("copy static-dir", start)
+	start = time.Now()
+	err = engine.compileSass()
+	if err != nil {
+		return err
+	}
+	engine.recordPhase("compile sass", start)
+	start = time.Now()
+	err = engine.minifyStaticFiles() // before fingerprinting, so the hashes are the ones of the minified files
+	if err != nil {
+		return err
+	}
+	err = engine.fingerprintAssets() // before rendering, so the 'asset' function knows the fingerprinted paths
+	if err != nil {
+		return err
+	}
+	err = engine.buildAssetBundles() // after fingerprinting the single files, so the bundles contain their minified contents and are added to the fingerprinted paths
+	if err != nil {
+		return err
+	}
+	engine.recordPhase("process assets", start)
+
+	// #####
+	// END Copy static-dir-contents to output-dir
+	// START Copy other contents to output-dir
+	// #####
+
+	engine.logDebug("*** Copying other contents to output-dir ... ***")
+
+	opt := copy.Options{
+		Skip: func(src string) (bool, error) {
+			if engine.isExcludedFromCopy(src) {
+				return true, nil
+			}
+			engine.countCopied(src)
+			return false, nil
+		},
+	}
+	start = time.Now()
+	err = copy.Copy(engine.InputDir, engine.OutputDir, opt)
+	if err != nil {
+		return err
+	}
+	engine.recordPhase("copy input-dir", start)
+
+	// #####
+	// END Copy other contents to output-dir
+	// START Render templates
+	// #####
+
+	engine.logDebug("*** Starting templating process ... ***")
+
+	err = engine.render()
+	if err != nil {
+		return err
+	}
+
+	// #####
+	// END Render templates
+	// START Export rendered files
+	// #####
+
+	start = time.Now()
+	err = engine.export()
+	if err != nil {
+		return err
+	}
+	engine.recordPhase("export", start)
+	start = time.Now()
+	err = engine.checkLinks() // after the exports, so links to them aren't reported
+	if err != nil {
+		return err
+	}
+	engine.recordPhase("check links", start)
+	err = engine.writeManifest()
+	if err != nil {
+		return err
+	}
+
+	// #####
+	// END Export rendered files
+	// #####
+
+	return nil
+}
+
+// export creates the files which are derived from the collections and the rendered files.
+// The exports are independent of each other, so the errors of all of them are returned at once.
+func (engine *Engine) export() error {
+	errs := BuildErrors{}
+	errs.add(engine.exportEpubs())
+	errs.add(engine.exportCalendars())
+	errs.add(engine.exportFeeds())
+	errs.add(engine.exportOpmls())
+	errs.add(engine.writeWebmentionDiscovery())
+	errs.add(engine.exportActivityPub())
+	errs.add(engine.exportPdfs())
+	errs.add(engine.exportSitemap())
+	errs.add(engine.exportSearchIndex())
+	return errs.err()
+}
+
+// isExcludedFromCopy returns whether the file at src is not copied from the inputDir to the outputDir as it is.
+// Besides the files which are rendered instead or internal, these are the ones matching the copyExclusions. Their negations ('!') copy files excluded by default.
+func (engine *Engine) isExcludedFromCopy(src string) bool {
+	src = filepath.ToSlash(src) // the copy library passes the paths with the separators of the os
+	exclusions := []string{path.Join("/", engine.PartialsDir), "**/*" + engine.TemplateExtension}
+	for _, extension := range engine.getTemplateExtensions() { // the values files of templates and markdown files
+		exclusions = append(exclusions, "**/*"+extension+templateValuesFileSuffix)
+	}
+	if engine.MarkdownExtension != "" { // markdown files are only rendered instead of copied if markdown content is enabled
+		exclusions = append(exclusions, "**/*"+engine.MarkdownExtension)
+	}
+	if engine.isTemplateValuesFile(src) { // '<name>.values.yaml', only if there is a template or markdown file with that name
+		exclusions = append(exclusions, "/"+path.Clean(src))
+	}
+	if engine.isItemFile(src) {
+		exclusions = append(exclusions, "/"+path.Clean(src))
+	}
+	for _, fileName := range engine.ItemIndexFiles {
+		exclusions = append(exclusions, "**/"+fileName)
+	}
+	for _, configFileName := range configFileNames { // per-collection config files
+		exclusions = append(exclusions, "**/"+configFileName)
+	}
+	exclusions = append(exclusions, engine.getLanguageExclusions()...)
+	exclusions = append(exclusions, engine.getEnvironmentExclusions()...)
+	if engine.isExcluded(src, append(exclusions, engine.CopyExclusions...)) { // after the ignore file and the default exclusions, so their negations take precedence
+		if engine.isExcludedByTemingoignore(src, []string{}) || !engine.matchesExclusions(src, exclusions) { // neither rendered nor copied
+			engine.countSkipped(src)
+		}
+		return true
+	}
+	return engine.isInUnpublishedItem(src) // unpublished content is counted as skipped by its index file
+}
+
+func (engine *Engine) deleteOutput() error {
+	engine.logDebug("*** Deleting contents in output-dir ... ***")
+
+	dirContents, err := ioutil.ReadDir(engine.OutputDir)
+	if err != nil {
+		return err
+	}
+	for _, element := range dirContents {
+		elementPath := path.Join(engine.OutputDir, element.Name())
+		engine.logDebug("Deleting output-dir content at: " + elementPath)
+		err = os.RemoveAll(elementPath)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package temingo
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var reviewIntervalRegexp = regexp.MustCompile(`^(\d+)\s*(d|w|m|y)$`)
+
+// ReviewItem is a page or item which is overdue for review, see Review.
+type ReviewItem struct {
+	Path         string `json:"path"`         // the site-relative path of the page or item
+	LastReviewed string `json:"lastReviewed"` // the date the content was last reviewed at
+	ReviewDue    string `json:"reviewDue"`    // the date the next review was due at
+	OverdueDays  int    `json:"overdueDays"`  // the days since the review is due
+}
+
+// getReviewDue returns when the content with the given values was last reviewed and when it's due for review again, which is its 'lastReviewed' date plus its 'reviewEvery' interval or the reviewEvery option.
+// It returns false for content without 'lastReviewed' or without an interval. name is the template, markdown file or item used in errors.
+func (engine *Engine) getReviewDue(name string, values map[string]interface{}) (time.Time, time.Time, bool, error) {
+	value, ok := values["lastReviewed"]
+	if !ok {
+		return time.Time{}, time.Time{}, false, nil
+	}
+	lastReviewed, ok := toTime(value)
+	if !ok {
+		return time.Time{}, time.Time{}, false, errors.New("The 'lastReviewed' of '" + name + "' must be a date like '2021-06-01', but is '" + toString(value) + "'.")
+	}
+	interval := engine.ReviewEvery
+	if every, ok := values["reviewEvery"]; ok {
+		interval = toString(every)
+	}
+	if interval == "" {
+		return time.Time{}, time.Time{}, false, nil
+	}
+	due, err := addReviewInterval(lastReviewed, interval)
+	if err != nil {
+		return time.Time{}, time.Time{}, false, errors.New("The 'reviewEvery' of '" + name + "' is invalid: " + err.Error())
+	}
+	return lastReviewed, due, true, nil
+}
+
+// addReviewInterval adds the interval to t. Intervals are a number followed by 'd' (days), 'w' (weeks), 'm' (months) or 'y' (years), f.e. '90d' or '6m', or a go duration like '720h'.
+func addReviewInterval(t time.Time, interval string) (time.Time, error) {
+	if match := reviewIntervalRegexp.FindStringSubmatch(interval); match != nil {
+		count, _ := strconv.Atoi(match[1]) // only digits
+		switch match[2] {
+		case "d":
+			return t.AddDate(0, 0, count), nil
+		case "w":
+			return t.AddDate(0, 0, count*7), nil
+		case "m":
+			return t.AddDate(0, count, 0), nil
+		default:
+			return t.AddDate(count, 0, 0), nil
+		}
+	}
+	duration, err := time.ParseDuration(interval)
+	if err != nil || duration <= 0 {
+		return time.Time{}, errors.New("'" + interval + "' must be a positive interval like '90d', '2w', '6m' or '1y'")
+	}
+	return t.Add(duration), nil
+}
+
+// validateReviewEvery checks the reviewEvery option is a valid interval, if it's set.
+func (engine *Engine) validateReviewEvery() error {
+	if engine.ReviewEvery == "" {
+		return nil
+	}
+	if _, err := addReviewInterval(time.Now(), engine.ReviewEvery); err != nil {
+		return errors.New("The review interval is invalid: " + err.Error())
+	}
+	return nil
+}
+
+// addReviewStatus adds 'LastReviewed', 'ReviewDue' and 'ReviewOverdue' to the entry of the page collection with the given values, if it has a review interval.
+func (engine *Engine) addReviewStatus(page map[string]interface{}, name string, values map[string]interface{}) error {
+	lastReviewed, due, ok, err := engine.getReviewDue(name, values)
+	if err != nil || !ok {
+		return err
+	}
+	page["LastReviewed"] = lastReviewed
+	page["ReviewDue"] = due
+	page["ReviewOverdue"] = !time.Now().Before(due)
+	return nil
+}
+
+// setPageReview makes when the rendered page is due for review available as '.Page.LastReviewed', '.Page.ReviewDue' and '.Page.ReviewOverdue', f.e. for a badge on outdated pages.
+// Invalid values are already reported while collecting the pages.
+func (engine *Engine) setPageReview(context map[string]interface{}, values map[string]interface{}) {
+	page, ok := context["Page"].(map[string]interface{})
+	if !ok || engine.FlatContext {
+		return
+	}
+	page["ReviewOverdue"] = false
+	if lastReviewed, due, ok, err := engine.getReviewDue("", values); err == nil && ok {
+		page["LastReviewed"] = lastReviewed
+		page["ReviewDue"] = due
+		page["ReviewOverdue"] = !time.Now().Before(due)
+	}
+}
+
+// Review returns the pages and items which are overdue for review, as their 'lastReviewed' date plus their 'reviewEvery' interval (or the reviewEvery option) lies in the past.
+// They are sorted by how long they are overdue, the longest first.
+func (engine *Engine) Review() ([]ReviewItem, error) {
+	if err := engine.validate(); err != nil {
+		return nil, err
+	}
+	if err := engine.loadTemingoignore(); err != nil {
+		return nil, err
+	}
+	engine.language = engine.getLanguages()[0] // the pages of the other languages are translations of the same content
+	defer func() { engine.language = "" }()
+	sources, err := engine.readSources()
+	if err != nil {
+		return nil, err
+	}
+	if err := engine.collectPages(sources); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	items := []ReviewItem{}
+	for _, page := range engine.sitePages {
+		values := page.(map[string]interface{})
+		due, ok := values["ReviewDue"].(time.Time)
+		if !ok || now.Before(due) {
+			continue
+		}
+		lastReviewed, _ := values["LastReviewed"].(time.Time)
+		items = append(items, ReviewItem{
+			Path:         toString(values["Path"]),
+			LastReviewed: lastReviewed.Format("2006-01-02"),
+			ReviewDue:    due.Format("2006-01-02"),
+			OverdueDays:  int(now.Sub(due).Hours() / 24),
+		})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].OverdueDays != items[j].OverdueDays {
+			return items[i].OverdueDays > items[j].OverdueDays
+		}
+		return items[i].Path < items[j].Path
+	})
+	return items, nil
+}
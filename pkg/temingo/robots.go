@@ -0,0 +1,68 @@
+package temingo
+
+import (
+	"regexp"
+)
+
+var (
+	headTagRegexp    = regexp.MustCompile(`(?i)<head(\s[^>]*)?>`)
+	robotsMetaRegexp = regexp.MustCompile(`(?i)<meta[^>]+name=["']?robots["']?`)
+)
+
+const noIndexMetaTag = `<meta name="robots" content="noindex">`
+
+// pageIndexing is how search engines are supposed to treat a page, as declared via 'noindex', 'sitemap' and 'protected' in its front matter or values.
+type pageIndexing struct {
+	NoIndex   bool // whether search engines are asked not to index the page, which also leaves it out of the sitemap
+	Sitemap   bool // whether the page is listed in the sitemap
+	Protected bool // whether the page is encrypted with the protectPassword, which also leaves it out of the search index
+}
+
+// getPageIndexing returns the indexing of the page, item or template with the given values.
+// By default pages are indexed and listed in the sitemap. 'noindex: true' asks search engines not to index the page, 'sitemap: false' only leaves it out of the sitemap.
+func getPageIndexing(values map[string]interface{}) pageIndexing {
+	indexing := pageIndexing{Sitemap: true}
+	if noIndex, ok := values["noindex"].(bool); ok && noIndex {
+		indexing.NoIndex, indexing.Sitemap = true, false
+	}
+	if sitemap, ok := values["sitemap"].(bool); ok && !sitemap {
+		indexing.Sitemap = false
+	}
+	indexing.Protected = isProtected(values)
+	return indexing
+}
+
+// setPageIndexing records the indexing of the output file, which is rendered from the page, item or template with the given values, and makes it available as '.Page.NoIndex' and '.Page.Protected'.
+func (engine *Engine) setPageIndexing(outputFilePath string, context map[string]interface{}, values map[string]interface{}) {
+	indexing := getPageIndexing(values)
+	if page, ok := context["Page"].(map[string]interface{}); ok && !engine.FlatContext {
+		page["NoIndex"] = indexing.NoIndex
+		page["Protected"] = indexing.Protected
+	}
+	engine.lock.Lock()
+	engine.pageIndexing[outputFilePath] = indexing
+	engine.lock.Unlock()
+}
+
+// getOutputIndexing returns the indexing of the output file, which is the default one for outputs not rendered from a page.
+func (engine *Engine) getOutputIndexing(outputFilePath string) pageIndexing {
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+	if indexing, ok := engine.pageIndexing[outputFilePath]; ok {
+		return indexing
+	}
+	return pageIndexing{Sitemap: true}
+}
+
+// addNoIndexMetaTag adds a robots meta tag with 'noindex' to the head of the html output, unless it already contains a robots meta tag.
+func (engine *Engine) addNoIndexMetaTag(outputFilePath string, output []byte) []byte {
+	if robotsMetaRegexp.Match(output) {
+		return output
+	}
+	location := headTagRegexp.FindIndex(output)
+	if location == nil {
+		engine.logWarn("'" + outputFilePath + "' has 'noindex' set, but no <head> the robots meta tag could be added to.")
+		return output
+	}
+	return []byte(string(output[:location[1]]) + noIndexMetaTag + string(output[location[1]:]))
+}
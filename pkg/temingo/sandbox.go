@@ -0,0 +1,118 @@
+package temingo
+
+import (
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var byteSizeRegexp = regexp.MustCompile(`^(\d+)\s*(B|KB|MB|GB)?$`)
+
+// limitedTemplate executes a template within the maxOutputSize and maxRenderTime, so runaway loops fail fast instead of hanging the build or filling the disk.
+// The time is checked whenever the template writes output or includes a partial, as templates can't be interrupted otherwise.
+type limitedTemplate struct {
+	executableTemplate
+	name          string
+	maxOutputSize int64
+	maxRenderTime time.Duration
+	deadline      time.Time // of the current execution, zero without maxRenderTime
+}
+
+// newLimitedTemplate returns tpl with the execution limits of the engine.
+func (engine *Engine) newLimitedTemplate(name string, tpl executableTemplate) executableTemplate {
+	maxOutputSize, _ := parseByteSize(engine.MaxOutputSize) // validated before
+	return &limitedTemplate{executableTemplate: tpl, name: name, maxOutputSize: maxOutputSize, maxRenderTime: engine.MaxRenderTime}
+}
+
+// Execute renders the template into wr, starting the maxRenderTime.
+func (tpl *limitedTemplate) Execute(wr io.Writer, data interface{}) error {
+	if tpl.maxRenderTime > 0 {
+		tpl.deadline = time.Now().Add(tpl.maxRenderTime)
+	}
+	return tpl.executableTemplate.Execute(&limitedWriter{writer: wr, template: tpl}, data)
+}
+
+// ExecuteTemplate renders the partial or defined template with name into wr, as used by 'include'.
+func (tpl *limitedTemplate) ExecuteTemplate(wr io.Writer, name string, data interface{}) error {
+	if err := tpl.checkDeadline(); err != nil {
+		return err
+	}
+	return tpl.executableTemplate.ExecuteTemplate(&limitedWriter{writer: wr, template: tpl}, name, data)
+}
+
+// checkDeadline returns an error once the current execution took longer than the maxRenderTime.
+func (tpl *limitedTemplate) checkDeadline() error {
+	if !tpl.deadline.IsZero() && time.Now().After(tpl.deadline) {
+		return errors.New("Rendering '" + tpl.name + "' took longer than the maximum render time of " + tpl.maxRenderTime.String() + ", does it contain an endless loop?")
+	}
+	return nil
+}
+
+// limitedWriter fails writes once the output of a template or a single include exceeds the maxOutputSize, or once its maxRenderTime is over.
+type limitedWriter struct {
+	writer   io.Writer
+	template *limitedTemplate
+	written  int64
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if err := w.template.checkDeadline(); err != nil {
+		return 0, err
+	}
+	w.written += int64(len(p))
+	if w.template.maxOutputSize > 0 && w.written > w.template.maxOutputSize {
+		return 0, errors.New("The output of '" + w.template.name + "' exceeds the maximum output size of " + formatByteSize(w.template.maxOutputSize) + ", does it contain an endless loop?")
+	}
+	return w.writer.Write(p)
+}
+
+// parseByteSize returns the number of bytes of a size like '100MB', '512KB' or '1048576'. Units are powers of 1024, '0' disables the limit.
+func parseByteSize(size string) (int64, error) {
+	match := byteSizeRegexp.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(size)))
+	if match == nil {
+		return 0, errors.New("'" + size + "' must be a size like '100MB', '512KB' or a number of bytes")
+	}
+	bytes, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	switch match[2] {
+	case "KB":
+		bytes <<= 10
+	case "MB":
+		bytes <<= 20
+	case "GB":
+		bytes <<= 30
+	}
+	return bytes, nil
+}
+
+// formatByteSize returns the size in the largest unit it's a whole multiple of, f.e. '100MB'.
+func formatByteSize(bytes int64) string {
+	for _, unit := range []struct {
+		name  string
+		shift uint
+	}{{"GB", 30}, {"MB", 20}, {"KB", 10}} {
+		if bytes >= 1<<unit.shift && bytes%(1<<unit.shift) == 0 {
+			return strconv.FormatInt(bytes>>unit.shift, 10) + unit.name
+		}
+	}
+	return strconv.FormatInt(bytes, 10) + "B"
+}
+
+// validateLimits checks the execution limits of templates are valid.
+func (engine *Engine) validateLimits() error {
+	if _, err := parseByteSize(engine.MaxOutputSize); err != nil {
+		return errors.New("The maximum output size is invalid: " + err.Error())
+	}
+	if engine.MaxRenderTime < 0 {
+		return errors.New("The maximum render time must not be negative, but is " + engine.MaxRenderTime.String())
+	}
+	if engine.MaxIncludeDepth < 1 {
+		return errors.New("The maximum include depth must be positive, but is " + strconv.Itoa(engine.MaxIncludeDepth))
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package temingo
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// isSassFile returns whether the file at filePath is a Sass stylesheet, in either the scss or the indented syntax.
+func isSassFile(filePath string) bool {
+	extension := path.Ext(filePath)
+	return extension == ".scss" || extension == ".sass"
+}
+
+// getStaticOutputPath returns the path a static file at the relativePath is written to in the outputDir, which differs for Sass stylesheets, f.e. 'css/app.scss' to 'css/app.css'.
+// Sass partials, whose name starts with '_', are only imported by other stylesheets, so they have no output and an empty path is returned.
+func getStaticOutputPath(relativePath string) string {
+	if !isSassFile(relativePath) {
+		return relativePath
+	}
+	if strings.HasPrefix(path.Base(relativePath), "_") {
+		return ""
+	}
+	return strings.TrimSuffix(relativePath, path.Ext(relativePath)) + ".css"
+}
+
+// compileSass compiles all Sass stylesheets in the staticDir to css in the outputDir, via the external sassCommand.
+// As stylesheets can import each other, all of them are compiled, even while watching. The compiled css is cached until one of the stylesheets changes, see runCachedFileCommand.
+func (engine *Engine) compileSass() error {
+	stylesheets := []string{} // all of them, as any of them can be imported
+	err := filepath.Walk(engine.StaticDir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && isSassFile(filePath) {
+			stylesheets = append(stylesheets, filePath)
+		}
+		return nil
+	})
+	if err != nil || len(stylesheets) == 0 {
+		return err
+	}
+	return filepath.Walk(engine.StaticDir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !isSassFile(filePath) {
+			return nil
+		}
+		relativePath, err := filepath.Rel(engine.StaticDir, filePath)
+		if err != nil {
+			return err
+		}
+		outputPath := getStaticOutputPath(filepath.ToSlash(relativePath))
+		if outputPath == "" { // a partial
+			return nil
+		}
+		outputFilePath, err := engine.getOutputFilePath(outputPath)
+		if err != nil {
+			return err
+		}
+		engine.createFolderIfNotExists(path.Dir(outputFilePath))
+		return engine.runCachedFileCommand(engine.SassCommand, "Sass compilation", filePath, outputFilePath, stylesheets)
+	})
+}
+
+// getSassSource returns the path of the Sass stylesheet in the staticDir which is compiled to the css file at the relativePath, if there is one.
+func (engine *Engine) getSassSource(relativePath string) (string, bool) {
+	if path.Ext(relativePath) != ".css" {
+		return "", false
+	}
+	for _, extension := range []string{".scss", ".sass"} {
+		sourcePath := path.Join(engine.StaticDir, strings.TrimSuffix(relativePath, ".css")+extension)
+		if info, err := os.Stat(sourcePath); err == nil && !info.IsDir() {
+			return sourcePath, true
+		}
+	}
+	return "", false
+}
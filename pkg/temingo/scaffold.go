@@ -0,0 +1,34 @@
+package temingo
+
+// The files of the example project created by Init. The templates and partials are named after the configured extensions.
+const (
+	scaffoldTemplate = `<!DOCTYPE html>
+<html lang="en">
+{{ template "header" . }}
+<body>
+  <main>
+    <h1>{{ .Values.title }}</h1>
+    <p>{{ .Values.description }}</p>
+  </main>
+{{ template "footer" . }}
+</body>
+</html>
+`
+	scaffoldHeaderPartial = `<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{ .Values.title }}</title>
+</head>
+`
+	scaffoldFooterPartial = `<footer>
+  <p>Built with temingo {{ .Build.Version }}</p>
+</footer>
+`
+	scaffoldValues = `# values available in all templates via '.Values'
+title: My site
+description: A site rendered by temingo.
+`
+	scaffoldTemingoignore = `# files and folders which are neither rendered nor copied to the output-dir, with the syntax of a '.gitignore'
+# the values files are still read, but not published
+`
+)
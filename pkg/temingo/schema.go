@@ -0,0 +1,286 @@
+package temingo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const itemSchemaFileName = "schema.yaml"
+
+func init() {
+	configFileNames = append(configFileNames, itemSchemaFileName)
+}
+
+// schemaTypes are the types of values a schema can require.
+var schemaTypes = []string{"any", "string", "number", "bool", "date", "list", "map"}
+
+// schemaField describes a value in a schema. In schema files, a field can be written as its type only, f.e. 'title: string'.
+type schemaField struct {
+	Type     string                  `yaml:"type"`     // one of schemaTypes, defaults to 'any'
+	Required bool                    `yaml:"required"` // whether the value must be set and not null
+	Values   []interface{}           `yaml:"values"`   // the allowed values, if only some are
+	Keys     map[string]*schemaField `yaml:"keys"`     // the keys of a map, other keys are reported as unknown. Maps without keys can contain any
+	Items    *schemaField            `yaml:"items"`    // the elements of a list
+}
+
+// UnmarshalYAML reads a field, which is either its type or a map with its properties.
+func (field *schemaField) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		field.Type = node.Value
+		return nil
+	}
+	type plainField schemaField // without this method, so it doesn't recurse
+	var plain plainField
+	if err := node.Decode(&plain); err != nil {
+		return err
+	}
+	*field = schemaField(plain)
+	return nil
+}
+
+// loadSchema reads the schema file at filePath, which maps the keys of the validated values to their fields.
+func loadSchema(filePath string) (map[string]*schemaField, error) {
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	schema := make(map[string]*schemaField)
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true) // so typos in the schema itself are reported as well
+	if err := decoder.Decode(&schema); err != nil && err.Error() != "EOF" {
+		return nil, errors.New("Could not parse the schema '" + filePath + "': " + err.Error())
+	}
+	if err := checkSchema(schema, ""); err != nil {
+		return nil, errors.New("The schema '" + filePath + "' is invalid: " + err.Error())
+	}
+	return schema, nil
+}
+
+// checkSchema checks the types of the fields of schema are known. keyPath is the dotted key path of the schema, empty at the top level.
+func checkSchema(schema map[string]*schemaField, keyPath string) error {
+	for key, field := range schema {
+		if field == nil {
+			return errors.New("the key '" + keyPath + key + "' has no type")
+		}
+		if err := checkSchemaField(field, keyPath+key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkSchemaField(field *schemaField, keyPath string) error {
+	if field.Type == "" {
+		field.Type = "any"
+	}
+	known := false
+	for _, schemaType := range schemaTypes {
+		known = known || field.Type == schemaType
+	}
+	if !known {
+		return errors.New("the type '" + field.Type + "' of '" + keyPath + "' must be one of '" + strings.Join(schemaTypes, "', '") + "'")
+	}
+	if len(field.Keys) > 0 && field.Type != "map" {
+		return errors.New("'" + keyPath + "' has keys, but isn't a map")
+	}
+	if field.Items != nil {
+		if field.Type != "list" {
+			return errors.New("'" + keyPath + "' has items, but isn't a list")
+		}
+		if err := checkSchemaField(field.Items, keyPath+"[]"); err != nil {
+			return err
+		}
+	}
+	return checkSchema(field.Keys, keyPath+".")
+}
+
+// validateValues returns the mismatches of values and schema, sorted. keyPath is the dotted key path of the values, empty at the top level.
+func validateValues(values map[string]interface{}, schema map[string]*schemaField, keyPath string) []string {
+	mismatches := []string{}
+	keys := []string{}
+	for key := range schema {
+		keys = append(keys, key)
+	}
+	for key, value := range values {
+		field, ok := schema[key]
+		if !ok {
+			message := "the key '" + keyPath + key + "' is unknown"
+			if suggestion := getClosestKey(key, keys); suggestion != "" {
+				message += " (did you mean '" + keyPath + suggestion + "'?)"
+			}
+			mismatches = append(mismatches, message)
+			continue
+		}
+		mismatches = append(mismatches, validateValue(value, field, keyPath+key)...)
+	}
+	for key, field := range schema {
+		if value, ok := values[key]; field.Required && (!ok || value == nil) {
+			mismatches = append(mismatches, "the required key '"+keyPath+key+"' is missing")
+		}
+	}
+	sort.Strings(mismatches)
+	return mismatches
+}
+
+// validateValue returns the mismatches of value at keyPath and its field.
+func validateValue(value interface{}, field *schemaField, keyPath string) []string {
+	if value == nil { // missing required values are reported by validateValues
+		return nil
+	}
+	valid := true
+	switch field.Type {
+	case "string":
+		_, valid = value.(string)
+	case "number":
+		_, valid = toFloat(value)
+	case "bool":
+		_, valid = value.(bool)
+	case "date":
+		_, valid = toTime(value)
+	case "list":
+		_, valid = value.([]interface{})
+	case "map":
+		_, valid = value.(map[string]interface{})
+	}
+	if !valid {
+		return []string{fmt.Sprintf("the value of '%s' must be a %s, but is '%v'", keyPath, field.Type, value)}
+	}
+	if len(field.Values) > 0 {
+		allowed := []string{}
+		for _, allowedValue := range field.Values {
+			allowed = append(allowed, fmt.Sprint(allowedValue))
+			if fmt.Sprint(allowedValue) == fmt.Sprint(value) {
+				allowed = nil
+				break
+			}
+		}
+		if allowed != nil {
+			return []string{fmt.Sprintf("the value of '%s' must be one of '%s', but is '%v'", keyPath, strings.Join(allowed, "', '"), value)}
+		}
+	}
+	mismatches := []string{}
+	if list, ok := value.([]interface{}); ok && field.Items != nil {
+		for i, element := range list {
+			mismatches = append(mismatches, validateValue(element, field.Items, fmt.Sprintf("%s[%d]", keyPath, i))...)
+		}
+	}
+	if nested, ok := value.(map[string]interface{}); ok && len(field.Keys) > 0 {
+		mismatches = append(mismatches, validateValues(nested, field.Keys, keyPath+".")...)
+	}
+	return mismatches
+}
+
+// getClosestKey returns the key of keys which is most likely meant by the unknown key, f.e. 'title' for 'titel', or an empty string if none is similar enough.
+func getClosestKey(key string, keys []string) string {
+	sort.Strings(keys) // so ties result in the same suggestion every time
+	closest := ""
+	closestDistance := len(key)/3 + 1 // roughly one typo per three characters
+	for _, candidate := range keys {
+		if distance := getEditDistance(strings.ToLower(key), strings.ToLower(candidate)); distance < closestDistance {
+			closest, closestDistance = candidate, distance
+		}
+	}
+	return closest
+}
+
+// getEditDistance returns the number of inserted, deleted, replaced or swapped adjacent characters which turn a into b.
+func getEditDistance(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	distances := make([][]int, len(ra)+1)
+	for i := range distances {
+		distances[i] = make([]int, len(rb)+1)
+		distances[i][0] = i
+	}
+	for j := range distances[0] {
+		distances[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			distances[i][j] = minInt(distances[i-1][j]+1, distances[i][j-1]+1, distances[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				distances[i][j] = minInt(distances[i][j], distances[i-2][j-2]+1)
+			}
+		}
+	}
+	return distances[len(ra)][len(rb)]
+}
+
+func minInt(values ...int) int {
+	min := values[0]
+	for _, value := range values[1:] {
+		if value < min {
+			min = value
+		}
+	}
+	return min
+}
+
+// validateSchemas validates the merged values against the valuesSchema and the items of each collection with a 'schema.yaml' against it, before anything is rendered.
+// The mismatches of all files are returned at once.
+func (engine *Engine) validateSchemas(values map[string]interface{}) error {
+	errs := BuildErrors{}
+	if engine.ValuesSchema != "" {
+		schema, err := loadSchema(engine.ValuesSchema)
+		if err != nil {
+			return err
+		}
+		for _, mismatch := range validateValues(values, schema, "") {
+			errs.add(errors.New("The values don't match the schema '" + engine.ValuesSchema + "': " + mismatch + "."))
+		}
+	}
+
+	schemaFiles, err := engine.getConfigFiles(itemSchemaFileName)
+	if err != nil {
+		return err
+	}
+	for _, schemaFile := range schemaFiles {
+		schema, err := loadSchema(schemaFile)
+		if err != nil {
+			errs.add(err)
+			continue
+		}
+		itemIndexPaths, err := engine.getItemIndexPaths(path.Dir(schemaFile), false)
+		if err != nil {
+			return err
+		}
+		itemPaths := []string{}
+		for itemPath := range itemIndexPaths {
+			itemPaths = append(itemPaths, itemPath)
+		}
+		sort.Strings(itemPaths) // so the errors are always in the same order
+		for _, itemPath := range itemPaths {
+			indexPath := itemIndexPaths[itemPath]
+			sectionValues, err := engine.getSectionValues(itemPath)
+			if err != nil {
+				return err
+			}
+			var itemValues map[string]interface{}
+			if engine.isMarkdownFile(indexPath) {
+				itemValues, err = engine.loadItemIndexFile(indexPath)
+				for _, key := range []string{"Content", "Summary", "ReadingTime"} { // added by temingo, not part of the file
+					delete(itemValues, key)
+				}
+			} else {
+				itemValues, err = loadDataFile(indexPath) // without the 'Summary' and 'ReadingTime' added by temingo
+			}
+			if err != nil {
+				return err
+			}
+			for _, mismatch := range validateValues(mergeValues(sectionValues, itemValues), schema, "") {
+				errs.add(errors.New("'" + indexPath + "' doesn't match the schema '" + schemaFile + "': " + mismatch + "."))
+			}
+		}
+	}
+	return errs.err()
+}
@@ -0,0 +1,144 @@
+package temingo
+
+import (
+	"encoding/json"
+	"html"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tdewolff/parse/v2"
+	htmlparse "github.com/tdewolff/parse/v2/html"
+)
+
+const searchIndexFileName = "search-index.json"
+
+// searchIndexEntry is a page in the 'search-index.json', as a flat document lunr.js and Fuse.js can index directly, f.e. with 'url' as ref of lunr.js.
+type searchIndexEntry struct {
+	URL         string   `json:"url"`         // the site-relative url of the page
+	Title       string   `json:"title"`       // the 'title' value of the page, or its '<title>'
+	Description string   `json:"description"` // the 'description' value of the page
+	Tags        []string `json:"tags"`        // the 'tags' value of the page
+	Content     string   `json:"content"`     // the text of the page, without markup, scripts and styles
+}
+
+// searchIndexSkippedTags are the elements whose text isn't content of the page.
+var searchIndexSkippedTags = map[string]bool{"head": true, "script": true, "style": true, "noscript": true, "template": true, "svg": true}
+
+// exportSearchIndex writes a 'search-index.json' to the outputDir, which contains the title, url, tags and text of all rendered html pages, for client-side search.
+// Pages with 'noindex' or 'protected' are left out. It's skipped if the site provides its own 'search-index.json' - as template, static or input file.
+func (engine *Engine) exportSearchIndex() error {
+	if !engine.SearchIndex {
+		return nil
+	}
+	outputFilePath, err := engine.getOutputFilePath(searchIndexFileName)
+	if err != nil {
+		return err
+	}
+	for _, ownPath := range []string{path.Join(engine.StaticDir, searchIndexFileName), path.Join(engine.InputDir, searchIndexFileName)} {
+		if _, err := os.Stat(ownPath); err == nil {
+			return nil
+		}
+	}
+
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+	if _, ok := engine.renderedSources[outputFilePath]; ok {
+		return nil
+	}
+
+	pageValues := make(map[string]map[string]interface{}) // the values of the pages, by their url without trailing 'index.html' and '/'
+	for _, page := range engine.sitePages {
+		values := page.(map[string]interface{})
+		pageValues[strings.TrimSuffix(strings.TrimSuffix(toString(values["Path"]), "index.html"), "/")] = values
+	}
+	outputFilePaths := []string{}
+	for filePath := range engine.renderedSources {
+		if indexing, ok := engine.pageIndexing[filePath]; engine.isHtmlOutputLocked(filePath) && (!ok || !indexing.NoIndex && !indexing.Protected) {
+			outputFilePaths = append(outputFilePaths, filePath)
+		}
+	}
+	sort.Strings(outputFilePaths)
+
+	entries := make(map[string]searchIndexEntry)
+	index := []searchIndexEntry{}
+	for _, filePath := range outputFilePaths {
+		entry, ok := engine.searchIndexEntries[filePath]
+		if !ok || engine.renderedFiles == nil || engine.renderedFiles[filePath] { // entries of outputs that weren't rerendered are still up to date
+			relativePath, err := filepath.Rel(engine.OutputDir, filePath)
+			if err != nil {
+				return err
+			}
+			content, err := ioutil.ReadFile(filePath)
+			if err != nil {
+				return err
+			}
+			pagePath := "/" + strings.TrimSuffix(filepath.ToSlash(relativePath), "index.html") // 'blog/index.html' is served as 'blog/'
+			entry = getSearchIndexEntry(pagePath, content, pageValues[strings.TrimSuffix(pagePath, "/")])
+		}
+		entries[filePath] = entry
+		index = append(index, entry)
+	}
+	engine.searchIndexEntries = entries
+
+	content, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	engine.logDebug("Writing search index '" + outputFilePath + "' with " + strconv.Itoa(len(index)) + " page(s) ...")
+	return engine.writeTemplateToFile(outputFilePath, append(content, '\n'))
+}
+
+// getSearchIndexEntry returns the entry of the html page at pagePath with the rendered content. values are the ones of the page in the 'pages' collection, if it's part of it.
+func getSearchIndexEntry(pagePath string, content []byte, values map[string]interface{}) searchIndexEntry {
+	entry := searchIndexEntry{URL: pagePath, Tags: []string{}}
+	text := new(strings.Builder)
+	lexer := htmlparse.NewLexer(parse.NewInputBytes(content))
+	skipped := ""    // the element whose text is currently skipped
+	inTitle := false // whether the lexer is inside the '<title>'
+	for {
+		tokenType, data := lexer.Next()
+		if tokenType == htmlparse.ErrorToken { // the end of the page
+			break
+		}
+		switch tokenType {
+		case htmlparse.StartTagToken:
+			tag := strings.ToLower(string(lexer.Text()))
+			inTitle = tag == "title"
+			if skipped == "" && searchIndexSkippedTags[tag] {
+				skipped = tag
+			}
+			text.WriteString(" ") // f.e. paragraphs and list items separate words
+		case htmlparse.EndTagToken:
+			tag := strings.ToLower(string(lexer.Text()))
+			if tag == skipped {
+				skipped = ""
+			}
+			inTitle = false
+			text.WriteString(" ")
+		case htmlparse.TextToken:
+			if inTitle && entry.Title == "" {
+				entry.Title = strings.Join(strings.Fields(html.UnescapeString(string(data))), " ")
+			}
+			if skipped == "" {
+				text.Write(data)
+			}
+		}
+	}
+	entry.Content = strings.Join(strings.Fields(html.UnescapeString(text.String())), " ")
+
+	if title := toString(values["title"]); title != "" {
+		entry.Title = title
+	}
+	entry.Description = toString(values["description"])
+	if tags, ok := values["tags"].([]interface{}); ok {
+		for _, tag := range tags {
+			entry.Tags = append(entry.Tags, toString(tag))
+		}
+	}
+	return entry
+}
@@ -0,0 +1,97 @@
+package temingo
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/imdario/mergo"
+)
+
+const sectionValuesFileName = "_index.yaml"
+
+func init() {
+	configFileNames = append(configFileNames, sectionValuesFileName)
+}
+
+// getSectionValues returns the cascaded values of all '_index.yaml' files from the inputDir down to dirPath.
+// Values of deeper folders override the ones of their parents.
+func (engine *Engine) getSectionValues(dirPath string) (map[string]interface{}, error) {
+	dirPath = path.Clean(filepath.ToSlash(dirPath))
+	engine.lock.Lock()
+	cached, ok := engine.sectionValuesCache[dirPath]
+	engine.lock.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	dirs := []string{dirPath}
+	if relativePath, err := filepath.Rel(engine.InputDir, dirPath); err == nil && !strings.HasPrefix(relativePath, "..") {
+		dirs = []string{engine.InputDir}
+		if relativePath != "." {
+			currentPath := engine.InputDir
+			for _, dirName := range strings.Split(filepath.ToSlash(relativePath), "/") {
+				currentPath = path.Join(currentPath, dirName)
+				dirs = append(dirs, currentPath)
+			}
+		}
+	}
+
+	sectionValues := make(map[string]interface{})
+	for _, dir := range dirs {
+		sectionValuesFilePath := path.Join(dir, sectionValuesFileName)
+		if _, err := os.Stat(sectionValuesFilePath); err != nil {
+			continue
+		}
+		engine.logDebug("Cascading section values from '" + sectionValuesFilePath + "' to '" + dirPath + "'.")
+		values, err := engine.loadValuesFile(sectionValuesFilePath)
+		if err != nil {
+			return nil, err
+		}
+		err = mergo.Merge(&sectionValues, copyValues(values), mergo.WithOverride)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	engine.lock.Lock()
+	engine.sectionValuesCache[dirPath] = sectionValues
+	engine.lock.Unlock()
+	return sectionValues, nil
+}
+
+// mergeValues returns a new map with the values of all layers, where later layers override earlier ones. The layers themselves are not modified.
+func mergeValues(layers ...map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{})
+	for _, layer := range layers {
+		mergo.Merge(&merged, copyValues(layer), mergo.WithOverride) // can't fail, as both are maps of the same type
+	}
+	return merged
+}
+
+// copyValues deep-copies values loaded from yaml, so merging into the copy can't modify the original.
+func copyValues(values map[string]interface{}) map[string]interface{} {
+	if values == nil {
+		return nil
+	}
+	return copyValue(values).(map[string]interface{})
+}
+
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, element := range v {
+			copied[key] = copyValue(element)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, element := range v {
+			copied[i] = copyValue(element)
+		}
+		return copied
+	}
+	return value
+}
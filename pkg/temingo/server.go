@@ -0,0 +1,54 @@
+package temingo
+
+import (
+	"os"
+	"path"
+)
+
+const serverConfigFileName = "server.yaml"
+
+func init() {
+	configFileNames = append(configFileNames, serverConfigFileName)
+}
+
+// serverConfig is the content of the 'server.yaml' in the inputDir.
+// It's the data source templates of server configuration files (f.e. '.htaccess.template') render their redirects and headers from.
+type serverConfig struct {
+	Redirects []serverRedirect `yaml:"redirects"`
+	Headers   []serverHeader   `yaml:"headers"`
+}
+
+type serverRedirect struct {
+	From   string `yaml:"from"`
+	To     string `yaml:"to"`
+	Status int    `yaml:"status"` // defaults to 301
+}
+
+type serverHeader struct {
+	Path   string            `yaml:"path"`
+	Values map[string]string `yaml:"values"`
+}
+
+// loadServerConfig reads the 'server.yaml' in the inputDir. If there is none, the config is empty.
+func (engine *Engine) loadServerConfig() (serverConfig, error) {
+	config := serverConfig{
+		Redirects: []serverRedirect{},
+		Headers:   []serverHeader{},
+	}
+
+	configFilePath := path.Join(engine.InputDir, serverConfigFileName)
+	if _, err := os.Stat(configFilePath); os.IsNotExist(err) {
+		return config, nil
+	}
+	if err := loadConfigFile(configFilePath, &config); err != nil {
+		return config, err
+	}
+
+	for i, redirect := range config.Redirects {
+		if redirect.Status == 0 {
+			config.Redirects[i].Status = 301
+		}
+	}
+
+	return config, nil
+}
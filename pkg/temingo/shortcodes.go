@@ -0,0 +1,133 @@
+package temingo
+
+import (
+	"bytes"
+	"errors"
+	"html"
+	"html/template"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// shortcodesDir is the folder in the partialsDir containing the shortcodes, f.e. 'partials/shortcodes/youtube.partial' for '{{< youtube id >}}'.
+const shortcodesDir = "shortcodes"
+
+var (
+	shortcodeTagRegexp      = regexp.MustCompile(`{{<\s*(/?)([\w-][\w/-]*)((?:\s+(?:"[^"]*"|[^\s"=>]+(?:="[^"]*"|=[^\s">]+)?))*)\s*>}}`) // opening and closing tags, with the arguments of opening tags
+	shortcodeArgumentRegexp = regexp.MustCompile(`(?:([\w-]+)=)?("[^"]*"|[^\s"]+)`)                                                      // positional and named arguments
+	shortcodeEscapeRegexp   = regexp.MustCompile(`{{</\*(.*?)\*/>}}`)                                                                    // shortcodes that are shown literally, f.e. in code blocks
+)
+
+// convertMarkdown converts the markdown body of the content file at source to html, including its shortcodes.
+// Shortcodes are replaced with placeholders before the conversion, so the markdown renderer can't alter their output, and rendered afterwards.
+func (engine *Engine) convertMarkdown(source string, body string) (template.HTML, error) {
+	placeholders := []string{}
+	addPlaceholder := func(output string) string {
+		placeholders = append(placeholders, output)
+		return "TEMINGOSHORTCODE" + strconv.Itoa(len(placeholders)-1) + "END"
+	}
+
+	body = shortcodeEscapeRegexp.ReplaceAllStringFunc(body, func(escaped string) string {
+		return addPlaceholder(html.EscapeString("{{<" + shortcodeEscapeRegexp.FindStringSubmatch(escaped)[1] + ">}}"))
+	})
+	templates := make(map[string]executableTemplate) // parsed once per shortcode and content file
+	expanded := new(strings.Builder)
+	for {
+		location := shortcodeTagRegexp.FindStringSubmatchIndex(body)
+		if location == nil {
+			expanded.WriteString(body)
+			break
+		}
+		name, arguments := body[location[4]:location[5]], body[location[6]:location[7]]
+		if location[3] > location[2] { // a closing tag without opening tag
+			return "", errors.New("The shortcode '" + name + "' in '" + source + "' is closed, but wasn't opened.")
+		}
+		inner, rest, paired := getShortcodeInner(name, body[location[1]:])
+		context := map[string]interface{}{
+			"Name":   name,
+			"Args":   []interface{}{},
+			"Params": map[string]interface{}{},
+			"Source": source,
+		}
+		for _, argument := range shortcodeArgumentRegexp.FindAllStringSubmatch(arguments, -1) {
+			value := strings.TrimSuffix(strings.TrimPrefix(argument[2], `"`), `"`)
+			if argument[1] == "" {
+				context["Args"] = append(context["Args"].([]interface{}), value)
+			} else {
+				context["Params"].(map[string]interface{})[argument[1]] = value
+			}
+		}
+		if paired {
+			innerHTML, err := engine.convertMarkdown(source, inner)
+			if err != nil {
+				return "", err
+			}
+			context["Inner"] = innerHTML
+		}
+
+		tpl, ok := templates[name]
+		if !ok {
+			partialName := path.Join(shortcodesDir, name)
+			if !engine.partialExists(partialName) {
+				return "", errors.New("The shortcode '" + name + "' in '" + source + "' does not exist, it has to be the partial '" + partialName + "'.")
+			}
+			parsed, err := engine.parseTemplateFiles(source, getLayoutInvocation(partialName), engine.partials, nil)
+			if err != nil {
+				return "", err
+			}
+			tpl, templates[name] = parsed, parsed
+		}
+		output := new(bytes.Buffer)
+		if err := tpl.Execute(output, context); err != nil {
+			return "", errors.New("Could not render the shortcode '" + name + "' in '" + source + "': " + err.Error())
+		}
+		expanded.WriteString(body[:location[0]])
+		expanded.WriteString(addPlaceholder(strings.TrimSuffix(output.String(), "\n"))) // the final newline of the partial file
+		body = rest
+	}
+
+	converted := new(bytes.Buffer)
+	if err := markdownRenderer.Convert([]byte(expanded.String()), converted); err != nil {
+		return "", errors.New("Could not convert '" + source + "' to html: " + err.Error())
+	}
+	content := converted.String()
+	for i, output := range placeholders {
+		placeholder := "TEMINGOSHORTCODE" + strconv.Itoa(i) + "END"
+		content = strings.ReplaceAll(content, "<p>"+placeholder+"</p>", output) // shortcodes on their own line aren't wrapped into paragraphs
+		content = strings.ReplaceAll(content, placeholder, output)
+	}
+	return template.HTML(content), nil
+}
+
+// getShortcodeInner returns the content between the opening tag of the shortcode with name and its closing tag, and what follows the closing tag.
+// rest starts right after the opening tag. Without closing tag, the shortcode has no inner content.
+func getShortcodeInner(name string, rest string) (string, string, bool) {
+	depth := 0
+	for _, location := range shortcodeTagRegexp.FindAllStringSubmatchIndex(rest, -1) {
+		if rest[location[4]:location[5]] != name {
+			continue
+		}
+		if location[3] == location[2] { // the same shortcode nested into itself
+			depth++
+			continue
+		}
+		if depth > 0 {
+			depth--
+			continue
+		}
+		return rest[:location[0]], rest[location[1]:], true
+	}
+	return "", rest, false
+}
+
+// partialExists returns whether one of the partials is named name or defines a template with it.
+func (engine *Engine) partialExists(name string) bool {
+	for _, partial := range engine.partials {
+		if partial[0] == name || templateDefinesName(partial[1], name) {
+			return true
+		}
+	}
+	return false
+}
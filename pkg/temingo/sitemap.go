@@ -0,0 +1,113 @@
+package temingo
+
+import (
+	"encoding/xml"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const sitemapFileName = "sitemap.xml"
+
+type sitemapDocument struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Location     string `xml:"loc"`
+	LastModified string `xml:"lastmod,omitempty"`
+}
+
+// exportSitemap writes a 'sitemap.xml' to the outputDir, which lists all rendered html pages with the last modification of their source files.
+// It's skipped without a baseURL, as sitemaps require absolute URLs, and if the site provides its own 'sitemap.xml' - as template, static or input file.
+func (engine *Engine) exportSitemap() error {
+	if !engine.Sitemap {
+		return nil
+	}
+	if engine.siteBaseURL == "" {
+		engine.logDebug("*** Skipping the sitemap, as neither the '--baseURL' flag nor the 'baseURL' value is set ***")
+		return nil
+	}
+	outputFilePath, err := engine.getOutputFilePath(sitemapFileName)
+	if err != nil {
+		return err
+	}
+	for _, ownPath := range []string{path.Join(engine.StaticDir, sitemapFileName), path.Join(engine.InputDir, sitemapFileName)} {
+		if _, err := os.Stat(ownPath); err == nil {
+			return nil
+		}
+	}
+
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+	if _, ok := engine.renderedSources[outputFilePath]; ok {
+		return nil
+	}
+
+	outputFilePaths := []string{}
+	for filePath := range engine.renderedSources {
+		if indexing, ok := engine.pageIndexing[filePath]; engine.isHtmlOutputLocked(filePath) && (!ok || indexing.Sitemap) { // pages with 'noindex' or 'sitemap: false' are left out
+			outputFilePaths = append(outputFilePaths, filePath)
+		}
+	}
+	sort.Strings(outputFilePaths)
+
+	document := sitemapDocument{Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+	changed := engine.renderedFiles == nil // full builds always write the sitemap, incremental rebuilds only if an entry changed
+	urls := make(map[string]sitemapURL)
+	for _, filePath := range outputFilePaths {
+		url, ok := engine.sitemapURLs[filePath]
+		if !ok || engine.renderedFiles == nil || engine.renderedFiles[filePath] { // entries of outputs that weren't rerendered are still up to date
+			relativePath, err := filepath.Rel(engine.OutputDir, filePath)
+			if err != nil {
+				return err
+			}
+			pagePath := "/" + strings.TrimSuffix(filepath.ToSlash(relativePath), "index.html") // 'blog/index.html' is served as 'blog/'
+			url = sitemapURL{Location: engine.absoluteURL(pagePath)}
+			if lastModified, ok := getLastModification(engine.renderedSources[filePath]); ok {
+				url.LastModified = lastModified.UTC().Format(time.RFC3339)
+			}
+		}
+		if url != engine.sitemapURLs[filePath] {
+			changed = true
+		}
+		urls[filePath] = url
+		document.URLs = append(document.URLs, url)
+	}
+	if len(urls) != len(engine.sitemapURLs) {
+		changed = true
+	}
+	engine.sitemapURLs = urls
+	if !changed {
+		engine.logDebug("*** Keeping the sitemap, as none of its entries changed ***")
+		return nil
+	}
+
+	content, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return err
+	}
+	engine.logDebug("Writing sitemap '" + outputFilePath + "' with " + strconv.Itoa(len(document.URLs)) + " page(s) ...")
+	return engine.writeTemplateToFile(outputFilePath, append([]byte(xml.Header), append(content, '\n')...))
+}
+
+// getLastModification returns the most recent modification time of the files, and false if none of them exists.
+func getLastModification(filePaths []string) (time.Time, bool) {
+	lastModified, ok := time.Time{}, false
+	for _, filePath := range filePaths {
+		info, err := os.Stat(filePath)
+		if err != nil {
+			continue
+		}
+		if info.ModTime().After(lastModified) {
+			lastModified, ok = info.ModTime(), true
+		}
+	}
+	return lastModified, ok
+}
@@ -0,0 +1,222 @@
+package temingo
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// stagingDir is the folder in the cacheDir full builds are rendered to, before the outputDir is updated from it.
+var stagingDir = path.Join(cacheDir, "build")
+
+// rebuildOutput renders the whole site to the stagingDir and then updates the outputDir from it.
+// Only new and changed files are written to the outputDir and files that weren't built again are deleted, so unchanged files keep their modification time, f.e. for deployments via rsync.
+// A failed build leaves the outputDir untouched.
+func (engine *Engine) rebuildOutput() error {
+	engine.phases = nil
+	defer engine.logPhases(time.Now())
+	outputDir := engine.OutputDir
+	if err := os.MkdirAll(stagingDir, engine.dirMode); err != nil {
+		return err
+	}
+	engine.OutputDir, engine.actualOutputDir = stagingDir, outputDir
+	err := engine.renderOutput()
+	engine.OutputDir, engine.actualOutputDir = outputDir, ""
+	if err != nil {
+		return err
+	}
+
+	engine.logDebug("*** Updating the output-dir with the changed files ... ***")
+	start := time.Now()
+	if err := engine.syncOutput(stagingDir, outputDir); err != nil {
+		return err
+	}
+	engine.recordPhase("update output-dir", start)
+	engine.rebaseOutputPaths(stagingDir, outputDir)
+	if err := os.RemoveAll(stagingDir); err != nil {
+		return err
+	}
+	engine.logSummary("Successfully built contents")
+	return nil
+}
+
+// syncOutput updates the files in outputDir to the ones in sourceDir, which are moved there if they are new or changed. Files and folders of the outputDir which aren't in sourceDir are deleted.
+func (engine *Engine) syncOutput(sourceDir string, outputDir string) error {
+	built := make(map[string]bool) // relative paths of the files and folders in sourceDir
+	written, unchanged, deleted := 0, 0, 0
+
+	err := filepath.Walk(sourceDir, func(sourcePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relativePath, err := filepath.Rel(sourceDir, sourcePath)
+		if err != nil || relativePath == "." {
+			return err
+		}
+		built[filepath.ToSlash(relativePath)] = true
+		outputPath := filepath.Join(outputDir, relativePath)
+
+		existing, err := os.Lstat(outputPath)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if existing != nil && existing.IsDir() != info.IsDir() { // f.e. a file that became a folder
+			if err := os.RemoveAll(outputPath); err != nil {
+				return err
+			}
+			existing = nil
+		}
+		if info.IsDir() {
+			if existing == nil {
+				return os.Mkdir(outputPath, info.Mode().Perm())
+			}
+			return nil
+		}
+
+		if existing != nil {
+			same, err := isSameFile(sourcePath, info, outputPath, existing)
+			if err != nil {
+				return err
+			}
+			if same {
+				unchanged++
+				if existing.Mode() != info.Mode() {
+					return os.Chmod(outputPath, info.Mode())
+				}
+				return nil
+			}
+			if err := os.Remove(outputPath); err != nil {
+				return err
+			}
+		}
+		written++
+		if err := os.Rename(sourcePath, outputPath); err == nil {
+			return nil
+		}
+		return engine.copyStagedFile(sourcePath, info, outputPath) // f.e. if the outputDir is on another device
+	})
+	if err != nil {
+		return err
+	}
+
+	err = filepath.Walk(outputDir, func(outputPath string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) { // inside a folder deleted before
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		relativePath, err := filepath.Rel(outputDir, outputPath)
+		if err != nil || relativePath == "." {
+			return err
+		}
+		if built[filepath.ToSlash(relativePath)] {
+			return nil
+		}
+		engine.logDebug("Deleting stale output-dir content at: " + outputPath)
+		deleted++
+		if err := os.RemoveAll(outputPath); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	engine.logDebug("Wrote", written, "new or changed file(s) to the output-dir, kept", unchanged, "unchanged one(s) and deleted", deleted, "stale one(s).")
+	return nil
+}
+
+// isSameFile returns whether the file at sourcePath has the same content as the one at outputPath, or points to the same target if both are symlinks.
+func isSameFile(sourcePath string, source os.FileInfo, outputPath string, output os.FileInfo) (bool, error) {
+	if source.Mode()&os.ModeSymlink != 0 || output.Mode()&os.ModeSymlink != 0 {
+		if source.Mode()&os.ModeSymlink == 0 || output.Mode()&os.ModeSymlink == 0 {
+			return false, nil
+		}
+		sourceTarget, err := os.Readlink(sourcePath)
+		if err != nil {
+			return false, err
+		}
+		outputTarget, err := os.Readlink(outputPath)
+		return sourceTarget == outputTarget, err
+	}
+	if source.Size() != output.Size() {
+		return false, nil
+	}
+	sourceContent, err := ioutil.ReadFile(sourcePath)
+	if err != nil {
+		return false, err
+	}
+	outputContent, err := ioutil.ReadFile(outputPath)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(sourceContent, outputContent), nil
+}
+
+func (engine *Engine) copyStagedFile(sourcePath string, info os.FileInfo, outputPath string) error {
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(sourcePath)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, outputPath)
+	}
+	content, err := ioutil.ReadFile(sourcePath)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(outputPath, content, info.Mode()); err != nil {
+		return err
+	}
+	return os.Chmod(outputPath, info.Mode()) // the mode of WriteFile is reduced by the umask
+}
+
+// rebaseOutputPaths moves the output files the engine keeps track of across builds from the folder they were rendered to into the outputDir.
+func (engine *Engine) rebaseOutputPaths(fromDir string, toDir string) {
+	rebase := func(filePath string) string {
+		relativePath, err := filepath.Rel(fromDir, filePath)
+		if err != nil || strings.HasPrefix(relativePath, "..") {
+			return filePath
+		}
+		return path.Join(toDir, filepath.ToSlash(relativePath))
+	}
+
+	renderedSources := make(map[string][]string)
+	for filePath, sources := range engine.renderedSources {
+		renderedSources[rebase(filePath)] = sources
+	}
+	engine.renderedSources = renderedSources
+
+	sitemapURLs := make(map[string]sitemapURL)
+	for filePath, url := range engine.sitemapURLs {
+		sitemapURLs[rebase(filePath)] = url
+	}
+	engine.sitemapURLs = sitemapURLs
+
+	searchIndexEntries := make(map[string]searchIndexEntry)
+	for filePath, entry := range engine.searchIndexEntries {
+		searchIndexEntries[rebase(filePath)] = entry
+	}
+	engine.searchIndexEntries = searchIndexEntries
+
+	pageIndexing := make(map[string]pageIndexing)
+	for filePath, indexing := range engine.pageIndexing {
+		pageIndexing[rebase(filePath)] = indexing
+	}
+	engine.pageIndexing = pageIndexing
+
+	outputSources := make(map[string]string)
+	for filePath, templateName := range engine.outputSources {
+		outputSources[rebase(filePath)] = templateName
+	}
+	engine.outputSources = outputSources
+}
@@ -0,0 +1,49 @@
+package temingo
+
+import (
+	"errors"
+	"html"
+	"html/template"
+	"strconv"
+	"strings"
+)
+
+// summaryDivider separates the summary of content from the rest of it.
+const summaryDivider = "<!--more-->"
+
+// getSummary returns the summary of the html content and its reading time in minutes.
+// The summary is the html before '<!--more-->', or the text of the first summaryWords words. The reading time is based on the readingSpeed, at least one minute for content with any text.
+func (engine *Engine) getSummary(content string) (template.HTML, int) {
+	words := strings.Fields(getSearchIndexEntry("", []byte(content), nil).Content)
+	readingTime := (len(words) + engine.ReadingSpeed - 1) / engine.ReadingSpeed
+
+	if index := strings.Index(content, summaryDivider); index != -1 {
+		return template.HTML(strings.TrimSpace(content[:index])), readingTime
+	}
+	if len(words) > engine.SummaryWords {
+		words = words[:engine.SummaryWords]
+	}
+	return template.HTML(html.EscapeString(strings.Join(words, " "))), readingTime
+}
+
+// addSummary adds the 'Summary' and 'ReadingTime' of the html content to values, unless they already contain them.
+func (engine *Engine) addSummary(values map[string]interface{}, content string) {
+	summary, readingTime := engine.getSummary(content)
+	if _, ok := values["Summary"]; !ok {
+		values["Summary"] = summary
+	}
+	if _, ok := values["ReadingTime"]; !ok {
+		values["ReadingTime"] = readingTime
+	}
+}
+
+// validateSummaries checks the summaryWords and the readingSpeed are positive.
+func (engine *Engine) validateSummaries() error {
+	if engine.SummaryWords < 1 {
+		return errors.New("The number of summary words must be positive, but is " + strconv.Itoa(engine.SummaryWords))
+	}
+	if engine.ReadingSpeed < 1 {
+		return errors.New("The reading speed must be positive, but is " + strconv.Itoa(engine.ReadingSpeed))
+	}
+	return nil
+}
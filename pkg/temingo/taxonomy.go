@@ -0,0 +1,117 @@
+package temingo
+
+import (
+	"errors"
+	"path"
+	"sort"
+	"strings"
+)
+
+// collectTaxonomies groups the pages and items of the site by the terms of each taxonomy, f.e. the values of their 'tags'.
+// Each term has the keys 'Name' (as written in the first page declaring it), 'Slug', 'Path' (the folder of its listing page, or empty without taxonomy template), 'Count' and 'Pages'. The terms of a taxonomy are sorted by their slug.
+// Terms with the same slug, f.e. 'Go' and 'go', are the same term. The listing pages of the taxonomy templates are added to the site pages, with the 'noindex' of their template.
+func (engine *Engine) collectTaxonomies(taxonomyTemplates map[string]string, taxonomyNoIndex map[string]bool) error {
+	engine.taxonomies = make(map[string][]interface{})
+	for _, taxonomy := range engine.Taxonomies {
+		terms := make(map[string]map[string]interface{}) // by slug
+		for _, sitePage := range engine.sitePages {
+			page := sitePage.(map[string]interface{})
+			names, err := getTaxonomyTerms(page, taxonomy)
+			if err != nil {
+				return err
+			}
+			for _, name := range names {
+				slug, err := engine.urlize(name)
+				if err != nil {
+					return err
+				}
+				term, ok := terms[slug]
+				if !ok {
+					term = map[string]interface{}{"Name": name, "Slug": slug, "Path": "", "Pages": []interface{}{}}
+					if templateName, ok := taxonomyTemplates[taxonomy]; ok {
+						term["Path"] = "/" + path.Join(path.Dir(templateName), slug) + "/"
+					}
+					terms[slug] = term
+				}
+				term["Pages"] = append(term["Pages"].([]interface{}), page)
+			}
+		}
+
+		slugs := []string{}
+		for slug := range terms {
+			slugs = append(slugs, slug)
+		}
+		sort.Strings(slugs)
+		sorted := []interface{}{}
+		for _, slug := range slugs {
+			terms[slug]["Count"] = len(terms[slug]["Pages"].([]interface{}))
+			sorted = append(sorted, terms[slug])
+		}
+		engine.taxonomies[taxonomy] = sorted
+	}
+
+	for _, taxonomy := range engine.Taxonomies {
+		templateName, ok := taxonomyTemplates[taxonomy]
+		if !ok {
+			continue
+		}
+		fileName := engine.trimLanguage(strings.TrimSuffix(path.Base(templateName), engine.TemplateExtension))
+		for _, term := range engine.taxonomies[taxonomy] {
+			pagePath := path.Join(toString(term.(map[string]interface{})["Path"]), fileName)
+			engine.sitePages = append(engine.sitePages, map[string]interface{}{
+				"Path":     pagePath,
+				"Section":  getSection(pagePath),
+				"Kind":     "term",
+				"Template": templateName,
+				"Term":     term.(map[string]interface{})["Name"],
+				"NoIndex":  taxonomyNoIndex[taxonomy],
+			})
+		}
+	}
+	return nil
+}
+
+// getTaxonomyTerms returns the terms of the taxonomy the page declares, which is either a list or a single term.
+func getTaxonomyTerms(page map[string]interface{}, taxonomy string) ([]string, error) {
+	value, ok := page[taxonomy]
+	if !ok || value == nil {
+		return nil, nil
+	}
+	values, ok := value.([]interface{})
+	if !ok {
+		values = []interface{}{value}
+	}
+	names := []string{}
+	for _, value := range values {
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			return nil, errors.New("The '" + taxonomy + "' of '" + toString(page["Path"]) + "' must be a list of terms, but contains '" + toString(value) + "'.")
+		}
+		if name := strings.TrimSpace(toString(value)); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// getTaxonomy returns the taxonomy a template declares via 'taxonomy' in its front matter, and false if it doesn't.
+// Such a template is rendered once per term of the taxonomy instead of once, f.e. 'tags/index.html.template' with 'taxonomy: tags' results in 'tags/<term>/index.html' for each tag.
+func (engine *Engine) getTaxonomy(templateName string, frontMatter map[string]interface{}) (string, bool, error) {
+	value, ok := frontMatter["taxonomy"]
+	if !ok {
+		return "", false, nil
+	}
+	taxonomy := toString(value)
+	if _, ok := frontMatter["generate"]; ok {
+		return "", true, errors.New("The front matter of '" + templateName + "' can contain either 'generate' or 'taxonomy', but not both.")
+	}
+	if _, ok := frontMatter["paginate"]; ok {
+		return "", true, errors.New("The front matter of '" + templateName + "' can contain either 'paginate' or 'taxonomy', but not both.")
+	}
+	for _, configured := range engine.Taxonomies {
+		if configured == taxonomy {
+			return taxonomy, true, nil
+		}
+	}
+	return "", true, errors.New("The taxonomy '" + taxonomy + "' of '" + templateName + "' is not one of the configured taxonomies '" + strings.Join(engine.Taxonomies, "', '") + "', see '--taxonomies'.")
+}
@@ -0,0 +1,128 @@
+package temingo
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+// templateCacheDir is the folder in the cacheDir the outputs of templates with 'cache: true' in their front matter are cached in, one file per output file.
+var templateCacheDir = path.Join(cacheDir, "templates")
+
+// templateCache is what the cached outputs of a template depend on, besides the template itself, its partials and its source files.
+type templateCache struct {
+	keys      []string               // the declared 'cacheKeys', paths of values like 'team.members'
+	ownValues map[string]interface{} // the values which only apply to the template
+}
+
+// registerTemplateCache remembers the template with templateName as cached for the current build, if its front matter declares 'cache: true'.
+func (engine *Engine) registerTemplateCache(templateName string, frontMatter map[string]interface{}, ownValues map[string]interface{}) error {
+	value, ok := frontMatter["cache"]
+	if !ok {
+		return nil
+	}
+	enabled, ok := value.(bool)
+	if !ok {
+		return errors.New("The front matter 'cache' of '" + templateName + "' must be either true or false.")
+	}
+	if !enabled {
+		return nil
+	}
+	cache := &templateCache{ownValues: ownValues}
+	if value, ok := frontMatter["cacheKeys"]; ok {
+		keys, ok := value.([]interface{})
+		if !ok {
+			return errors.New("The front matter 'cacheKeys' of '" + templateName + "' must be a list of value paths.")
+		}
+		for _, key := range keys {
+			keyPath, ok := key.(string)
+			if !ok || keyPath == "" {
+				return errors.New("The front matter 'cacheKeys' of '" + templateName + "' must be a list of value paths.")
+			}
+			cache.keys = append(cache.keys, keyPath)
+		}
+	}
+	engine.lock.Lock()
+	engine.templateCaches[templateName] = cache
+	engine.lock.Unlock()
+	return nil
+}
+
+// getTemplateCacheKey returns the hash of everything the output of the job depends on, if its template is cached.
+// These are the template, all partials, the source files of the job, the values of the template and the values at its 'cacheKeys'.
+func (engine *Engine) getTemplateCacheKey(job renderJob, sources renderSources) (string, bool) {
+	engine.lock.Lock()
+	cache, ok := engine.templateCaches[job.templateName]
+	engine.lock.Unlock()
+	if !ok {
+		return "", false
+	}
+	values, _ := job.context["Values"].(map[string]interface{})
+	if engine.FlatContext {
+		values = job.context
+	}
+	dependencies := map[string]interface{}{}
+	for _, keyPath := range cache.keys {
+		value, _ := lookupValue(values, keyPath)
+		dependencies[keyPath] = value
+	}
+	partials := append([][]string{}, sources.partials...)
+	sort.Slice(partials, func(i, j int) bool { return partials[i][0] < partials[j][0] })
+	sourceContents := []string{}
+	for _, sourceFile := range job.sourceFiles {
+		content, err := ioutil.ReadFile(sourceFile)
+		if err != nil {
+			return "", false
+		}
+		sourceContents = append(sourceContents, string(content))
+	}
+	key, err := json.Marshal([]interface{}{engine.Version, engine.language, job.template, partials, sourceContents, cache.ownValues, dependencies})
+	if err != nil {
+		engine.logWarn("Could not cache the output of '" + job.templateName + "': " + err.Error())
+		return "", false
+	}
+	hash := sha256.Sum256(key)
+	return hex.EncodeToString(hash[:]), true
+}
+
+// getTemplateCacheFilePath returns the file in the templateCacheDir the output file at outputFilePath is cached in.
+// It's the same while rendering to the stagingDir, as the path relative to the outputDir is used.
+func (engine *Engine) getTemplateCacheFilePath(outputFilePath string) string {
+	if relativePath, err := filepath.Rel(engine.OutputDir, outputFilePath); err == nil {
+		outputFilePath = filepath.ToSlash(relativePath)
+	}
+	hash := sha256.Sum256([]byte(engine.language + "/" + outputFilePath))
+	return path.Join(templateCacheDir, hex.EncodeToString(hash[:]))
+}
+
+// readCachedOutput returns the cached output of the output file at outputFilePath, if it was cached with the same cacheKey.
+func (engine *Engine) readCachedOutput(outputFilePath string, cacheKey string) ([]byte, bool) {
+	content, err := ioutil.ReadFile(engine.getTemplateCacheFilePath(outputFilePath))
+	if err != nil {
+		return nil, false
+	}
+	separator := bytes.IndexByte(content, '\n')
+	if separator < 0 || string(content[:separator]) != cacheKey {
+		return nil, false
+	}
+	return content[separator+1:], true
+}
+
+// writeCachedOutput caches the output of the output file at outputFilePath together with its cacheKey. Failing to do so is only logged as warning, as it's rendered again next time.
+func (engine *Engine) writeCachedOutput(outputFilePath string, cacheKey string, output []byte) {
+	cacheFilePath := engine.getTemplateCacheFilePath(outputFilePath)
+	if err := os.MkdirAll(templateCacheDir, engine.dirMode); err != nil {
+		engine.logWarn("Could not cache the output of '" + outputFilePath + "': " + err.Error())
+		return
+	}
+	if err := ioutil.WriteFile(cacheFilePath, append([]byte(cacheKey+"\n"), output...), engine.fileMode); err != nil {
+		engine.logWarn("Could not cache the output of '" + outputFilePath + "': " + err.Error())
+	}
+}
@@ -0,0 +1,331 @@
+package temingo
+
+import (
+	"errors"
+	"html"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"path"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/Masterminds/sprig"
+	"github.com/PuerkitoBio/purell"
+	"github.com/imdario/mergo"
+)
+
+func (engine *Engine) getTemplates(fromPath string, extension string, additionalExclusions []string) ([][]string, error) {
+	var templates [][]string
+
+	dirContents, err := ioutil.ReadDir(fromPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range dirContents {
+		if !(entry.Name()[:1] == ".") || (!entry.IsDir() && strings.HasSuffix(entry.Name(), extension)) { // ignore hidden files/folders, except for templates like '.htaccess.template'
+			entryPath := path.Join(fromPath, entry.Name())
+			if fromPath == "." { // path.Join adds this to the filename directly ... which has to be prevented here
+				entryPath = entry.Name()
+			}
+			if !engine.isExcluded(entryPath, append(append([]string{}, engine.TemplateExclusions...), additionalExclusions...)) { // Make all paths absolute from working-directory
+				if entry.IsDir() {
+					subTemplates, err := engine.getTemplates(entryPath, extension, additionalExclusions)
+					if err != nil {
+						return nil, err
+					}
+					templates = append(templates, subTemplates...)
+				} else if strings.HasSuffix(entry.Name(), extension) {
+					if !rexp.MatchString(entryPath) {
+						return nil, errors.New("The path '" + entryPath + "' doesn't validate against the regular expression '" + pathValidator + "'.")
+					}
+					fileContent, err := ioutil.ReadFile(entryPath)
+					if err != nil {
+						return nil, err
+					}
+					templates = append(templates, []string{entryPath, string(fileContent)})
+				}
+			}
+		}
+	}
+
+	return templates, nil
+}
+
+// executableTemplate is implemented by both html/template and text/template, depending on the escaping mode of the output.
+type executableTemplate interface {
+	Execute(wr io.Writer, data interface{}) error
+	ExecuteTemplate(wr io.Writer, name string, data interface{}) error
+}
+
+// isHtmlOutput returns whether the output of the template with name is html, and therefore has to be escaped contextually by html/template.
+// All other outputs are rendered by text/template, so f.e. xml, json or txt files are not mangled with html escapes.
+// The 'engine' of the front matter of a template overrides it, see registerTemplateEngine.
+func (engine *Engine) isHtmlOutput(name string) bool {
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+	return engine.isHtmlOutputLocked(name)
+}
+
+// isHtmlOutputLocked is isHtmlOutput for callers which already hold the lock of the engine, f.e. while iterating over the renderedSources.
+func (engine *Engine) isHtmlOutputLocked(name string) bool {
+	if engine.isMarkdownFile(name) { // markdown is always converted to html
+		return true
+	}
+	if isHtml, ok := engine.templateEngines[name]; ok {
+		return isHtml
+	}
+	outputName := engine.trimLanguage(strings.TrimSuffix(strings.TrimSuffix(name, engine.SingleTemplateExtension), engine.TemplateExtension))
+	for _, extension := range engine.HtmlExtensions {
+		if strings.HasSuffix(outputName, extension) {
+			return true
+		}
+	}
+	return false
+}
+
+// registerTemplateEngine remembers for the current build whether the template with templateName is rendered by html/template or text/template, if its front matter declares 'engine: html' or 'engine: text'.
+func (engine *Engine) registerTemplateEngine(templateName string, frontMatter map[string]interface{}) error {
+	value, ok := frontMatter["engine"]
+	if !ok {
+		return nil
+	}
+	var isHtml bool
+	switch value {
+	case "html":
+		isHtml = true
+	case "text":
+		isHtml = false
+	default:
+		return errors.New("The front matter 'engine' of '" + templateName + "' must be either 'html' or 'text'.")
+	}
+	engine.lock.Lock()
+	engine.templateEngines[templateName] = isHtml
+	engine.lock.Unlock()
+	return nil
+}
+
+func (engine *Engine) parseTemplateFiles(name string, baseTemplate string, partialTemplates [][]string, values map[string]interface{}) (executableTemplate, error) {
+	var tpl executableTemplate
+	funcMap := engine.getFuncMap(name, &tpl, values)
+
+	if engine.isHtmlOutput(name) {
+		htmlTpl := template.New(name).Funcs(funcMap)
+		for index := range partialTemplates {
+			_, err := htmlTpl.New(partialTemplates[index][0]).Parse(partialTemplates[index][1])
+			if err != nil {
+				return nil, err
+			}
+		}
+		_, err := htmlTpl.Parse(baseTemplate)
+		if err != nil {
+			return nil, err
+		}
+		layout, err := getExtendedLayout(htmlTpl.Tree)
+		if err != nil {
+			return nil, err
+		}
+		if layout != "" {
+			if htmlTpl.Lookup(layout) == nil {
+				return nil, errors.New("'" + name + "' extends the layout '" + layout + "', which is no partial or defined template")
+			}
+			engine.logDebug("'" + name + "' extends the layout '" + layout + "'.")
+			// the body of the template is replaced with the layout, the blocks it overrides remain as separate templates
+			_, err = htmlTpl.Parse(getLayoutInvocation(layout))
+			if err != nil {
+				return nil, err
+			}
+		}
+		tpl = htmlTpl
+	} else {
+		engine.logDebug("Using text mode for '" + name + "', as its output is not html.")
+		textTpl := texttemplate.New(name).Funcs(funcMap)
+		for index := range partialTemplates {
+			_, err := textTpl.New(partialTemplates[index][0]).Parse(partialTemplates[index][1])
+			if err != nil {
+				return nil, err
+			}
+		}
+		_, err := textTpl.Parse(baseTemplate)
+		if err != nil {
+			return nil, err
+		}
+		layout, err := getExtendedLayout(textTpl.Tree)
+		if err != nil {
+			return nil, err
+		}
+		if layout != "" {
+			if textTpl.Lookup(layout) == nil {
+				return nil, errors.New("'" + name + "' extends the layout '" + layout + "', which is no partial or defined template")
+			}
+			engine.logDebug("'" + name + "' extends the layout '" + layout + "'.")
+			// the body of the template is replaced with the layout, the blocks it overrides remain as separate templates
+			_, err = textTpl.Parse(getLayoutInvocation(layout))
+			if err != nil {
+				return nil, err
+			}
+		}
+		tpl = textTpl
+	}
+	tpl = engine.newLimitedTemplate(name, tpl) // includes are executed via tpl as well, so they are limited too
+	return tpl, nil
+}
+
+// getFuncMap returns the functions available in the template with name, which are the ones of sprig, the ones of temingo and the execFunctions.
+func (engine *Engine) getFuncMap(name string, tpl *executableTemplate, values map[string]interface{}) map[string]interface{} {
+	funcMap := engine.getBuiltinFuncMap(name, tpl, values)
+	for k, v := range engine.getExecFunctions() {
+		funcMap[k] = v
+	}
+	return funcMap
+}
+
+// getBuiltinFuncMap returns the functions of sprig and the ones of temingo available in the template with name.
+func (engine *Engine) getBuiltinFuncMap(name string, tpl *executableTemplate, values map[string]interface{}) map[string]interface{} {
+	funcMap := sprig.GenericFuncMap()
+	for k, v := range engine.getTemingoFuncMap(name, tpl, values, funcMap) {
+		funcMap[k] = v
+	}
+	return funcMap
+}
+
+// getTemingoFuncMap returns the functions of temingo available in the template with name, some of which replace the ones of sprig in sprigFuncMap.
+// 'include' executes the partials of tpl, which is set once the template is parsed. 'getValue' and 'hasValue' look up values, which are the ones of the rendered page.
+func (engine *Engine) getTemingoFuncMap(name string, tpl *executableTemplate, values map[string]interface{}, sprigFuncMap map[string]interface{}) map[string]interface{} {
+	includeDepth, recursiveInclude := 0, "" // each template is executed by a single goroutine, so they don't need to be guarded
+
+	return map[string]interface{}{
+		"addPercentage": func(a string, b string) (string, error) {
+			aInt, err := strconv.Atoi(a[:len(a)-1])
+			if err != nil {
+				return "", err
+			}
+			bInt, err := strconv.Atoi(b[:len(b)-1])
+			if err != nil {
+				return "", err
+			}
+			cInt := aInt + bInt
+			return strconv.Itoa(cInt) + "%", nil
+		},
+		"extends": extendsOutsideTopLevel,
+		"include": func(name string, data interface{}) (string, error) {
+			if includeDepth >= engine.MaxIncludeDepth { // a partial including itself would otherwise recurse until the stack overflows
+				recursiveInclude = name
+				return "", nil // unwound until the outermost include, so the error isn't wrapped once per level
+			}
+			if engine.isProfilingTemplates() {
+				defer engine.recordProfile("include", name, time.Now())
+			}
+			includeDepth++
+			var buf strings.Builder
+			err := (*tpl).ExecuteTemplate(&buf, name, data)
+			includeDepth--
+			if includeDepth == 0 && recursiveInclude != "" {
+				err = errors.New("include: exceeded the maximum depth of " + strconv.Itoa(engine.MaxIncludeDepth) + " nested includes, does '" + recursiveInclude + "' include itself?")
+				recursiveInclude = ""
+			}
+			if err != nil {
+				return "", err
+			}
+			result := buf.String()
+			return result, nil
+		},
+		"safeHTML": func(s string) template.HTML {
+			return template.HTML(s)
+		},
+		"safeCSS": func(s string) template.CSS {
+			return template.CSS(s)
+		},
+		"xmlEscape": html.EscapeString,
+		"list": func(listPaths ...string) (map[string]interface{}, error) {
+			listObjects := make(map[string]interface{})
+			if len(listPaths) == 0 { // If no path is provided
+				listPaths = append(listPaths, path.Dir(name)) // Add the default path (folder containing the template)
+			}
+			if engine.isProfilingTemplates() {
+				defer engine.recordProfile("list", strings.Join(listPaths, ", "), time.Now())
+			}
+			for _, listPath := range listPaths {
+				loadedObjects, err := engine.loadListObjects(listPath, false)
+				if err != nil {
+					return nil, errors.New("list: could not load the list objects of '" + listPath + "': " + err.Error())
+				}
+				mergo.Merge(&listObjects, loadedObjects)
+				engine.lock.Lock()
+				engine.listListObjects[listPath] = listObjects
+				engine.lock.Unlock()
+			}
+			return listObjects, nil
+		},
+		"listTree": func(args ...string) (map[string]interface{}, error) {
+			treePath, sortBy := path.Dir(name), "" // defaults to the folder containing the template
+			if len(args) > 0 {
+				treePath = args[0]
+			}
+			if len(args) > 1 {
+				sortBy = strings.Join(args[1:], ",")
+			}
+			tree, err := engine.getListTree(treePath, sortBy)
+			if err != nil {
+				return nil, errors.New("listTree: could not load the list objects of '" + treePath + "': " + err.Error())
+			}
+			return tree, nil
+		},
+		"asset":           engine.getAsset,
+		"assetBundle":     engine.getAssetBundleTag,
+		"imageResize":     engine.imageResize,
+		"imageCrop":       engine.imageCrop,
+		"imageConvert":    engine.imageConvert,
+		"urlize":          engine.urlize,
+		"required":        assertRequired,
+		"getValue":        getValue(values),
+		"hasValue":        hasValue(values),
+		"warnf":           engine.assertWarnf(name),
+		"pages":           engine.queryPages,
+		"related":         engine.getRelated,
+		"isActive":        isActiveMenuURL,
+		"hasActiveChild":  hasActiveMenuChild,
+		"where":           queryWhere,
+		"sortBy":          querySortBy,
+		"first":           queryFirst(sprigFuncMap["first"].(func(interface{}) interface{})),
+		"limit":           queryLimit,
+		"reverse":         queryReverse,
+		"slice":           querySlice(sprigFuncMap["slice"].(func(interface{}, ...interface{}) interface{})),
+		"count":           queryCount,
+		"sumBy":           querySumBy,
+		"minBy":           queryMinBy,
+		"maxBy":           queryMaxBy,
+		"uniqBy":          queryUniqBy,
+		"sortedKeys":      querySortedKeys,
+		"sortedPairs":     querySortedPairs,
+		"webmentionLinks": engine.webmentionLinks,
+		"webmentions":     engine.getWebmentions,
+		"getJSON":         engine.getRemoteJSON,
+		"getYAML":         engine.getRemoteYAML,
+		"T":               engine.translate,
+		"langPath":        engine.getLanguagePath,
+		"params":          getPartialParams,
+		"env":             engine.getEnv,
+		"expandenv":       engine.expandEnv,
+		"capitalize": func(oldContent string) (string, error) {
+			if err := engine.useDeprecated("capitalize"); err != nil {
+				return "", err
+			}
+			newContent := strings.Title(oldContent)
+			engine.logDebug("Capitalized '" + oldContent + "' to '" + newContent + "'.")
+			return newContent, nil
+		},
+	}
+}
+
+func (engine *Engine) urlize(oldContent string) (string, error) {
+	newContent, err := purell.NormalizeURLString(strings.ReplaceAll(oldContent, " ", "_"), purell.FlagsSafe)
+	if err != nil {
+		return "", errors.New("Could not urlize '" + oldContent + "': " + err.Error())
+	}
+	newContent = strings.ToLower(newContent) // Also convert everything to lowercase. Arguable.
+	engine.logDebug("Urlized '" + oldContent + "' to '" + newContent + "'.")
+	return newContent, nil
+}
@@ -0,0 +1,87 @@
+package temingo
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+)
+
+// templateValuesFileSuffix is appended to the file name of a template or markdown file for the values file next to it, f.e. 'about.html.template.values.yaml'.
+// The name without any extensions works as well, f.e. 'about.values.yaml'.
+const templateValuesFileSuffix = ".values.yaml"
+
+// getPageName returns the file name of the template or markdown file at templateName without any extensions, f.e. 'about' for 'about.html.template'.
+func getPageName(templateName string) string {
+	return strings.SplitN(path.Base(templateName), ".", 2)[0]
+}
+
+// isTemplateValuesFile returns whether the file at filePath is the values file of a template or markdown file.
+func (engine *Engine) isTemplateValuesFile(filePath string) bool {
+	return len(engine.getValuesFileTemplates(filePath)) > 0
+}
+
+// getTemplateExtensions returns the extensions of the files which can have a values file: templates, single-view templates and markdown files, if markdown content is enabled.
+func (engine *Engine) getTemplateExtensions() []string {
+	if engine.MarkdownExtension == "" {
+		return []string{engine.TemplateExtension, engine.SingleTemplateExtension}
+	}
+	return []string{engine.TemplateExtension, engine.SingleTemplateExtension, engine.MarkdownExtension}
+}
+
+// getValuesFileTemplates returns the templates and markdown files next to the values file at filePath which use it.
+// A '<template>.values.yaml' belongs to its template, a '<name>.values.yaml' to all templates and markdown files named '<name>' next to it.
+func (engine *Engine) getValuesFileTemplates(filePath string) []string {
+	if !strings.HasSuffix(filePath, templateValuesFileSuffix) {
+		return nil
+	}
+	templateName := strings.TrimSuffix(filePath, templateValuesFileSuffix)
+	for _, extension := range engine.getTemplateExtensions() {
+		if strings.HasSuffix(templateName, extension) {
+			return []string{templateName}
+		}
+	}
+	if strings.Contains(path.Base(templateName), ".") {
+		return nil
+	}
+	siblings, _ := ioutil.ReadDir(path.Dir(filePath))
+	templates := []string{}
+	for _, sibling := range siblings {
+		if sibling.IsDir() || getPageName(sibling.Name()) != path.Base(templateName) {
+			continue
+		}
+		for _, extension := range engine.getTemplateExtensions() {
+			if strings.HasSuffix(sibling.Name(), extension) {
+				templates = append(templates, path.Join(path.Dir(filePath), sibling.Name()))
+				break
+			}
+		}
+	}
+	return templates
+}
+
+// getTemplateValues returns the values which only apply to the outputs of the template or markdown file with templateName.
+// They are the ones of the '<name>.values.yaml' file next to it, overridden by the ones of the '<template>.values.yaml' file and by the 'values' of its front matter.
+func (engine *Engine) getTemplateValues(templateName string, frontMatter map[string]interface{}) (map[string]interface{}, error) {
+	templateValues := make(map[string]interface{})
+	for _, valuesFilePath := range []string{path.Join(path.Dir(templateName), getPageName(templateName)+templateValuesFileSuffix), templateName + templateValuesFileSuffix} {
+		if _, err := os.Stat(valuesFilePath); err != nil {
+			continue
+		}
+		engine.logDebug("Loading template values from '" + valuesFilePath + "' for '" + templateName + "'.")
+		values, err := engine.loadValuesFile(valuesFilePath)
+		if err != nil {
+			return nil, err
+		}
+		templateValues = mergeValues(templateValues, values)
+	}
+	if value, ok := frontMatter["values"]; ok {
+		values, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, errors.New("The front matter 'values' of '" + templateName + "' must be a map.")
+		}
+		templateValues = mergeValues(templateValues, values)
+	}
+	return templateValues, nil
+}
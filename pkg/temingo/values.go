@@ -0,0 +1,125 @@
+package temingo
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+func (engine *Engine) getMappedValues() (map[string]interface{}, error) {
+	var mappedValues map[string]interface{}
+	for _, v := range engine.ValuesFilePaths {
+		tempMappedValues, err := engine.loadValuesFile(v)
+		if err != nil {
+			return nil, err
+		}
+
+		err = engine.mergeValuesLayer(&mappedValues, tempMappedValues)
+		if err != nil {
+			return nil, err
+		}
+
+		environmentValuesFilePath := engine.getEnvironmentValuesFilePath(v) // f.e. 'values.production.yaml' overrides 'values.yaml' for 'production'
+		if _, err := os.Stat(environmentValuesFilePath); err == nil {
+			environmentValues, err := engine.loadValuesFile(environmentValuesFilePath)
+			if err != nil {
+				return nil, err
+			}
+			err = engine.mergeValuesLayer(&mappedValues, environmentValues)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if engine.language != "" { // f.e. 'values.de.yaml' overrides 'values.yaml' for 'de'
+			languageValuesFilePath := engine.getLanguageValuesFilePath(v)
+			if _, err := os.Stat(languageValuesFilePath); err == nil {
+				languageValues, err := engine.loadValuesFile(languageValuesFilePath)
+				if err != nil {
+					return nil, err
+				}
+				err = engine.mergeValuesLayer(&mappedValues, languageValues)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	err := engine.mergeValuesLayer(&mappedValues, copyValues(engine.Values)) // programmatically passed values override the values files
+	if err != nil {
+		return nil, err
+	}
+	return mappedValues, nil
+}
+
+// loadValuesFile reads the values file at filePath, with the environment variables it references expanded.
+func (engine *Engine) loadValuesFile(filePath string) (map[string]interface{}, error) {
+	values, err := loadYaml(filePath)
+	if err != nil {
+		return nil, err
+	}
+	expanded, err := engine.expandValuesEnv(filePath, values)
+	if err != nil || expanded == nil {
+		return nil, err
+	}
+	return expanded.(map[string]interface{}), nil
+}
+
+func loadYaml(filePath string) (map[string]interface{}, error) {
+	var mappedObject map[string]interface{}
+	values, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	err = yaml.Unmarshal([]byte(values), &mappedObject) // store yaml into map
+	if err != nil {
+		return nil, errors.New("Could not parse '" + filePath + "': " + err.Error())
+	}
+
+	// valuesYaml, err := yaml.Marshal(mappedValues) // convert map to yaml/string
+	return mappedObject, nil
+}
+
+// loadListObjects returns the items of listPath by their folder, see getItemIndexPaths. With nested, each item additionally contains its 'Depth' and 'Parents', see getItemAncestry.
+func (engine *Engine) loadListObjects(listPath string, nested bool) (map[string]interface{}, error) {
+	engine.logDebug("*** Loading list objects from '" + listPath + "' ... ***")
+	itemIndexPaths, err := engine.getItemIndexPaths(listPath, nested)
+	if err != nil {
+		return nil, err
+	}
+	mappedObjects := make(map[string]interface{})
+	for elementPath, indexPath := range itemIndexPaths { // f.e. list/element1 with list/element1/index.yaml
+		if !rexp.MatchString(indexPath) { // if path is not good for urls
+			return nil, errors.New("The path '" + indexPath + "' for the list object must validate against the regular expression '" + pathValidator + "'.")
+		}
+		sectionValues, err := engine.getSectionValues(elementPath)
+		if err != nil {
+			return nil, err
+		}
+		itemValues, err := engine.loadItemIndexFile(indexPath)
+		if err != nil {
+			return nil, err
+		}
+		if !engine.isPublished(indexPath, itemValues) {
+			continue
+		}
+		tempMappedObject := mergeValues(sectionValues, itemValues) // f.e. list/_index.yaml overridden by list/element1/index.yaml
+		itemPath, err := engine.getItemOutputPath(indexPath, toString(tempMappedObject["permalink"]), tempMappedObject, elementPath)
+		if err != nil {
+			return nil, err
+		}
+		tempMappedObject["Path"] = "/" + itemPath // will become /[.../]list/element1 (or actually /[.../]list/element1/index.html)
+		if nested {
+			tempMappedObject["Depth"], tempMappedObject["Parents"], err = engine.getItemAncestry(listPath, elementPath)
+			if err != nil {
+				return nil, err
+			}
+		}
+		mappedObjects[elementPath] = tempMappedObject
+		engine.logDebug("Loaded object from '" + indexPath + "' ...")
+	}
+
+	return mappedObjects, nil
+}
@@ -0,0 +1,111 @@
+package temingo
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// logValuesDiff logs how the merged values differ from the ones of the previous build, so the effect of an edit of a values file is visible before rerendering.
+func (engine *Engine) logValuesDiff() {
+	if engine.previousValues == nil { // no previous build to compare with
+		return
+	}
+	values, err := engine.getMappedValues()
+	if err != nil { // reported by the rebuild
+		return
+	}
+
+	changes := engine.diffValues("", engine.previousValues, values)
+	if len(changes) == 0 {
+		engine.logInfo("*** The merged values did not change ***")
+		return
+	}
+	engine.logInfo("*** The merged values changed: ***\n" + strings.Join(changes, "\n"))
+}
+
+// diffValues returns the differences between two values loaded from yaml, one line per added ('+'), removed ('-') or changed ('~') value.
+// Each line contains the path of the value, f.e. 'team[1].name'. Maps are compared by key and lists by index.
+// The values of redacted keys are not shown, only that they changed.
+func (engine *Engine) diffValues(keyPath string, previous interface{}, current interface{}) []string {
+	previousMap, previousIsMap := previous.(map[string]interface{})
+	currentMap, currentIsMap := current.(map[string]interface{})
+	if previousIsMap && currentIsMap {
+		keys := []string{}
+		for key := range previousMap {
+			keys = append(keys, key)
+		}
+		for key := range currentMap {
+			if _, ok := previousMap[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		changes := []string{}
+		for _, key := range keys {
+			childPath := key
+			if keyPath != "" {
+				childPath = keyPath + "." + key
+			}
+			previousValue, previousOk := previousMap[key]
+			currentValue, currentOk := currentMap[key]
+			switch {
+			case !previousOk:
+				changes = append(changes, "+ "+childPath+": "+engine.formatLoggedValue(key, currentValue))
+			case !currentOk:
+				changes = append(changes, "- "+childPath+": "+engine.formatLoggedValue(key, previousValue))
+			case engine.isRedactedKey(key):
+				if !reflect.DeepEqual(previousValue, currentValue) {
+					changes = append(changes, "~ "+childPath+": "+redactedValue)
+				}
+			default:
+				changes = append(changes, engine.diffValues(childPath, previousValue, currentValue)...)
+			}
+		}
+		return changes
+	}
+
+	previousList, previousIsList := previous.([]interface{})
+	currentList, currentIsList := current.([]interface{})
+	if previousIsList && currentIsList {
+		changes := []string{}
+		for i := 0; i < len(previousList) || i < len(currentList); i++ {
+			childPath := keyPath + "[" + strconv.Itoa(i) + "]"
+			switch {
+			case i >= len(previousList):
+				changes = append(changes, "+ "+childPath+": "+formatValue(engine.redactValues(currentList[i])))
+			case i >= len(currentList):
+				changes = append(changes, "- "+childPath+": "+formatValue(engine.redactValues(previousList[i])))
+			default:
+				changes = append(changes, engine.diffValues(childPath, previousList[i], currentList[i])...)
+			}
+		}
+		return changes
+	}
+
+	if reflect.DeepEqual(previous, current) {
+		return nil
+	}
+	return []string{"~ " + keyPath + ": " + formatValue(engine.redactValues(previous)) + " -> " + formatValue(engine.redactValues(current))}
+}
+
+// formatLoggedValue returns the single-line representation of the value of key, without the values of redacted keys, see formatValue.
+func (engine *Engine) formatLoggedValue(key string, value interface{}) string {
+	if engine.isRedactedKey(key) {
+		return redactedValue
+	}
+	return formatValue(engine.redactValues(value))
+}
+
+// formatValue returns a single-line representation of a value, where strings are quoted so f.e. '"1"' and '1' can be told apart.
+func formatValue(value interface{}) string {
+	formatted, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(formatted)
+}
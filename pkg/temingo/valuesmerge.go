@@ -0,0 +1,103 @@
+package temingo
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/imdario/mergo"
+)
+
+// ValuesMergeStrategies are the strategies values of the values files can be merged with, see the valuesMerge option.
+var ValuesMergeStrategies = []string{"override", "append", "replace"}
+
+// validateValuesMerge checks the strategies of the valuesMerge option.
+func (engine *Engine) validateValuesMerge() error {
+	for keyPath, strategy := range engine.ValuesMerge {
+		valid := false
+		for _, validStrategy := range ValuesMergeStrategies {
+			valid = valid || strategy == validStrategy
+		}
+		if !valid {
+			return errors.New("The merge strategy of the value '" + keyPath + "' must be either 'override', 'append' or 'replace', but is '" + strategy + "'")
+		}
+		if keyPath == "" || strings.HasPrefix(keyPath, ".") || strings.HasSuffix(keyPath, ".") || strings.Contains(keyPath, "..") {
+			return errors.New("The value '" + keyPath + "' of the merge strategy '" + strategy + "' must be a dotted key path like 'nav.items'")
+		}
+	}
+	return nil
+}
+
+// mergeValuesLayer merges the values of layer, f.e. the ones of the next values file, into merged.
+// By default, maps are merged deeply and all other values, including lists, are overridden. The valuesMerge option sets other strategies per key path:
+// 'append' appends the list of layer to the merged one, 'replace' replaces the map of layer as a whole instead of merging it.
+func (engine *Engine) mergeValuesLayer(merged *map[string]interface{}, layer map[string]interface{}) error {
+	keyPaths := []string{}
+	for keyPath, strategy := range engine.ValuesMerge {
+		if strategy != "override" {
+			keyPaths = append(keyPaths, keyPath)
+		}
+	}
+	sort.Strings(keyPaths) // parents before their children, so the merged values of children are set within the ones of their parents
+
+	results := make(map[string]interface{})
+	for _, keyPath := range keyPaths {
+		layerValue, ok := getKeyPath(layer, keyPath)
+		if !ok {
+			continue
+		}
+		switch engine.ValuesMerge[keyPath] {
+		case "append":
+			mergedValue, _ := getKeyPath(*merged, keyPath)
+			mergedList, mergedIsList := mergedValue.([]interface{})
+			layerList, layerIsList := layerValue.([]interface{})
+			if mergedIsList && layerIsList {
+				results[keyPath] = append(append([]interface{}{}, mergedList...), copyValue(layerList).([]interface{})...)
+				continue
+			}
+			results[keyPath] = copyValue(layerValue) // nothing to append to yet
+		case "replace":
+			results[keyPath] = copyValue(layerValue)
+		}
+	}
+
+	if err := mergo.Merge(merged, layer, mergo.WithOverride); err != nil {
+		return err
+	}
+	for _, keyPath := range keyPaths {
+		if result, ok := results[keyPath]; ok {
+			setKeyPath(*merged, keyPath, result)
+		}
+	}
+	return nil
+}
+
+// getKeyPath returns the value at the dotted keyPath of values, f.e. 'nav.items'.
+func getKeyPath(values map[string]interface{}, keyPath string) (interface{}, bool) {
+	var value interface{} = values
+	for _, key := range strings.Split(keyPath, ".") {
+		parent, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		value, ok = parent[key]
+		if !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// setKeyPath sets the value at the dotted keyPath of values, whose parents have to exist already.
+func setKeyPath(values map[string]interface{}, keyPath string, value interface{}) {
+	keys := strings.Split(keyPath, ".")
+	parent := values
+	for _, key := range keys[:len(keys)-1] {
+		child, ok := parent[key].(map[string]interface{})
+		if !ok {
+			return
+		}
+		parent = child
+	}
+	parent[keys[len(keys)-1]] = value
+}
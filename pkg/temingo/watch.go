@@ -0,0 +1,159 @@
+package temingo
+
+import (
+	"errors"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/radovskyb/watcher"
+)
+
+// errBuildCanceled is returned by the jobs of a build which was canceled while watching, as files changed in the meantime.
+var errBuildCanceled = errors.New("The build was canceled")
+
+// logBuildErrors logs the errors of a build while watching, so a broken template doesn't end the watching process.
+// A canceled build counts as failed, so the next build is a full rebuild.
+func (engine *Engine) logBuildErrors(err error) {
+	engine.buildFailed = err != nil
+	if engine.isBuildCanceled() {
+		engine.logInfo("*** Canceled the build, as files changed in the meantime ***")
+		engine.logWatchedBuild(err)
+		return
+	}
+	if err != nil {
+		engine.logError("*** Build failed: ***\n" + err.Error())
+	}
+	engine.logWatchedBuild(err)
+	engine.notifyBuild(err)
+}
+
+// cancelBuild makes the remaining jobs of the current build fail with errBuildCanceled.
+func (engine *Engine) cancelBuild() {
+	atomic.StoreInt32(&engine.buildCanceled, 1)
+}
+
+func (engine *Engine) isBuildCanceled() bool {
+	return atomic.LoadInt32(&engine.buildCanceled) == 1
+}
+
+func (engine *Engine) watchAll() error {
+	engine.logInfo("*** Starting to watch for file changes ... ***")
+
+	w := engine.newFileWatcher()
+	defer w.close()
+
+	if err := w.addRecursive(engine.InputDir); err != nil { // watch the input-files-directory recursively
+		return err
+	}
+	if err := w.addRecursive(engine.PartialsDir); err != nil { // watch the partials-files-directory recursively
+		return err
+	}
+	if _, err := os.Stat(engine.DataDir); err == nil && !engine.isInside(engine.DataDir, engine.InputDir) { // watch the data-directory, if it's not already watched as part of the input-directory
+		if err := w.addRecursive(engine.DataDir); err != nil {
+			return err
+		}
+	}
+	for _, valuesFile := range engine.ValuesFilePaths { // for each valuesfilepath
+		if err := w.add(valuesFile); err != nil { // watch the values-file
+			return err
+		}
+		if _, err := os.Stat(engine.getEnvironmentValuesFilePath(valuesFile)); err == nil { // and its overlay of the environment
+			if err := w.add(engine.getEnvironmentValuesFilePath(valuesFile)); err != nil {
+				return err
+			}
+		}
+	}
+	if engine.ValuesSchema != "" {
+		if err := w.add(engine.ValuesSchema); err != nil {
+			return err
+		}
+	}
+
+	if engine.isLogged(levelDebug) {
+		engine.logDebug("Watched paths/files:")
+		// Print a list of all of the files and folders currently being watched and their paths.
+		for _, watchedPath := range w.watchedPaths() {
+			engine.logDebug(watchedPath)
+		}
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Second) // checks whether a deleted partials-directory was recreated
+		defer ticker.Stop()
+		var (
+			pending  []watcher.Event // events which aren't rebuilt yet
+			debounce <-chan time.Time
+			building chan error // receives the result of the running build, nil while there is none
+		)
+		startBuild := func(trigger []string, build func() error) {
+			atomic.StoreInt32(&engine.buildCanceled, 0)
+			engine.buildTrigger = trigger
+			building = make(chan error, 1)
+			go func() {
+				building <- engine.buildWithHooks(build)
+			}()
+		}
+		rebuildPending := func() {
+			events := pending
+			pending = nil
+			trigger := []string{}
+			triggered := make(map[string]bool) // files are often changed several times in a row
+			for _, event := range events {
+				changedPath, ok := getRelativePath(event.Path)
+				if !ok {
+					changedPath = event.Path
+				}
+				if !triggered[changedPath] {
+					triggered[changedPath] = true
+					trigger = append(trigger, changedPath)
+				}
+			}
+			startBuild(trigger, func() error {
+				if engine.rewatchPartials(w) {
+					engine.logInfo("*** Rebuilding because the partials-directory was recreated ***")
+					return engine.rebuildOutput()
+				}
+				return engine.rebuildChanged(events)
+			})
+		}
+		for { // while true
+			select {
+			case event := <-w.events(): // receive events
+				engine.logWatchedChange(event)
+				pending = append(pending, event)
+				debounce = time.After(engine.WatchDebounce) // events are collected until there were none for the debounce window, so they result in a single rebuild
+				if building != nil {
+					engine.cancelBuild() // its outputs are outdated anyway
+				}
+			case <-debounce:
+				debounce = nil
+				if building == nil {
+					rebuildPending()
+				}
+			case err := <-building:
+				building = nil
+				engine.logBuildErrors(err)
+				if len(pending) > 0 && debounce == nil { // the changes which canceled the build
+					rebuildPending()
+				}
+			case <-ticker.C:
+				if building == nil && debounce == nil && engine.rewatchPartials(w) {
+					engine.logInfo("*** Rebuilding because the partials-directory was recreated ***")
+					startBuild([]string{engine.PartialsDir}, engine.rebuildOutput)
+				}
+			case err := <-w.errors(): // receive errors
+				if err == watcher.ErrWatchedFileDeleted { // f.e. the partials-directory, which is watched again once recreated
+					engine.logInfo("A watched file or folder was deleted.")
+					continue
+				}
+				engine.logError("Error while watching: " + err.Error())
+			case <-w.closed():
+				return
+			}
+		}
+	}()
+
+	// Start the watching process - when polling, it'll check for changes every watchInterval.
+	return w.start(engine.WatchInterval)
+}
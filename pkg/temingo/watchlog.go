@@ -0,0 +1,89 @@
+package temingo
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/radovskyb/watcher"
+)
+
+// watchChangeEntry is appended as json line to the watchLog for each change while watching.
+type watchChangeEntry struct {
+	Type string `json:"type"` // 'change'
+	Time string `json:"time"` // when the change was noticed, in RFC 3339 format
+	Path string `json:"path"` // the changed file or folder
+	Op   string `json:"op"`   // how it changed, f.e. 'WRITE', 'CREATE' or 'REMOVE'
+}
+
+// watchBuildEntry is appended as json line to the watchLog for each build while watching.
+type watchBuildEntry struct {
+	Type       string   `json:"type"`              // 'build'
+	Time       string   `json:"time"`              // when the build ended, in RFC 3339 format
+	Status     string   `json:"status"`            // 'success', 'failure' or 'canceled'
+	Trigger    []string `json:"trigger,omitempty"` // the changed paths the build was triggered by, none for the initial build
+	DurationMs int64    `json:"durationMs"`        // duration of the build
+	Rendered   int      `json:"rendered"`          // number of rendered outputs
+	Copied     int      `json:"copied"`            // number of copied files
+	Skipped    int      `json:"skipped"`           // number of ignored files and unpublished content
+	Pages      []string `json:"pages,omitempty"`   // the rendered output files, sorted
+	Errors     []string `json:"errors,omitempty"`  // the errors of a failed build
+}
+
+// logWatchedChange appends the change to the watchLog, if set.
+func (engine *Engine) logWatchedChange(event watcher.Event) {
+	if engine.WatchLog == "" {
+		return
+	}
+	changedPath, ok := getRelativePath(event.Path)
+	if !ok {
+		changedPath = event.Path
+	}
+	engine.writeWatchLog(watchChangeEntry{Type: "change", Time: time.Now().Format(time.RFC3339), Path: changedPath, Op: event.Op.String()})
+}
+
+// logWatchedBuild appends the outcome of the build that ended with err to the watchLog, if set.
+func (engine *Engine) logWatchedBuild(err error) {
+	if engine.WatchLog == "" {
+		return
+	}
+	entry := watchBuildEntry{Type: "build", Time: time.Now().Format(time.RFC3339), Status: "success", Trigger: engine.buildTrigger}
+	if summary := engine.summary; summary != nil {
+		entry.Rendered, entry.Copied, entry.Skipped = summary.rendered, summary.copied, len(summary.skipped)
+		entry.DurationMs = time.Since(summary.started).Milliseconds()
+		entry.Pages = append(entry.Pages, summary.pages...)
+		sort.Strings(entry.Pages)
+	}
+	if engine.isBuildCanceled() {
+		entry.Status = "canceled"
+	} else if err != nil {
+		entry.Status = "failure"
+		if errs, ok := err.(BuildErrors); ok {
+			for _, buildErr := range errs {
+				entry.Errors = append(entry.Errors, buildErr.Error())
+			}
+		} else {
+			entry.Errors = []string{err.Error()}
+		}
+	}
+	engine.writeWatchLog(entry)
+}
+
+// writeWatchLog appends entry as json line to the watchLog. Failing to do so is only logged as warning, so it doesn't end the watching process.
+func (engine *Engine) writeWatchLog(entry interface{}) {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		engine.logWarn("Could not write to the watch log: " + err.Error())
+		return
+	}
+	file, err := os.OpenFile(engine.WatchLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, engine.fileMode)
+	if err != nil {
+		engine.logWarn("Could not write to the watch log: " + err.Error())
+		return
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		engine.logWarn("Could not write to the watch log: " + err.Error())
+	}
+}
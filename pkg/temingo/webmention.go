@@ -0,0 +1,131 @@
+package temingo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"html"
+	"html/template"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// webmentionLinks returns the link elements that announce the webmention and pingback endpoints of the site.
+func (engine *Engine) webmentionLinks() template.HTML {
+	links := ""
+	if engine.WebmentionEndpoint != "" {
+		links += `<link rel="webmention" href="` + html.EscapeString(engine.WebmentionEndpoint) + `">`
+	}
+	if engine.PingbackEndpoint != "" {
+		links += `<link rel="pingback" href="` + html.EscapeString(engine.PingbackEndpoint) + `">`
+	}
+	return template.HTML(links)
+}
+
+// writeWebmentionDiscovery writes a '.well-known/host-meta' file into the outputDir, which lists the endpoints for clients that don't parse html.
+func (engine *Engine) writeWebmentionDiscovery() error {
+	if engine.WebmentionEndpoint == "" && engine.PingbackEndpoint == "" {
+		return nil
+	}
+
+	content := `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+		`<XRD xmlns="http://docs.oasis-open.org/ns/xri/xrd-1.0">` + "\n"
+	if engine.WebmentionEndpoint != "" {
+		content += `  <Link rel="webmention" href="` + html.EscapeString(engine.WebmentionEndpoint) + `"/>` + "\n"
+	}
+	if engine.PingbackEndpoint != "" {
+		content += `  <Link rel="pingback" href="` + html.EscapeString(engine.PingbackEndpoint) + `"/>` + "\n"
+	}
+	content += "</XRD>\n"
+
+	outputFilePath := path.Join(engine.OutputDir, ".well-known", "host-meta")
+	engine.logDebug("Writing webmention discovery file '" + outputFilePath + "' ...")
+	return engine.writeTemplateToFile(outputFilePath, []byte(content))
+}
+
+// getWebmentions returns the received webmentions for the page at pagePath.
+// They are fetched from the webmentionsAPI once per run and cached, so the cached ones can be used if fetching fails or with the offline option.
+func (engine *Engine) getWebmentions(pagePath string) ([]interface{}, error) {
+	target := engine.absoluteURL(pagePath)
+	engine.lock.Lock()
+	mentions, ok := engine.fetchedWebmentions[target]
+	engine.lock.Unlock()
+	if ok {
+		return mentions, nil
+	}
+
+	cacheFilePath := getWebmentionsCacheFilePath(target)
+	mentions = []interface{}{}
+	if engine.WebmentionsAPI != "" && !engine.Offline {
+		fetched, err := engine.fetchWebmentions(target)
+		if err == nil {
+			mentions = fetched
+			content, err := json.Marshal(mentions)
+			if err != nil {
+				return nil, err
+			}
+			engine.createFolderIfNotExists(path.Dir(cacheFilePath))
+			err = ioutil.WriteFile(cacheFilePath, content, engine.fileMode)
+			if err != nil {
+				return nil, err
+			}
+			engine.lock.Lock()
+			engine.fetchedWebmentions[target] = mentions
+			engine.lock.Unlock()
+			return mentions, nil
+		}
+		engine.logWarn("Could not fetch webmentions for '" + target + "', using cached ones instead: " + err.Error())
+	}
+
+	if content, err := ioutil.ReadFile(cacheFilePath); err == nil {
+		err = json.Unmarshal(content, &mentions)
+		if err != nil {
+			return nil, errors.New("Could not parse cached webmentions at '" + cacheFilePath + "': " + err.Error())
+		}
+	}
+	engine.lock.Lock()
+	engine.fetchedWebmentions[target] = mentions
+	engine.lock.Unlock()
+	return mentions, nil
+}
+
+// fetchWebmentions requests the webmentions for target from the webmentionsAPI. The API has to return a jf2 feed, like webmention.io does.
+func (engine *Engine) fetchWebmentions(target string) ([]interface{}, error) {
+	requestURL := strings.ReplaceAll(engine.WebmentionsAPI, "{target}", url.QueryEscape(target))
+	engine.logDebug("Fetching webmentions from '" + redactURLQuery(requestURL) + "' ...")
+
+	client := http.Client{Timeout: 10 * time.Second}
+	response, err := client.Get(requestURL)
+	if err != nil {
+		if urlErr, ok := err.(*url.Error); ok { // its message contains the url
+			return nil, errors.New(urlErr.Op + " '" + redactURLQuery(requestURL) + "': " + urlErr.Err.Error())
+		}
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.New("unexpected status '" + response.Status + "' from '" + redactURLQuery(requestURL) + "'")
+	}
+
+	feed := struct {
+		Children []interface{} `json:"children"`
+	}{}
+	err = json.NewDecoder(response.Body).Decode(&feed)
+	if err != nil {
+		return nil, err
+	}
+	if feed.Children == nil {
+		feed.Children = []interface{}{}
+	}
+	return feed.Children, nil
+}
+
+func getWebmentionsCacheFilePath(target string) string {
+	hash := sha256.Sum256([]byte(target))
+	return path.Join(cacheDir, "webmentions", hex.EncodeToString(hash[:])+".json")
+}
@@ -1,620 +1,494 @@
 package main
 
 import (
-	"bytes"
-	"html/template"
-	"io/ioutil"
-	"log"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
 	"os"
-	"path"
-	"path/filepath"
-	"regexp"
-	"strconv"
+	"reflect"
 	"strings"
-	"time"
-
-	"github.com/Masterminds/sprig"
-	"github.com/PuerkitoBio/purell"
-	"github.com/imdario/mergo"
-	"github.com/otiai10/copy"
-	"github.com/radovskyb/watcher"
-	gitignore "github.com/sabhiram/go-gitignore"
-	flag "github.com/spf13/pflag"
-	"gopkg.in/yaml.v3"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/thetillhoff/temingo/pkg/temingo"
 )
 
 var (
-	debug bool
-	watch bool
-
-	valuesFilePaths         []string
-	inputDir                string
-	partialsDir             string
-	outputDir               string
-	staticDir               string
-	templateExtension       string
-	singleTemplateExtension string
-	partialExtension        string
-	temingoignoreFilePath   string
-
-	listListObjects = make(map[string]map[string]interface{})
-
-	pathValidator = "^[a-z0-9-_./]+$"
-	rexp          = regexp.MustCompile(pathValidator)
+	version = "dev" // set at build time via '-ldflags "-X main.version=<version>"'
+
+	options = temingo.DefaultOptions()
+
+	configFilePath string
+	host           string
+	port           string
+	cleanCache     bool
+	importFrom     string
+	lintFormat     string
+	reviewFormat   string
+	diffFormat     string
+	reviewFail     bool
+	funcsFormat    string
+	dryRun         bool
+	showDiff       bool
+	describe       bool
+	watchFlag      bool
 )
 
-type Breadcrumb struct {
-	Name, Path interface{}
-}
-
-func createFolderIfNotExists(path string) {
-	os.MkdirAll(path, os.ModePerm)
-}
-
-func createBreadcrumbs(path string) []Breadcrumb {
-	if debug {
-		log.Println("Creating breadcrumbs for '" + path + "'.")
-	}
-	breadcrumbs := []Breadcrumb{}
-	currentPath := ""
-	dirNames := strings.Split(path, "/")
-	for ok := true; ok; ok = (len(dirNames) > 1) { // last one is not considered, so no self-reference occurs
-		currentPath = currentPath + "/" + dirNames[0]
-		breadcrumb := Breadcrumb{dirNames[0], currentPath}
-		breadcrumbs = append(breadcrumbs, breadcrumb)
-		dirNames = dirNames[1:] // remove first one, as it is now added to 'currentPath'
-	}
-
-	return breadcrumbs
-}
-
-func isExcludedByTemingoignore(srcPath string, additionalExclusions []string) bool {
-	srcPath = "/" + srcPath
+// applyConfigFile loads the project config file into the options. The 'TEMINGO_ENV' and 'TEMINGO_PROTECT_PASSWORD' environment variables and the flags set on the command line take precedence over it.
+func applyConfigFile(cmd *cobra.Command, args []string) error {
+	configured := temingo.DefaultOptions()
+	configured.Version = version
 
-	ignore, err := gitignore.CompileIgnoreFileAndLines(temingoignoreFilePath, additionalExclusions...)
-	if err != nil {
-		log.Fatalln(err)
+	if configFilePath == "" {
+		configFilePath = temingo.FindConfigFile()
 	}
-
-	if ignore.MatchesPath(srcPath) {
-		if debug {
-			log.Println("Exclusion triggered at '" + srcPath + "', specified in '" + temingoignoreFilePath + "'.")
+	if configFilePath != "" {
+		temingo.New(options).LogDebug("Loading config file '" + configFilePath + "' ...") // logged with the flags, as the config isn't loaded yet
+		if err := temingo.LoadConfigFile(configFilePath, &configured); err != nil {
+			return err
 		}
-		return true
 	}
-
-	return false
-}
-
-func isExcluded(srcPath string, additionalExclusions []string) bool {
-	srcPath = "/" + srcPath
-
-	additionalExclusions = append(additionalExclusions, "/"+temingoignoreFilePath)      // always ignore the ignore file itself
-	additionalExclusions = append(additionalExclusions, "/"+path.Join(outputDir, "**")) // always ignore the outputDir
-	additionalExclusions = append(additionalExclusions, "/"+path.Join(staticDir, "**")) // always ignore the staticDir
-
-	ignore, err := gitignore.CompileIgnoreFileAndLines(temingoignoreFilePath, additionalExclusions...)
-	if err != nil {
-		log.Fatalln(err)
+	if environment, ok := os.LookupEnv("TEMINGO_ENV"); ok {
+		configured.Environment = environment
 	}
-
-	if ignore.MatchesPath((srcPath)) {
-		if debug {
-			log.Println("Exclusion triggered at '" + srcPath + "', specified internally.")
-		}
-		return true
+	if password, ok := os.LookupEnv("TEMINGO_PROTECT_PASSWORD"); ok { // so it doesn't end up in the config file or the shell history
+		configured.ProtectPassword = password
 	}
 
-	return false
-}
-
-func getTemplates(fromPath string, extension string, additionalExclusions []string) [][]string {
-	var templates [][]string
-
-	dirContents, err := ioutil.ReadDir(fromPath)
-	if err != nil {
-		log.Fatalln(err)
-	}
-	for _, entry := range dirContents {
-		if !(entry.Name()[:1] == ".") { // ignore hidden files/folders
-			entryPath := path.Join(fromPath, entry.Name())
-			if fromPath == "." { // path.Join adds this to the filename directly ... which has to be prevented here
-				entryPath = entry.Name()
-			}
-			if !isExcluded(entryPath, additionalExclusions) { // Make all paths absolute from working-directory
-				if entry.IsDir() {
-					templates = append(templates, getTemplates(entryPath, extension, additionalExclusions)...)
-				} else if strings.HasSuffix(entry.Name(), extension) {
-					if !rexp.MatchString(entryPath) {
-						log.Fatalln("The path '" + entryPath + "' doesn't validate against the regular expression '" + pathValidator + "'.")
-					}
-					fileContent, err := ioutil.ReadFile(entryPath)
-					if err != nil {
-						log.Fatalln(err)
-					}
-					templates = append(templates, []string{entryPath, string(fileContent)})
-				}
+	// the yaml keys of the options are the flag names, so the changed flags can be copied over by them
+	flagged := reflect.ValueOf(&options).Elem()
+	result := reflect.ValueOf(&configured).Elem()
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		for i := 0; i < flagged.NumField(); i++ {
+			if strings.Split(flagged.Type().Field(i).Tag.Get("yaml"), ",")[0] == flag.Name {
+				result.Field(i).Set(flagged.Field(i))
 			}
 		}
-	}
+	})
 
-	return templates
+	options = configured
+	return nil
 }
 
-func parseTemplateFiles(name string, baseTemplate string, partialTemplates [][]string) *template.Template {
-	tpl := template.New(name)
-
-	funcMap := sprig.HtmlFuncMap()
+// addLayoutFlags adds the flags describing where the files of a project are located, which are shared by all commands.
+func addLayoutFlags(cmd *cobra.Command) {
+	flags := cmd.PersistentFlags()
+	flags.StringSliceVarP(&options.ValuesFilePaths, "valuesfile", "f", options.ValuesFilePaths, "Sets the path(s) to the values-file(s).")
+	flags.StringToStringVar(&options.ValuesMerge, "valuesMerge", options.ValuesMerge, "Sets how the values at key paths are merged across the values-files, f.e. 'nav.items=append'. Each is either 'override' (default), 'append' (lists are concatenated) or 'replace' (maps are replaced instead of merged).")
+	flags.StringVarP(&options.InputDir, "inputDir", "i", options.InputDir, "Sets the path to the template-file-directory.")
+	flags.StringVarP(&options.PartialsDir, "partialsDir", "p", options.PartialsDir, "Sets the path to the partials-directory.")
+	flags.StringVarP(&options.OutputDir, "outputDir", "o", options.OutputDir, "Sets the destination-path for the compiled templates.")
+	flags.StringVarP(&options.StaticDir, "staticDir", "s", options.StaticDir, "Sets the source-path for the static files.")
+	flags.StringVarP(&options.TemplateExtension, "templateExtension", "t", options.TemplateExtension, "Sets the extension of the template files.")
+	flags.StringVar(&options.SingleTemplateExtension, "singleTemplateExtension", options.SingleTemplateExtension, "Sets the extension of the single-view template files. Automatically excluded from normally loaded templates.")
+	flags.StringVar(&options.PartialExtension, "partialExtension", options.PartialExtension, "Sets the extension of the partial files.") //TODO: not necessary, should be the same as templateExtension, since they are already distringuished by directory -> Might be useful when "modularization" will be implemented
+	flags.StringVar(&options.MarkdownExtension, "markdownExtension", options.MarkdownExtension, "Sets the extension of the markdown content files, f.e. '.md'. Markdown content is disabled without it, so markdown files are copied as they are.")
+	flags.StringSliceVar(&options.ItemIndexFiles, "itemIndexFiles", options.ItemIndexFiles, "Sets the file name(s) which make a folder an item of a list. Each can be a yaml, json, toml or markdown file, the first one existing in a folder contains its values.")
+	flags.StringSliceVar(&options.ItemFiles, "itemFiles", options.ItemFiles, "Sets pattern(s) of yaml, json, toml or markdown files which are items on their own, f.e. '/notes/*.md' makes 'notes/idea.md' the item 'notes/idea'. Matching markdown files aren't rendered as pages.")
+	flags.StringVar(&options.TemingoignoreFilePath, "temingoignore", options.TemingoignoreFilePath, "Sets the path to the ignore file.")
+	flags.BoolVar(&options.NoLock, "noLock", options.NoLock, "Skips the lock file '.temingo.lock', which prevents other temingo processes from writing to the output-dir at the same time.")
+	flags.BoolVarP(&options.Debug, "debug", "d", options.Debug, "Enables the debug mode. Deprecated, use '--verbose' instead.")
+	flags.BoolVar(&options.Verbose, "verbose", options.Verbose, "Logs debug information, like '--debug'.")
+	flags.BoolVarP(&options.Quiet, "quiet", "q", options.Quiet, "Only logs warnings and errors.")
+	flags.StringVar(&options.LogFormat, "logFormat", options.LogFormat, "Sets the format of the log messages, either 'text' or 'json' (one object per line, f.e. for CI).")
+	flags.BoolVar(&options.LogTimestamps, "logTimestamps", options.LogTimestamps, "Prefixes the log messages with the time they were logged at.")
+	flags.StringSliceVar(&options.RedactedKeys, "redactedKeys", options.RedactedKeys, "Sets the patterns of keys whose values are redacted when the values are logged, matched case-insensitively, f.e. '*token*'.")
+	flags.BoolVar(&options.Future, "future", options.Future, "Handles deprecated flags, options and template functions as if they were already removed, to prepare for the next major version early.")
+	flags.StringVarP(&configFilePath, "config", "c", "", "Sets the path to the project config file. Defaults to '"+strings.Join(temingo.ConfigFileNames, "', '")+"', whichever exists first.")
+}
 
-	extrafuncMap := template.FuncMap{
-		"addPercentage": func(a string, b string) string {
-			aInt, err := strconv.Atoi(a[:len(a)-1])
-			if err != nil {
-				log.Fatalln(err)
-			}
-			bInt, err := strconv.Atoi(b[:len(b)-1])
-			if err != nil {
-				log.Fatalln(err)
-			}
-			cInt := aInt + bInt
-			return strconv.Itoa(cInt) + "%"
-		},
-		"include": func(name string, data map[string]interface{}) string {
-			var buf strings.Builder
-			err := tpl.ExecuteTemplate(&buf, name, data)
-			if err != nil {
-				log.Fatalln(err)
-			}
-			result := buf.String()
-			return result
-		},
-		"safeHTML": func(s string) template.HTML {
-			return template.HTML(s)
-		},
-		"safeCSS": func(s string) template.CSS {
-			return template.CSS(s)
-		},
-		"list": func(listPaths ...string) map[string]interface{} {
-			listObjects := make(map[string]interface{})
-			if len(listPaths) == 0 { // If no path is provided
-				listPaths = append(listPaths, filepath.Dir(name)) // Add the default path (folder containing the template)
-			}
-			for _, listPath := range listPaths {
-				mergo.Merge(&listObjects, loadListObjects(listPath))
-				listListObjects[listPath] = listObjects
-			}
-			return listObjects
-		},
-		"urlize": func(oldContent string) string {
-			newContent, err := purell.NormalizeURLString(strings.ReplaceAll(oldContent, " ", "_"), purell.FlagsSafe)
-			if err != nil {
-				log.Fatalln(err)
-			}
-			newContent = strings.ToLower(newContent) // Also convert everything to lowercase. Arguable.
-			if debug {
-				log.Println("Urlized '" + oldContent + "' to '" + newContent + "'.")
-			}
-			return newContent
-		},
-		"capitalize": func(oldContent string) string {
-			newContent := strings.Title(oldContent)
-			if debug {
-				log.Println("Capitalized '" + oldContent + "' to '" + newContent + "'.")
-			}
-			return newContent
-		},
-	}
-	for k, v := range extrafuncMap {
-		funcMap[k] = v
-	}
+// addRenderFlags adds the flags that only affect rendering.
+func addRenderFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.StringSliceVar(&options.CopyExclusions, "copyExclusions", options.CopyExclusions, "Sets additional pattern(s) of files in the input-dir which are not copied to the output-dir, f.e. '*.psd,node_modules'. Unlike the '.temingoignore', they are still available for templating. Negations like '!**/*.md' copy files that are excluded by default or by the '.temingoignore'.")
+	flags.StringSliceVar(&options.TemplateExclusions, "templateExclusions", options.TemplateExclusions, "Sets pattern(s) of templates and markdown files in the input-dir which are not rendered, f.e. 'drafts/'. Unlike the '.temingoignore', they are still copied unless excluded from copying as well. Negations include files of the '.temingoignore'.")
+	flags.StringSliceVar(&options.HtmlExtensions, "htmlExtensions", options.HtmlExtensions, "Sets the output extensions which are rendered with contextual html escaping. All other outputs are rendered as plain text.")
+	flags.StringVar(&options.BaseURL, "baseURL", options.BaseURL, "Sets the absolute URL of the site, f.e. 'https://example.com'. It is used wherever absolute URLs are required.")
+	flags.StringVar(&options.WebmentionEndpoint, "webmentionEndpoint", options.WebmentionEndpoint, "Sets the webmention endpoint of the site, which is announced via the 'webmentionLinks' function and '.well-known/host-meta'.")
+	flags.StringVar(&options.PingbackEndpoint, "pingbackEndpoint", options.PingbackEndpoint, "Sets the pingback endpoint of the site, which is announced via the 'webmentionLinks' function and '.well-known/host-meta'.")
+	flags.StringVar(&options.WebmentionsAPI, "webmentionsAPI", options.WebmentionsAPI, "Sets the url received webmentions are fetched from during the build, f.e. 'https://webmention.io/api/mentions.jf2?token=<token>&target={target}'.")
+	flags.BoolVar(&options.Offline, "offline", options.Offline, "Uses the cached responses of 'getJSON' and 'getYAML' as well as cached webmentions instead of fetching them. Urls which were never fetched are an error.")
+	flags.StringSliceVar(&options.PdfPatterns, "pdf", options.PdfPatterns, "Sets the pattern(s) of rendered files that should additionally be exported to PDF, f.e. '/invoices/**/*.html'.")
+	flags.StringVar(&options.SassCommand, "sassCommand", options.SassCommand, "Sets the command used to compile the Sass stylesheets ('.scss' and '.sass') of the static-dir to css. '{input}' and '{output}' are replaced with the respective file paths.")
+	flags.StringSliceVar(&options.FingerprintPatterns, "fingerprint", options.FingerprintPatterns, "Sets the pattern(s) of static files that are additionally written with a hash of their content in the file name, f.e. '**/*.css'. The 'asset' function returns their fingerprinted paths.")
+	flags.IntVar(&options.ImageQuality, "imageQuality", options.ImageQuality, "Sets the quality of the jpeg images written by 'imageResize', 'imageCrop' and 'imageConvert', from 1 to 100.")
+	flags.StringVar(&options.PdfCommand, "pdfCommand", options.PdfCommand, "Sets the command used for the PDF export. '{input}' and '{output}' are replaced with the respective file paths.")
+	flags.BoolVar(&options.BuildDrafts, "buildDrafts", options.BuildDrafts, "Includes items, markdown files and templates with 'draft: true' in the build.")
+	flags.BoolVar(&options.BuildFuture, "buildFuture", options.BuildFuture, "Includes items, markdown files and templates with a 'date' in the future in the build.")
+	flags.StringVar(&options.FileMode, "fileMode", options.FileMode, "Sets the permissions of the written files in octal notation. They are reduced by the umask of the process, like for any other program.")
+	flags.StringVar(&options.DirMode, "dirMode", options.DirMode, "Sets the permissions of the created directories in octal notation. They are reduced by the umask of the process, like for any other program.")
+	flags.BoolVar(&options.ProfileTemplates, "profileTemplates", options.ProfileTemplates, "Logs the time spent per template, included partial and list after rendering, to find slow ones.")
+	flags.BoolVar(&options.Profile, "profile", options.Profile, "Logs the time spent per phase of the build, like loading the values, rendering and copying files, together with the time spent per template.")
+	flags.StringVar(&options.ProfileDir, "profileDir", options.ProfileDir, "Sets the folder the pprof cpu and heap profiles of 'temingo build' are written to, as 'cpu.pprof' and 'heap.pprof'.")
+	flags.BoolVar(&options.Minify, "minify", options.Minify, "Minifies the rendered html, css and js outputs, including inline styles and scripts.")
+	flags.BoolVar(&options.MinifyStatic, "minifyStatic", options.MinifyStatic, "Minifies the css and js files copied from the static-dir.")
+	flags.BoolVar(&options.FlatContext, "flatContext", options.FlatContext, "Passes the values to the templates at the top-level, together with 'breadcrumbs', 'Item' and 'ItemPath', instead of namespacing them. Deprecated, kept for compatibility.")
+	flags.StringVar(&options.MarkdownLayout, "markdownLayout", options.MarkdownLayout, "Sets the name of the partial markdown content files are rendered with, unless they specify a 'layout' in their front matter or values.")
+	flags.IntVar(&options.SummaryWords, "summaryWords", options.SummaryWords, "Sets the number of words of the summaries of markdown content and items without a '<!--more-->' marker.")
+	flags.IntVar(&options.ReadingSpeed, "readingSpeed", options.ReadingSpeed, "Sets the words per minute the reading time of markdown content and items is calculated with.")
+	flags.IntVar(&options.Concurrency, "concurrency", options.Concurrency, "Sets the number of outputs rendered at the same time. Defaults to the number of usable CPUs.")
+	flags.BoolVar(&options.Sitemap, "sitemap", options.Sitemap, "Generates a 'sitemap.xml' of all rendered html pages, if a base URL is set and the site doesn't provide its own.")
+	flags.BoolVar(&options.SearchIndex, "searchIndex", options.SearchIndex, "Generates a 'search-index.json' with the url, title, description, tags and text of all rendered html pages for client-side search with f.e. lunr.js or Fuse.js, if the site doesn't provide its own.")
+	flags.StringVar(&options.ProtectPassword, "protectPassword", options.ProtectPassword, "Sets the password the html pages with 'protected: true' are encrypted with, they are decrypted in the browser once it's entered. Defaults to the 'TEMINGO_PROTECT_PASSWORD' environment variable, if set.")
+	flags.StringToStringVar(&options.ExecFunctions, "execFunctions", options.ExecFunctions, "Adds template functions implemented by external commands, f.e. 'price=./scripts/price.py'. The command gets the arguments as json array on stdin and prints the result, which is decoded as json if possible. A failing command fails the template.")
+	flags.StringArrayVar(&options.PreBuild, "preBuild", options.PreBuild, "Sets a shell command that is run before each build, f.e. 'npm run build:css'. Can be repeated, the commands run in the given order and a failing one aborts the build.")
+	flags.StringArrayVar(&options.PostBuild, "postBuild", options.PostBuild, "Sets a shell command that is run after each successful build, f.e. 'aws s3 sync output s3://example.com'. Can be repeated, the commands run in the given order and a failing one fails the build.")
+	flags.StringVar(&options.ReviewEvery, "reviewEvery", options.ReviewEvery, "Sets the interval after which content with 'lastReviewed' is due for review, unless it has its own 'reviewEvery', f.e. '90d', '2w', '6m' or '1y'.")
+	flags.BoolVar(&options.CheckLinks, "checkLinks", options.CheckLinks, "Logs a warning for each link of the rendered html pages (f.e. 'href' and 'src') to a file which doesn't exist in the output-dir.")
+	flags.BoolVar(&options.FailOnBrokenLinks, "failOnBrokenLinks", options.FailOnBrokenLinks, "Fails the build if links of the rendered html pages point to files which don't exist in the output-dir, f.e. for CI. Implies '--checkLinks'.")
+	flags.StringVar(&options.MaxOutputSize, "maxOutputSize", options.MaxOutputSize, "Sets the maximum size of the output of a template or a single include, f.e. '10MB'. Larger outputs fail the template, '0' disables the limit.")
+	flags.DurationVar(&options.MaxRenderTime, "maxRenderTime", options.MaxRenderTime, "Sets the maximum time rendering a single template may take, f.e. '30s'. It's checked whenever the template writes output or includes a partial, '0' disables the limit.")
+	flags.IntVar(&options.MaxIncludeDepth, "maxIncludeDepth", options.MaxIncludeDepth, "Sets the maximum number of nested 'include' calls, so partials including themselves fail the template.")
+	flags.StringSliceVar(&options.EnvAllowlist, "envAllowlist", options.EnvAllowlist, "Sets the environment variables available via the 'env' template function and expanded as '${NAME}' in values files, f.e. 'BASE_URL,API_*'. Other environment variables aren't accessible.")
+	flags.StringVar(&options.ValuesSchema, "valuesSchema", options.ValuesSchema, "Sets the path of a schema file the merged values are validated against before rendering, f.e. to catch typos in keys. Items are validated against a 'schema.yaml' in their collection folder.")
+	flags.StringVar(&options.Manifest, "manifest", options.Manifest, "Sets the path of a file the build manifest is written to after each build, f.e. 'manifest.json'. Later builds can be compared against it with 'temingo diff'.")
+	flags.StringArrayVar(&options.OutputNames, "outputNames", options.OutputNames, "Sets a rule naming the outputs of pages without permalink, as gitignore pattern of the sources and permalink separated by '=', f.e. '*.template=:dir/:slug/' to render 'page.template' to 'page/index.html'. Can be repeated, the first matching rule applies.")
+	flags.StringSliceVar(&options.Taxonomies, "taxonomies", options.Taxonomies, "Sets the values of pages and items which are taxonomies, f.e. 'tags'. A template with 'taxonomy: tags' in its front matter is rendered once per tag.")
+	flags.StringSliceVar(&options.Languages, "languages", options.Languages, "Sets the language(s) the site is rendered in, f.e. 'en,de'. The first one is the default language and rendered to the output-dir itself, the others to a folder named after them.")
+	flags.StringVar(&options.DataDir, "dataDir", options.DataDir, "Sets the path to the directory containing yaml, json and toml files, which are available in templates as '.Data', f.e. 'data/team.yaml' as '.Data.team'.")
+	flags.StringVar(&options.TranslationsDir, "translationsDir", options.TranslationsDir, "Sets the path to the directory containing the translations of the 'T' function, one '<language>.yaml' per language.")
+	flags.StringVar(&options.AliasRedirects, "aliasRedirects", options.AliasRedirects, "Sets how the 'aliases' of pages redirect to them. 'page' writes redirect pages with a canonical link to the page, 'server' writes empty placeholders and adds 301 redirects to '.Site.Redirects' for the templates of server configuration files.")
+	flags.StringVar(&options.SlugCollisions, "slugCollisions", options.SlugCollisions, "Sets how generated pages with the same slug are handled. 'fail' aborts the build naming both elements, 'suffix' appends '-2', '-3', ... to the slugs of the later ones.")
+	flags.StringVar(&options.Environment, "environment", options.Environment, "Sets the environment the site is built for, f.e. 'production'. Its values files like 'values.production.yaml' are merged over the base ones, and it's available as '.Env' and '.Build.Environment'. Defaults to the 'TEMINGO_ENV' environment variable, if set.")
+}
 
-	for index := range partialTemplates {
-		partialTemplateContent := partialTemplates[index][1]
-		_, err := tpl.Funcs(funcMap).Parse(partialTemplateContent)
-		if err != nil {
-			log.Fatalln(err)
-		}
-	}
-	_, err := tpl.Funcs(funcMap).Parse(baseTemplate)
-	if err != nil {
-		log.Fatalln(err)
-	}
-	return tpl
+// addWatchFlags adds the flags that only affect watching.
+func addWatchFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.BoolVar(&options.Poll, "poll", options.Poll, "Checks watched files for changes every watchInterval, instead of being notified about them by the operating system. F.e. for network drives and containers, where notifications don't work.")
+	flags.DurationVar(&options.WatchInterval, "watchInterval", options.WatchInterval, "Sets the interval in which watched files are checked for changes when polling.")
+	flags.DurationVar(&options.WatchDebounce, "watchDebounce", options.WatchDebounce, "Sets how long to wait for further changes before rebuilding, so f.e. saving several files at once results in a single rebuild. A build is canceled when files change while it's running.")
+	flags.BoolVar(&options.Notify, "notify", options.Notify, "Shows a desktop notification after each build, so failed builds are noticed while working in another window.")
+	flags.StringVar(&options.NotifyWebhook, "notifyWebhook", options.NotifyWebhook, "Sets a url a json summary of each build is posted to, with its 'status' ('success' or 'failure'), 'message', counts, 'durationMs' and 'errors'.")
+	flags.StringVar(&options.WatchLog, "watchLog", options.WatchLog, "Sets a file json lines are appended to for each change and each build, f.e. for editor integrations. Builds contain their 'status' ('success', 'failure' or 'canceled'), 'trigger' paths, counts, 'durationMs', rendered 'pages' and 'errors'.")
+	flags.StringSliceVar(&options.WatchExclusions, "watchExclusions", options.WatchExclusions, "Sets pattern(s) of files whose changes don't trigger a rebuild while watching, f.e. 'assets/videos/'. They are still rendered and copied.")
 }
 
-func writeTemplateToFile(filePath string, content []byte) error {
-	dirPath := strings.TrimSuffix(filePath, path.Base(filePath))
-	createFolderIfNotExists(dirPath)
-	err := ioutil.WriteFile(filePath, content, os.ModePerm)
-	return err
+// addBuildFlags adds the flags that only affect a single build.
+func addBuildFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.BoolVar(&dryRun, "dryRun", false, "Renders the project without touching the output-dir, and prints which of its files would be created, changed or deleted.")
+	flags.BoolVar(&showDiff, "diff", false, "Additionally prints the unified diff of each changed file with '--dryRun'.")
+	flags.BoolVar(&describe, "describe", false, "Prints the resolved project as json instead of rendering it: the templates, partials, values keys, pages and which outputs are rendered from which template, f.e. for editor extensions.")
 }
 
-func readCliFlags() {
-	var (
-		info os.FileInfo
-		err  error
-	)
-
-	flag.StringSliceVarP(&valuesFilePaths, "valuesfile", "f", []string{"values.yaml"}, "Sets the path(s) to the values-file(s).")
-	flag.StringVarP(&inputDir, "inputDir", "i", ".", "Sets the path to the template-file-directory.")
-	flag.StringVarP(&partialsDir, "partialsDir", "p", "partials", "Sets the path to the partials-directory.")
-	flag.StringVarP(&outputDir, "outputDir", "o", "output", "Sets the destination-path for the compiled templates.")
-	flag.StringVarP(&staticDir, "staticDir", "s", "static", "Sets the source-path for the static files.")
-	flag.StringVarP(&templateExtension, "templateExtension", "t", ".template", "Sets the extension of the template files.")
-	flag.StringVar(&singleTemplateExtension, "singleTemplateExtension", ".single.template", "Sets the extension of the single-view template files. Automatically excluded from normally loaded templates.")
-	flag.StringVar(&partialExtension, "partialExtension", ".partial", "Sets the extension of the partial files.") //TODO: not necessary, should be the same as templateExtension, since they are already distringuished by directory -> Might be useful when "modularization" will be implemented
-	flag.StringVar(&temingoignoreFilePath, "temingoignore", ".temingoignore", "Sets the path to the ignore file.")
-	flag.BoolVarP(&watch, "watch", "w", false, "Watches the template-file-directory, partials-directory and values-files.")
-	flag.BoolVarP(&debug, "debug", "d", false, "Enables the debug mode.")
-
-	flag.Parse() // Actually read the configured cli-flags
-
-	for i, valuesfilePath := range valuesFilePaths { // for each path stated
-		valuesFilePaths[i] = path.Clean(valuesfilePath) // clean path
-		info, err = os.Stat(valuesFilePaths[i])
-		if os.IsNotExist(err) { // if path doesn't exist
-			log.Fatalln("Values file does not exist: " + valuesFilePaths[i])
-		} else if info.IsDir() { // if is not a directoy
-			log.Fatalln("Values file is not a file (but a directory): " + valuesFilePaths[i])
+func build(cmd *cobra.Command, args []string) {
+	engine := temingo.New(options)
+	if describe {
+		model, err := engine.Describe()
+		exitOnError(engine, err)
+		content, err := json.MarshalIndent(model, "", "  ")
+		exitOnError(engine, err)
+		fmt.Println(string(content))
+		return
+	}
+	if dryRun {
+		changes, err := engine.Preview(showDiff)
+		exitOnError(engine, err)
+		for _, change := range changes {
+			fmt.Printf("%-8s %s\n", change.Change, change.Path)
+			fmt.Print(change.Diff)
 		}
+		engine.LogInfo(fmt.Sprintf("*** Dry run: %d file(s) would change ***", len(changes)))
+		return
 	}
+	exitOnError(engine, engine.Render()) // copy static contents & render templates once, then update the changed files of the output-folder
+}
 
-	inputDir = path.Clean(inputDir)
-	info, err = os.Stat(inputDir)
-	if os.IsNotExist(err) { // if path doesn't exist
-		log.Fatalln("Given input-directory does not exist: " + inputDir)
-	} else if !info.IsDir() { // if is not a directory
-		log.Fatalln("Given input-directory is not a directory: " + inputDir)
+// buildOrWatch is the root command, which builds the project, or watches it with the deprecated '--watch' flag.
+func buildOrWatch(cmd *cobra.Command, args []string) {
+	if watchFlag {
+		watch(cmd, args)
+		return
 	}
+	build(cmd, args)
+}
 
-	partialsDir = path.Clean(partialsDir)
-	info, err = os.Stat(partialsDir)
-	if os.IsNotExist(err) { // if path doesn't exist
-		log.Fatalln("Given partial-files-directory does not exist: " + partialsDir)
-	} else if !info.IsDir() { // if is not a directory
-		log.Fatalln("Given partial-files-directory is not a directory: " + partialsDir)
-	}
+func watch(cmd *cobra.Command, args []string) {
+	engine := temingo.New(options)
+	exitOnError(engine, engine.Watch()) // render once & start to watch
+}
 
-	outputDir = path.Clean(outputDir)
-	info, err = os.Stat(outputDir)
-	if os.IsNotExist(err) { // if path doesn't exist
-		log.Fatalln("Given output-directory does not exist: " + outputDir)
-	} else if !info.IsDir() { // if is not a directory
-		log.Fatalln("Given output-directory is not a directory: " + outputDir)
-	}
+func serve(cmd *cobra.Command, args []string) {
+	engine := temingo.New(options)
+	address := net.JoinHostPort(host, port)
+	go func() {
+		engine.LogInfo("Serving '" + options.OutputDir + "' at http://" + address + " ...")
+		exitOnError(engine, http.ListenAndServe(address, http.FileServer(http.Dir(options.OutputDir))))
+	}()
 
-	staticDir = path.Clean(staticDir)
-	info, err = os.Stat(staticDir)
-	if os.IsNotExist(err) { // if path doesn't exist
-		log.Fatalln("Given static-files-directory does not exist: " + staticDir)
-	} else if !info.IsDir() { // if is not a directory
-		log.Fatalln("Given static-files-directory is not a directory: " + staticDir)
-	}
+	exitOnError(engine, engine.Watch())
 }
 
-func getMappedValues() map[string]interface{} {
-	var mappedValues map[string]interface{}
-	for _, v := range valuesFilePaths {
-		tempMappedValues := loadYaml(v)
+func initProject(cmd *cobra.Command, args []string) {
+	engine := temingo.New(options)
+	exitOnError(engine, engine.Init())
+}
 
-		err := mergo.Merge(&mappedValues, tempMappedValues, mergo.WithOverride)
-		if err != nil {
-			log.Fatalln(err)
-		}
-	}
-	return mappedValues
+func importSite(cmd *cobra.Command, args []string) {
+	engine := temingo.New(options)
+	exitOnError(engine, engine.Import(importFrom, args[0]))
 }
 
-func runTemplate(mappedValues map[string]interface{}, templateName string, template string, partialTemplates [][]string, outputFilePath string) {
-	outputBuffer := new(bytes.Buffer)
-	outputBuffer.Reset()
-	tpl := parseTemplateFiles(templateName, template, partialTemplates)
-	mappedValues["breadcrumbs"] = createBreadcrumbs(filepath.Dir(templateName))
-	err := tpl.Execute(outputBuffer, mappedValues)
-	if err != nil {
-		log.Fatalln(err)
-	}
-	if _, err := os.Stat(outputDir); os.IsNotExist(err) { // If output directory doesn't exist
-		createFolderIfNotExists(outputDir)
-	}
-	err = writeTemplateToFile(outputFilePath, outputBuffer.Bytes())
-	if err != nil {
-		log.Fatalln(err)
-	}
+func bundle(cmd *cobra.Command, args []string) {
+	engine := temingo.New(options)
+	exitOnError(engine, engine.Bundle(args[0]))
 }
 
-func render() {
-	// #####
-	// START reading value files
-	// #####
-	if debug {
-		log.Println("*** Reading values file(s) ... ***")
-	}
-	mappedValues := getMappedValues()
-	if debug {
-		valuesYaml, err := yaml.Marshal(mappedValues)
-		if err != nil {
-			log.Fatalln(err)
+func lint(cmd *cobra.Command, args []string) {
+	engine := temingo.New(options)
+	if lintFormat != "text" && lintFormat != "json" {
+		exitOnError(engine, errors.New("The lint format must be either 'text' or 'json', but is '"+lintFormat+"'."))
+	}
+	issues, err := engine.Lint()
+	exitOnError(engine, err)
+
+	failed := false
+	for _, issue := range issues {
+		failed = failed || issue.Severity == "error"
+	}
+	if lintFormat == "json" {
+		content, err := json.MarshalIndent(issues, "", "  ")
+		exitOnError(engine, err)
+		fmt.Println(string(content))
+	} else {
+		for _, issue := range issues {
+			fmt.Printf("%s:%d: %s: %s [%s]\n", issue.File, issue.Line, issue.Severity, issue.Message, issue.Rule)
 		}
-		log.Println("*** General values-object: ***\n" + string(valuesYaml))
+		engine.LogInfo(fmt.Sprintf("*** Found %d issue(s) ***", len(issues)))
 	}
-
-	// #####
-	// END reading value files
-	// START normal templating
-	// #####
-
-	templates := getTemplates(inputDir, templateExtension, []string{"**/*" + singleTemplateExtension}) // get full html templates - with names
-	partialTemplates := getTemplates(partialsDir, partialExtension, []string{})                        // get partial html templates - without names
-
-	for _, template := range templates {
-		outputFilePath := path.Join(outputDir, strings.TrimSuffix(template[0], templateExtension))
-		if debug {
-			log.Println("Writing output file '" + outputFilePath + "' ...")
-		}
-		runTemplate(mappedValues, template[0], template[1], partialTemplates, outputFilePath)
+	if failed {
+		os.Exit(1)
 	}
+}
 
-	// #####
-	// END normal templating
-	// START single-view templating
-	// #####
-
-	// identify & collect single-view templates via their extension
-	singleTemplates := getTemplates(inputDir, singleTemplateExtension, []string{
-		path.Join(inputDir, partialsDir, "**"),
-		path.Join(inputDir, outputDir, "**"),
-	}) // get full html templates - with names
-
-	// for each of the single-view templates
-	for _, template := range singleTemplates {
-		templateName := template[0]
-		template := template[1]
-		// search all configurations
-
-		dirContents, err := ioutil.ReadDir(filepath.Dir(templateName))
-		if err != nil {
-			log.Fatalln(err)
-		}
-
-		itemValues := make(map[string]interface{})
-
-		// Read item-specific values, so they are available independent of the items way of the configuration
-		for _, dirEntry := range dirContents {
-			if dirEntry.IsDir() {
-				if _, err := os.Stat(path.Join(filepath.Dir(templateName), dirEntry.Name(), "index.yaml")); err == nil { // if the dirEntry-folder contains an "index.yaml"
-					itemValues[path.Join(filepath.Dir(templateName), dirEntry.Name())] = loadYaml(path.Join(filepath.Dir(templateName), dirEntry.Name(), "index.yaml"))
-				}
-			}
-		}
-
-		for itemPath, itemValue := range itemValues {
-			// load corresponding additional values into mappedValues["Item"]
-			extendedMappedValues := mappedValues
-			itemPath = strings.TrimSuffix(itemPath, filepath.Ext(itemPath))
-			fileName := strings.TrimSuffix(filepath.Base(templateName), singleTemplateExtension)
-			extendedMappedValues["ItemPath"] = "/" + itemPath
-			extendedMappedValues["Item"] = itemValue
-			outputFilePath := path.Join(outputDir, itemPath, fileName)
-			if debug {
-				log.Println("Writing single-view output from '" + itemPath + "*' to '" + outputFilePath + "' ...") // itemPath is incomplete; either its a yaml-file or a folder containing an index.yaml -> Therefore it has the '*' behind it.
-			}
-			runTemplate(extendedMappedValues, templateName, template, partialTemplates, outputFilePath)
+func review(cmd *cobra.Command, args []string) {
+	engine := temingo.New(options)
+	if reviewFormat != "text" && reviewFormat != "json" {
+		exitOnError(engine, errors.New("The review format must be either 'text' or 'json', but is '"+reviewFormat+"'."))
+	}
+	items, err := engine.Review()
+	exitOnError(engine, err)
+
+	if reviewFormat == "json" {
+		content, err := json.MarshalIndent(items, "", "  ")
+		exitOnError(engine, err)
+		fmt.Println(string(content))
+	} else {
+		for _, item := range items {
+			fmt.Printf("%s: last reviewed %s, due since %s (%d day(s) overdue)\n", item.Path, item.LastReviewed, item.ReviewDue, item.OverdueDays)
 		}
+		engine.LogInfo(fmt.Sprintf("*** Found %d page(s) overdue for review ***", len(items)))
+	}
+	if reviewFail && len(items) > 0 {
+		os.Exit(1)
 	}
-
-	// #####
-	// END single-view templating
-	// #####
 }
 
-func watchAll() {
-	log.Println("*** Starting to watch for file changes ... ***")
-
-	// ignoring before adding, so the "to-be-ignored" paths won't be added
-	w := watcher.New()
-
-	// SetMaxEvents to 1 to allow at most 1 event's to be received
-	// on the Event channel per watching cycle.
-	// If SetMaxEvents is not set, the default is to send all events.
-	w.SetMaxEvents(1)
-
-	w.Ignore(outputDir) // ignore the outputfolder
-
-	w.Ignore(".git") // ignore the git-folder natively
-
-	if err := w.AddRecursive(inputDir); err != nil { // watch the input-files-directory recursively
-		log.Fatalln(err)
-	}
-	if err := w.AddRecursive(partialsDir); err != nil { // watch the partials-files-directory recursively
-		log.Fatalln(err)
-	}
-	for _, valuesFile := range valuesFilePaths { // for each valuesfilepath
-		if err := w.Add(valuesFile); err != nil { // watch the values-file
-			log.Fatalln(err)
+func diff(cmd *cobra.Command, args []string) {
+	engine := temingo.New(options)
+	if diffFormat != "text" && diffFormat != "json" {
+		exitOnError(engine, errors.New("The diff format must be either 'text' or 'json', but is '"+diffFormat+"'."))
+	}
+	exitOnError(engine, engine.Render()) // the current build is compared
+	report, err := engine.Diff(args[0])
+	exitOnError(engine, err)
+
+	if diffFormat == "json" {
+		content, err := json.MarshalIndent(report, "", "  ")
+		exitOnError(engine, err)
+		fmt.Println(string(content))
+		return
+	}
+	for _, section := range []struct {
+		name  string
+		files []temingo.DiffFile
+	}{{"Added", report.Added}, {"Removed", report.Removed}, {"Changed", report.Changed}} {
+		if len(section.files) == 0 {
+			continue
 		}
-	}
-
-	if debug {
-		log.Println("Watched paths/files:")
-		// Print a list of all of the files and folders currently being watched and their paths.
-		for watchedPath, f := range w.WatchedFiles() {
-			log.Println(path.Join(watchedPath, f.Name()))
+		fmt.Printf("## %s (%d)\n", section.name, len(section.files))
+		for _, file := range section.files {
+			if file.Title != "" {
+				fmt.Printf("- %s (%s)\n", file.Path, file.Title)
+			} else {
+				fmt.Printf("- %s\n", file.Path)
+			}
 		}
+		fmt.Println()
 	}
-
-	go func() {
-		for { // while true
-			select {
-			case event := <-w.Event: // receive events
-				log.Println("*** Rebuilding because of a change in", event.Path, "***")
-				rebuildOutput()
-			case err := <-w.Error: // receive errors
-				log.Fatalln(err)
-			case <-w.Closed:
-				return
-			}
+	if len(report.RemovedLinks) > 0 {
+		fmt.Printf("## Removed links (%d)\n", len(report.RemovedLinks))
+		for _, link := range report.RemovedLinks {
+			fmt.Printf("- %s no longer links to %s\n", link.Page, link.Target)
 		}
-	}()
-
-	// Start the watching process - it'll check for changes every 100ms.
-	if err := w.Start(time.Millisecond * 100); err != nil {
-		log.Fatalln(err)
+		fmt.Println()
 	}
+	engine.LogInfo(fmt.Sprintf("*** %d file(s) added, %d removed, %d changed, %d link(s) removed ***", len(report.Added), len(report.Removed), len(report.Changed), len(report.RemovedLinks)))
 }
 
-func rebuildOutput() {
-	// #####
-	// START Delete output-dir contents
-	// #####
-
-	if debug {
-		log.Println("*** Deleting contents in output-dir ... ***")
+func funcs(cmd *cobra.Command, args []string) {
+	engine := temingo.New(options)
+	if funcsFormat != "text" && funcsFormat != "json" {
+		exitOnError(engine, errors.New("The funcs format must be either 'text' or 'json', but is '"+funcsFormat+"'."))
 	}
+	functions, err := engine.Funcs()
+	exitOnError(engine, err)
 
-	dirContents, err := ioutil.ReadDir(outputDir)
-	if err != nil {
-		log.Fatalln(err)
+	if funcsFormat == "json" {
+		content, err := json.MarshalIndent(functions, "", "  ")
+		exitOnError(engine, err)
+		fmt.Println(string(content))
+		return
 	}
-	for _, element := range dirContents {
-		elementPath := path.Join(outputDir, element.Name())
-		if debug {
-			log.Println("Deleting output-dir content at: " + elementPath)
+	for _, function := range functions {
+		signature := function.Signature
+		if signature == "" {
+			signature = function.Name
 		}
-		err = os.RemoveAll(elementPath)
-		if err != nil {
-			log.Fatalln(err)
+		fmt.Printf("%s [%s]\n    %s\n", signature, function.Source, function.Description)
+		if function.Deprecated != "" {
+			fmt.Printf("    Deprecated, %s.\n", function.Deprecated)
 		}
 	}
-
-	// #####
-	// END Delete output-dir contents
-	// START Copy static-dir contents to output-dir
-	// #####
-
-	if debug {
-		log.Println("*** Copying contents of static-dir to output-dir ... ***")
-	}
-
-	err = copy.Copy(staticDir, outputDir)
-	if err != nil {
-		log.Fatalln(err)
-	}
-
-	// #####
-	// END Copy static-dir-contents to output-dir
-	// START Copy other contents to output-dir
-	// #####
-
-	if debug {
-		log.Println("*** Copying other contents to output-dir ... ***")
-	}
-
-	opt := copy.Options{
-		Skip: func(src string) (bool, error) {
-			skip := false
-			if isExcluded(src, []string{path.Join("/", partialsDir), "**/*" + templateExtension, "**/index.yaml"}) || isExcludedByTemingoignore(src, []string{}) {
-				skip = true
-			}
-			return skip, nil
-		},
-	}
-	err = copy.Copy(inputDir, outputDir, opt)
-	if err != nil {
-		log.Fatalln(err)
-	}
-
-	// #####
-	// END Copy other contents to output-dir
-	// START Render templates
-	// #####
-
-	if debug {
-		log.Println("*** Starting templating process ... ***")
-	}
-
-	render()
-	log.Println("*** Successfully built contents. ***")
-
-	// #####
-	// END Render templates
-	// #####
 }
 
-func loadYaml(filePath string) map[string]interface{} {
-	var mappedObject map[string]interface{}
-	values, err := ioutil.ReadFile(filePath)
-	if err != nil {
-		log.Fatalln(err)
+func clean(cmd *cobra.Command, args []string) {
+	engine := temingo.New(options)
+	exitOnError(engine, engine.Clean())
+	if cleanCache {
+		exitOnError(engine, engine.CleanCache())
 	}
-	yaml.Unmarshal([]byte(values), &mappedObject) // store yaml into map
-
-	// valuesYaml, err := yaml.Marshal(mappedValues) // convert map to yaml/string
-	return mappedObject
 }
 
-func loadListObjects(listPath string) map[string]interface{} {
-	if debug {
-		log.Println("*** Loading list objects from '" + listPath + "' ... ***")
-	}
-	contents, err := ioutil.ReadDir(path.Join(path.Clean("."), path.Clean(listPath)))
+// exitOnError logs err in the log format of the engine and exits, if there is an error.
+func exitOnError(engine *temingo.Engine, err error) {
 	if err != nil {
-		log.Fatalln(err)
-	}
-	mappedObjects := make(map[string]interface{})
-	for _, element := range contents {
-		elementPath := path.Join(listPath, element.Name()) // f.e. list/element1 for folders
-		indexPath := path.Join(elementPath, "index.yaml")  // f.e. list/element1/index.yaml
-		if _, err := os.Stat(indexPath); err == nil {      // if list/element1/index.yaml exists
-			if !rexp.MatchString(indexPath) { // if path is not good for urls
-				log.Fatalln("The path '" + indexPath + "' for the list object must validate against the regular expression '" + pathValidator + "'.")
-			}
-			tempMappedObject := loadYaml(indexPath)      // f.e. list/element1/index.yaml
-			tempMappedObject["Path"] = "/" + elementPath // will become /[.../]list/element1 (or actually /[.../]list/element1/index.html)
-			mappedObjects[elementPath] = tempMappedObject
-			if debug {
-				log.Println("Loaded object from '" + indexPath + "' ...")
-			}
-		}
+		engine.LogError(err)
+		os.Exit(1)
 	}
-
-	return mappedObjects
 }
 
 func main() {
-	// #####
-	// START declaring variables
-	// #####
-
-	// no log.Println for debug before this, because the flags have to be read first ;)
-	readCliFlags()
-	// # example $> ./template -valuesfile values.yaml -inputDir ./ -partialsDir partials-html/ -templateExtension .html.template -generatedExtension .html
-
-	if debug {
-		log.Println("valuesFilePaths:", valuesFilePaths)
-		log.Println("inputDir:", inputDir)
-		log.Println("partialsDir:", partialsDir)
-		log.Println("outputDir:", outputDir)
-		log.Println("templateExtension:", templateExtension)
-		log.Println("singleTemplateExtension:", singleTemplateExtension)
-		log.Println("partialExtension:", partialExtension)
-		log.Println("temingoignoreFilePath:", temingoignoreFilePath)
-		log.Println("staticDir:", staticDir)
-		log.Println("watch:", watch)
-	}
-
-	// #####
-	// END declaring variables
-	// START rendering
-	// #####
-
-	if !watch { // if not watching
-		rebuildOutput() // delete old contents of output-folder & copy static contents & render templates once
-	} else { // else (== if watching)
-		watchAll() // start to watch
-	}
-
-	// #####
-	// END rendering
-	// #####
+	rootCmd := &cobra.Command{
+		Use:               "temingo",
+		Version:           version,
+		Short:             "Renders go templates into a static site",
+		Long:              "Renders go templates into a static site. Without a subcommand, it behaves like 'temingo build'.",
+		Args:              cobra.NoArgs,
+		PersistentPreRunE: applyConfigFile,
+		SilenceUsage:      true, // errors of the config file are no usage errors
+		Run:               buildOrWatch,
+	}
+	addLayoutFlags(rootCmd)
+	addRenderFlags(rootCmd)
+	addBuildFlags(rootCmd)
+	rootCmd.Flags().BoolVarP(&watchFlag, "watch", "w", false, "Watches the project like 'temingo watch'.")
+	rootCmd.Flags().MarkDeprecated("watch", "use 'temingo watch' instead") // kept, so existing scripts keep working
+
+	buildCmd := &cobra.Command{
+		Use:   "build",
+		Short: "Renders the project once",
+		Args:  cobra.NoArgs,
+		Run:   build,
+	}
+	addRenderFlags(buildCmd)
+	addBuildFlags(buildCmd)
+
+	watchCmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Renders the project and rerenders it whenever a template, partial or values file changes",
+		Args:  cobra.NoArgs,
+		Run:   watch,
+	}
+	addRenderFlags(watchCmd)
+	addWatchFlags(watchCmd)
+
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Watches the project and serves the outputDir via http",
+		Args:  cobra.NoArgs,
+		Run:   serve,
+	}
+	addRenderFlags(serveCmd)
+	addWatchFlags(serveCmd)
+	serveCmd.Flags().StringVar(&host, "host", "localhost", "Sets the host the http server listens on.")
+	serveCmd.Flags().StringVar(&port, "port", "8080", "Sets the port the http server listens on.")
+
+	initCmd := &cobra.Command{
+		Use:   "init",
+		Short: "Creates the folders and files of an example project",
+		Args:  cobra.NoArgs,
+		Run:   initProject,
+	}
+
+	importCmd := &cobra.Command{
+		Use:   "import <dir>",
+		Short: "Converts the site of another static site generator in <dir> into a temingo project",
+		Args:  cobra.ExactArgs(1),
+		Run:   importSite,
+	}
+	importCmd.Flags().StringVar(&importFrom, "from", "", "Sets the static site generator the site is built with, one of '"+strings.Join(temingo.ImportSources, "', '")+"'.")
+	importCmd.MarkFlagRequired("from")
+
+	bundleCmd := &cobra.Command{
+		Use:   "bundle <dir>",
+		Short: "Writes a self-contained copy of the project with its merged values and options to <dir>",
+		Args:  cobra.ExactArgs(1),
+		Run:   bundle,
+	}
+	addRenderFlags(bundleCmd) // all options are part of the bundled config
+
+	lintCmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Checks the templates and partials for mistakes without rendering them",
+		Args:  cobra.NoArgs,
+		Run:   lint,
+	}
+	lintCmd.Flags().StringToStringVar(&options.LintRules, "lintRules", options.LintRules, "Sets the severity of lint rules, f.e. 'unsafe-html=error,deprecated-function=off'. Each is either 'error', 'warning' or 'off'.")
+	lintCmd.Flags().StringVar(&lintFormat, "lintFormat", "text", "Sets the format the issues are printed in, either 'text' or 'json' (f.e. for CI).")
+
+	reviewCmd := &cobra.Command{
+		Use:   "review",
+		Short: "Lists the pages and items overdue for review, according to their 'lastReviewed' and 'reviewEvery' values",
+		Args:  cobra.NoArgs,
+		Run:   review,
+	}
+	addRenderFlags(reviewCmd) // the pages are collected like for a build
+	reviewCmd.Flags().StringVar(&reviewFormat, "reviewFormat", "text", "Sets the format the overdue pages are printed in, either 'text' or 'json'.")
+	reviewCmd.Flags().BoolVar(&reviewFail, "fail", false, "Exits with an error if pages are overdue for review, f.e. for CI.")
+
+	diffCmd := &cobra.Command{
+		Use:   "diff <old-manifest>",
+		Short: "Builds the site and reports the pages added, removed and changed since the build of <old-manifest>, f.e. for pull requests",
+		Args:  cobra.ExactArgs(1),
+		Run:   diff,
+	}
+	addRenderFlags(diffCmd) // the site is built like by 'build'
+	diffCmd.Flags().StringVar(&diffFormat, "diffFormat", "text", "Sets the format the changes are printed in, either 'text' (markdown, f.e. for comments on pull requests) or 'json'.")
+
+	funcsCmd := &cobra.Command{
+		Use:   "funcs",
+		Short: "Lists the functions available in templates with their signatures and descriptions",
+		Args:  cobra.NoArgs,
+		Run:   funcs,
+	}
+	funcsCmd.Flags().StringToStringVar(&options.ExecFunctions, "execFunctions", options.ExecFunctions, "Adds template functions implemented by external commands, like for 'build'.")
+	funcsCmd.Flags().StringVar(&funcsFormat, "funcsFormat", "text", "Sets the format the functions are printed in, either 'text' or 'json' (f.e. for the completion of editors).")
+
+	cleanCmd := &cobra.Command{
+		Use:   "clean",
+		Short: "Deletes the contents of the outputDir",
+		Args:  cobra.NoArgs,
+		Run:   clean,
+	}
+	cleanCmd.Flags().BoolVar(&cleanCache, "cache", false, "Additionally deletes the cache folder '.temingo-cache'.")
+
+	rootCmd.AddCommand(buildCmd, watchCmd, serveCmd, initCmd, importCmd, bundleCmd, lintCmd, reviewCmd, diffCmd, funcsCmd, cleanCmd)
+
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
 }